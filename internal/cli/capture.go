@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/snapshot"
+)
+
+func runCapture(args []string) int {
+	fs := flag.NewFlagSet("capture", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var out string
+	var withDocker bool
+	fs.StringVar(&out, "out", "", "bundle file (default portik-<host>-<time>.json.gz; .gz compresses)")
+	fs.BoolVar(&withDocker, "docker", false, "also record containers and their published ports (shells out to docker)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "capture: unexpected arguments")
+		return 2
+	}
+
+	b, err := snapshot.Capture(snapshot.Options{Docker: withDocker, Portik: version})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "capture:", err)
+		return 1
+	}
+	if out == "" {
+		host := b.Env.Host.Hostname
+		if host == "" {
+			host = "host"
+		}
+		out = fmt.Sprintf("portik-%s-%s.json.gz", host, b.Env.Captured.Format("20060102-150405"))
+	}
+	if err := snapshot.Write(out, b); err != nil {
+		fmt.Fprintln(os.Stderr, "capture:", err)
+		return 1
+	}
+
+	listeners := 0
+	for _, ls := range b.Sockets.Listeners {
+		listeners += len(ls)
+	}
+	conns := 0
+	for _, cs := range b.Sockets.Connections {
+		conns += len(cs)
+	}
+	fmt.Printf("Captured %d listeners, %d connections, %d unix sockets and %d processes at %s\n",
+		listeners, conns, len(b.Sockets.Unix), len(b.Processes), b.Env.Captured.Format(time.RFC3339))
+	fmt.Printf("Wrote %s — analyse it anywhere with: portik --from %s who <port>\n", out, out)
+	return 0
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pratik-anurag/portik/internal/docker"
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/policy"
+	"github.com/pratik-anurag/portik/internal/proc"
+	"github.com/pratik-anurag/portik/internal/render"
+	"github.com/pratik-anurag/portik/internal/sockets"
+)
+
+type checkOutput struct {
+	Manifest string            `json:"manifest"`
+	Host     string            `json:"host"`
+	OK       bool              `json:"ok"`
+	Checks   []model.PortCheck `json:"checks"`
+}
+
+// runManifestCheck compares the live listeners with a manifest of expected
+// ports. It exits 0 when they match, 1 on drift, 2 on usage or manifest
+// errors and 3 when the listeners could not be inspected.
+func runManifestCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	var manifestFile, host string
+	var jsonOut, strict bool
+	fs.StringVar(&manifestFile, "manifest", "", "manifest of expected ports (YAML or JSON)")
+	fs.StringVar(&host, "host", "", "host name to select services by (default: this host's name)")
+	fs.BoolVar(&strict, "strict", false, "also exit 1 on warnings (e.g. unexpected listeners with unexpected: warn)")
+	fs.BoolVar(&jsonOut, "json", false, "output JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if manifestFile == "" || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: portik check --manifest FILE [--host NAME] [--strict] [--json]")
+		return 2
+	}
+	m, err := policy.LoadManifest(manifestFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check:", err)
+		return 2
+	}
+	if host == "" {
+		host = platform.HostSummary().Hostname
+	}
+
+	listeners := map[string][]model.Listener{}
+	for _, p := range m.Protos(host) {
+		ls, err := sockets.ListListeners(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "check:", err)
+			return 3
+		}
+		for i := range ls {
+			proc.Enrich(&ls[i])
+		}
+		listeners[p] = ls
+	}
+	var compose func(proto string, port int) string
+	if m.UsesDocker(host) {
+		compose = func(proto string, port int) string {
+			return docker.MapPort(port, proto).ComposeService
+		}
+	}
+	checks := m.Check(host, listeners, compose)
+
+	ok := true
+	for _, c := range checks {
+		if c.Severity == "error" || (strict && c.Severity == "warn") {
+			ok = false
+		}
+	}
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(checkOutput{Manifest: manifestFile, Host: host, OK: ok, Checks: nonNil(checks)})
+	} else {
+		fmt.Printf("Manifest %s, host %s\n\n", manifestFile, host)
+		fmt.Print(render.CheckTable(checks))
+	}
+	if !ok {
+		return 1
+	}
+	return 0
+}
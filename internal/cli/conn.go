@@ -18,6 +18,38 @@ type connAggRow struct {
 	Total    int            `json:"total"`
 	ByState  map[string]int `json:"by_state,omitempty"`
 	Samples  []string       `json:"samples,omitempty"` // remote:port examples (optional)
+	Health   *connHealth    `json:"health,omitempty"`
+}
+
+// connHealth aggregates tcp_info across a client's connections.
+type connHealth struct {
+	Measured      int     `json:"measured"` // connections with tcp_info
+	AvgRTTMs      float64 `json:"avg_rtt_ms"`
+	MaxRTTMs      float64 `json:"max_rtt_ms"`
+	Retrans       int     `json:"retrans"` // total retransmitted segments
+	Unacked       int     `json:"unacked"`
+	BytesSent     uint64  `json:"bytes_sent"`
+	BytesReceived uint64  `json:"bytes_received"`
+	IdleMs        int64   `json:"idle_ms"` // since the most recently active connection moved data
+
+	rttSum float64
+}
+
+func (h *connHealth) add(t *model.TCPInfo) {
+	if t == nil {
+		return
+	}
+	if h.Measured == 0 || t.LastActivityMs < h.IdleMs {
+		h.IdleMs = t.LastActivityMs
+	}
+	h.Measured++
+	h.rttSum += t.RTTMs
+	h.AvgRTTMs = h.rttSum / float64(h.Measured)
+	h.MaxRTTMs = max(h.MaxRTTMs, t.RTTMs)
+	h.Retrans += t.TotalRetrans
+	h.Unacked += t.Unacked
+	h.BytesSent += t.BytesSent
+	h.BytesReceived += t.BytesReceived
 }
 
 func runConn(args []string) int {
@@ -137,6 +169,12 @@ func aggregateConnections(rep model.Report, stateFilter map[string]bool) []connA
 		}
 		row.Total++
 		row.ByState[state]++
+		if t := connTCP(c); t != nil {
+			if row.Health == nil {
+				row.Health = &connHealth{}
+			}
+			row.Health.add(t)
+		}
 
 		// keep a few example endpoints (remote ip:port)
 		if len(row.Samples) < 3 {
@@ -157,12 +195,19 @@ func aggregateConnections(rep model.Report, stateFilter map[string]bool) []connA
 func toRenderConnRows(in []connAggRow) []render.ConnAggRow {
 	out := make([]render.ConnAggRow, 0, len(in))
 	for _, r := range in {
-		out = append(out, render.ConnAggRow{
+		row := render.ConnAggRow{
 			RemoteIP: r.RemoteIP,
 			Total:    r.Total,
 			ByState:  r.ByState,
 			Samples:  r.Samples,
-		})
+		}
+		if r.Health != nil {
+			row.Measured = true
+			row.AvgRTTMs = r.Health.AvgRTTMs
+			row.Retrans = r.Health.Retrans
+			row.Unacked = r.Health.Unacked
+		}
+		out = append(out, row)
 	}
 	return out
 }
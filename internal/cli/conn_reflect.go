@@ -57,6 +57,19 @@ func connRemotePort(c any) int {
 	return 0
 }
 
+// connTCP returns kernel tcp_info for a connection when the backend collected it.
+func connTCP(c any) *model.TCPInfo {
+	switch v := c.(type) {
+	case model.Conn:
+		return v.TCP
+	case *model.Conn:
+		if v != nil {
+			return v.TCP
+		}
+	}
+	return nil
+}
+
 func getStringField(obj any, names ...string) string {
 	v := reflect.ValueOf(obj)
 	if v.Kind() == reflect.Pointer {
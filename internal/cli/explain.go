@@ -5,8 +5,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 
-	"github.com/pratik-anurag/portik/internal/history"
 	"github.com/pratik-anurag/portik/internal/inspect"
 	"github.com/pratik-anurag/portik/internal/render"
 )
@@ -15,25 +15,65 @@ func runExplain(args []string) int {
 	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 	c := parseCommon(fs)
+	bind := fs.String("bind", "", "try a real bind on this address (e.g. 127.0.0.1, 0.0.0.0, ::)")
+	reuseAddr := fs.Bool("reuseaddr", false, "with --bind: set SO_REUSEADDR")
+	reusePort := fs.Bool("reuseport", false, "with --bind: set SO_REUSEPORT")
+	v6Only := fs.Bool("v6only", false, "with --bind: set IPV6_V6ONLY on an IPv6 address")
+	exe := fs.String("exe", "", "program that will bind the port; its file capabilities count for ports < 1024 (linux)")
+	probe := fs.Bool("probe", false, "connect to the listener and identify the protocol it serves")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "explain: missing <port>")
+		fmt.Fprintln(os.Stderr, "explain: missing <port> (or <path> with --proto unix)")
 		return 2
 	}
-	port, err := parsePort(fs.Arg(0))
+	t, err := parseTarget(fs.Arg(0), c.Proto)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "explain:", err)
 		return 2
 	}
 
-	rep, err := inspect.InspectPort(port, c.Proto, inspect.Options{EnableDocker: c.Docker, IncludeConnections: true})
+	opt := inspect.Options{EnableDocker: c.Docker, IncludeConnections: true, Probe: *probe}
+	if *probe && (t.Path != "" || fromBundle != "") {
+		fmt.Fprintln(os.Stderr, "explain: --probe connects to a live tcp listener; it cannot be used with unix sockets or --from")
+		return 2
+	}
+	if *exe != "" {
+		p, err := exec.LookPath(*exe)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "explain:", err)
+			return 2
+		}
+		opt.Exe = p
+	}
+	if *bind == "" && (*reuseAddr || *reusePort || *v6Only) {
+		fmt.Fprintln(os.Stderr, "explain: --reuseaddr, --reuseport and --v6only need --bind")
+		return 2
+	}
+	if *bind != "" {
+		switch {
+		case t.Path != "":
+			fmt.Fprintln(os.Stderr, "explain: --bind works on tcp/udp ports only")
+			return 2
+		case fromBundle != "":
+			fmt.Fprintln(os.Stderr, "explain: --bind tries a bind on this host; it cannot be used with --from")
+			return 2
+		}
+		addr, err := inspect.ParseBindAddr(*bind)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "explain:", err)
+			return 2
+		}
+		opt.Bind = &inspect.BindRequest{Addr: addr, ReuseAddr: *reuseAddr, ReusePort: *reusePort, V6Only: *v6Only}
+	}
+
+	rep, err := t.inspect(opt)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		return 1
 	}
-	_ = history.Record(rep)
+	t.record(rep)
 
 	if c.JSON {
 		enc := json.NewEncoder(os.Stdout)
@@ -42,8 +82,8 @@ func runExplain(args []string) int {
 		return 0
 	}
 
-	opt := renderOptions(c)
-	opt.RecentOwners = recentOwners(port, c.Proto, 3)
-	fmt.Print(render.Explain(rep, opt))
+	ropt := renderOptions(c)
+	ropt.RecentOwners = t.recentOwners(3)
+	fmt.Print(render.Explain(rep, ropt))
 	return 0
 }
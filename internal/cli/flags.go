@@ -4,8 +4,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"strings"
 	"time"
+
+	"github.com/pratik-anurag/portik/internal/snapshot"
+	"github.com/pratik-anurag/portik/internal/sockets"
 )
 
 type commonFlags struct {
@@ -21,7 +25,7 @@ type commonFlags struct {
 
 func parseCommon(fs *flag.FlagSet) *commonFlags {
 	c := &commonFlags{}
-	fs.StringVar(&c.Proto, "proto", "tcp", "protocol: tcp|udp")
+	fs.StringVar(&c.Proto, "proto", "tcp", "protocol: tcp|udp (unix for who/explain/kill/wait)")
 	fs.BoolVar(&c.Docker, "docker", false, "enable docker mapping")
 	fs.BoolVar(&c.JSON, "json", false, "output JSON (if available)")
 	fs.BoolVar(&c.Yes, "yes", false, "skip confirmation prompts")
@@ -32,6 +36,75 @@ func parseCommon(fs *flag.FlagSet) *commonFlags {
 	return c
 }
 
+// fromBundle is the snapshot bundle given with --from; commands then run
+// against it instead of the live system.
+var fromBundle string
+
+// replayCommands are the commands that can run against a bundle. The rest
+// act on the live system (kill, wait, ...) or need its history.
+var replayCommands = map[string]bool{
+	"who": true, "explain": true, "lint": true, "graph": true, "trace": true, "scan": true, "check": true,
+}
+
+// ruleCommands are the commands whose output comes from the diagnostic
+// registry, so only they read a rules file.
+var ruleCommands = map[string]bool{"who": true, "explain": true, "rules": true}
+
+// applyGlobal consumes global flags placed before the command name and
+// returns the remaining args.
+func applyGlobal(args []string) ([]string, error) {
+	backend := os.Getenv("PORTIK_BACKEND")
+	backendFlag := false
+	rules := os.Getenv("PORTIK_RULES")
+	for len(args) > 0 {
+		name, val, hasVal := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if !strings.HasPrefix(args[0], "--") || (name != "backend" && name != "from" && name != "rules") {
+			break
+		}
+		if !hasVal {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("flag needs an argument: --%s", name)
+			}
+			val = args[1]
+			args = args[1:]
+		}
+		args = args[1:]
+		switch name {
+		case "from":
+			fromBundle = val
+		case "rules":
+			rules = val
+		default:
+			backend, backendFlag = val, true
+		}
+	}
+	if len(args) > 0 && ruleCommands[args[0]] {
+		if err := loadRules(rules); err != nil {
+			return nil, err
+		}
+	}
+	if fromBundle != "" {
+		if backendFlag {
+			return nil, errors.New("--backend cannot be combined with --from")
+		}
+		if len(args) > 0 && !replayCommands[args[0]] && !strings.HasPrefix(args[0], "-") && args[0] != "help" {
+			return nil, fmt.Errorf("%s cannot run against a bundle (--from works with who, explain, lint, check, graph, trace, scan)", args[0])
+		}
+		b, err := snapshot.Load(fromBundle)
+		if err != nil {
+			return nil, fmt.Errorf("--from %s: %w", fromBundle, err)
+		}
+		b.Install()
+		return args, nil
+	}
+	if backend != "" {
+		if err := sockets.SetBackend(backend); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
 func parsePort(s string) (int, error) {
 	var p int
 	if _, err := fmt.Sscanf(s, "%d", &p); err != nil {
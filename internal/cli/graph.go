@@ -2,11 +2,13 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 
 	"github.com/pratik-anurag/portik/internal/graph"
+	"github.com/pratik-anurag/portik/internal/netns"
 	"github.com/pratik-anurag/portik/internal/render"
 )
 
@@ -19,12 +21,15 @@ func runGraph(args []string) int {
 	var topN int
 	var dotOut bool
 	var jsonOut bool
+	var unix bool
 
 	fs.StringVar(&portsStr, "ports", "", "focus only on these ports (comma-separated)")
 	fs.BoolVar(&localOnly, "local-only", true, "only include local dependencies")
 	fs.IntVar(&topN, "top", 50, "limit dependency edges (default 50)")
 	fs.BoolVar(&dotOut, "dot", false, "output Graphviz DOT")
 	fs.BoolVar(&jsonOut, "json", false, "output JSON")
+	fs.BoolVar(&unix, "unix", true, "include Unix stream socket dependencies (linux; skipped with --ports)")
+	nf := addNetnsFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return 2
@@ -44,14 +49,45 @@ func runGraph(args []string) int {
 		ports = p
 	}
 
-	g, deps, warns, err := graph.Build("tcp", graph.Options{
-		Ports:     ports,
-		LocalOnly: localOnly,
+	nss, err := nf.namespaces()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "graph:", err)
+		return 2
+	}
+	var graphs []graph.Graph
+	var depSets [][]graph.Dependency
+	var warns []string
+	err = nf.each("graph", nss, func(ns netns.Namespace) error {
+		g, deps, w, err := graph.Build("tcp", graph.Options{
+			Ports:     ports,
+			LocalOnly: localOnly,
+			Unix:      unix,
+			Netns:     nf.label(ns),
+		})
+		if err != nil {
+			return err
+		}
+		graphs = append(graphs, g)
+		depSets = append(depSets, deps)
+		for _, msg := range w {
+			if l := nf.label(ns); l != "" {
+				msg = l + ": " + msg
+			}
+			warns = append(warns, msg)
+		}
+		return nil
 	})
+	if err == nil && len(graphs) == 0 {
+		err = errors.New("no network namespace could be inspected")
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "graph:", err)
 		return 1
 	}
+	g, deps := graphs[0], depSets[0]
+	if len(graphs) > 1 {
+		g, deps = graph.Merge(graphs, depSets)
+	}
 
 	if jsonOut {
 		topDeps := graph.TopDependencies(deps, topN)
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/probe"
+)
+
+// healthFlags select the readiness check shared by wait and scan.
+type healthFlags struct {
+	TCP     bool
+	TLS     bool
+	GRPC    bool
+	HTTP    string
+	Expect  string
+	Timeout time.Duration
+}
+
+func addHealthFlags(fs *flag.FlagSet) *healthFlags {
+	f := &healthFlags{}
+	fs.BoolVar(&f.TCP, "tcp-connect", false, "ready when a TCP connection succeeds")
+	fs.StringVar(&f.HTTP, "http", "", "ready when GET PATH answers an expected status (e.g. /healthz)")
+	fs.StringVar(&f.Expect, "expect", "", "HTTP status codes for --http: 200, 2xx, 200-399 or a list (default 200-399)")
+	fs.BoolVar(&f.TLS, "tls", false, "ready when a TLS handshake succeeds; with --http or --grpc-health, check over TLS")
+	fs.BoolVar(&f.GRPC, "grpc-health", false, "ready when grpc.health.v1 reports SERVING")
+	fs.DurationVar(&f.Timeout, "check-timeout", 2*time.Second, "timeout for each readiness check")
+	return f
+}
+
+// check returns the selected check, or nil if none was asked for.
+func (f *healthFlags) check() (*probe.Check, error) {
+	if f.Expect != "" && f.HTTP == "" {
+		return nil, errors.New("--expect needs --http")
+	}
+	if f.HTTP != "" && f.GRPC {
+		return nil, errors.New("use only one of --http and --grpc-health")
+	}
+	if f.TCP && (f.TLS || f.GRPC || f.HTTP != "") {
+		return nil, errors.New("--tcp-connect cannot be combined with --http, --tls or --grpc-health")
+	}
+	if f.Timeout <= 0 {
+		return nil, errors.New("invalid --check-timeout")
+	}
+	c := &probe.Check{TLS: f.TLS, Timeout: f.Timeout}
+	switch {
+	case f.HTTP != "":
+		c.Kind, c.Path = "http", f.HTTP
+		if f.Expect != "" {
+			e, err := probe.ParseExpect(f.Expect)
+			if err != nil {
+				return nil, errors.New("--expect: " + err.Error())
+			}
+			c.Expect = e
+		}
+	case f.GRPC:
+		c.Kind = "grpc"
+	case f.TLS:
+		c.Kind, c.TLS = "tls", false
+	case f.TCP:
+		c.Kind = "tcp"
+	default:
+		return nil, nil
+	}
+	return c, nil
+}
+
+// healthLabel is the short HEALTH cell: "ok HTTP 200", "fail NOT_SERVING".
+func healthLabel(h *model.Health) string {
+	if h == nil {
+		return ""
+	}
+	if h.OK {
+		return "ok " + h.Detail
+	}
+	return "fail " + h.Detail
+}
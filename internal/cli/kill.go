@@ -4,10 +4,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
-	"github.com/pratik-anurag/portik/internal/history"
+	"github.com/pratik-anurag/portik/internal/forwarder"
 	"github.com/pratik-anurag/portik/internal/inspect"
+	"github.com/pratik-anurag/portik/internal/model"
 	"github.com/pratik-anurag/portik/internal/render"
 	"github.com/pratik-anurag/portik/internal/sys"
 )
@@ -18,17 +20,18 @@ func runKill(args []string) int {
 	c := parseCommon(fs)
 
 	var timeoutStr string
-	var force bool
+	var force, killManaged bool
 	fs.StringVar(&timeoutStr, "timeout", "5s", "grace period before SIGKILL")
 	fs.BoolVar(&force, "force", false, "allow killing processes not owned by your user (danger)")
+	fs.BoolVar(&killManaged, "kill-managed", false, "kill a forwarder managed by dockerd, podman, VS Code or a shared ssh master anyway")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "kill: missing <port>")
+		fmt.Fprintln(os.Stderr, "kill: missing <port> (or <path> with --proto unix)")
 		return 2
 	}
-	port, err := parsePort(fs.Arg(0))
+	t, err := parseTarget(fs.Arg(0), c.Proto)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "kill:", err)
 		return 2
@@ -39,16 +42,16 @@ func runKill(args []string) int {
 		return 2
 	}
 
-	rep, err := inspect.InspectPort(port, c.Proto, inspect.Options{EnableDocker: c.Docker, IncludeConnections: false})
+	rep, err := t.inspect(inspect.Options{EnableDocker: c.Docker, IncludeConnections: false})
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		return 1
 	}
-	_ = history.Record(rep)
+	t.record(rep)
 
 	target, ok := rep.PrimaryListener()
 	if !ok || target.PID <= 0 {
-		fmt.Fprintln(os.Stderr, "No listening process found for this port.")
+		fmt.Fprintf(os.Stderr, "No listening process found for %s.\n", t)
 		return 1
 	}
 
@@ -60,8 +63,16 @@ func runKill(args []string) int {
 		}
 	}
 
+	if target.Tunnel != nil {
+		return closeTunnel(target, t.String(), c.Yes, killManaged, timeout)
+	}
+
 	if !c.Yes {
-		fmt.Printf("Kill pid %d (%s) listening on %d/%s? [y/N]: ", target.PID, target.ProcName, port, c.Proto)
+		what := target.ProcName
+		if n := len(target.Workers()); n > 0 {
+			what = fmt.Sprintf("%s, master of %d workers", target.ProcName, n)
+		}
+		fmt.Printf("Kill pid %d (%s) listening on %s? [y/N]: ", target.PID, what, t)
 		var resp string
 		_, _ = fmt.Fscanln(os.Stdin, &resp)
 		if resp != "y" && resp != "Y" {
@@ -74,3 +85,43 @@ func runKill(args []string) int {
 	fmt.Print(render.ActionResult(res))
 	return res.ExitCode
 }
+
+// closeTunnel closes a forwarder's tunnel the least disruptive way: one
+// ssh forward is cancelled through its ControlMaster, and processes managed
+// by something else (dockerd, podman, VS Code) are left alone unless
+// killManaged is set.
+func closeTunnel(l model.Listener, where string, yes, killManaged bool, timeout time.Duration) int {
+	how := forwarder.HowToClose(*l.Tunnel, l.Cmdline)
+	if how.Refuse != "" && !killManaged {
+		fmt.Fprintf(os.Stderr, "pid %d (%s) on %s is a %s.\n", l.PID, l.ProcName, where, l.Tunnel)
+		fmt.Fprintln(os.Stderr, how.Refuse)
+		fmt.Fprintln(os.Stderr, "Use --kill-managed to kill it anyway.")
+		return 1
+	}
+	if !yes {
+		fmt.Printf("pid %d (%s) on %s is a %s.\n", l.PID, l.ProcName, where, l.Tunnel)
+		prompt := "Close the tunnel"
+		switch {
+		case how.Refuse != "":
+			fmt.Println(how.Refuse)
+			prompt = "Kill it anyway"
+		case how.Argv != nil:
+			prompt += " (" + strings.Join(how.Argv, " ") + ")"
+		}
+		fmt.Printf("%s? [y/N]: ", prompt)
+		var resp string
+		_, _ = fmt.Fscanln(os.Stdin, &resp)
+		if resp != "y" && resp != "Y" {
+			fmt.Println("Aborted.")
+			return 0
+		}
+	}
+	var res sys.ActionResult
+	if how.Argv != nil && how.Refuse == "" {
+		res = sys.RunCommand("Tunnel closed", how.Argv)
+	} else {
+		res = sys.TerminateProcess(l.PID, timeout)
+	}
+	fmt.Print(render.ActionResult(res))
+	return res.ExitCode
+}
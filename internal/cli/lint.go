@@ -2,18 +2,71 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/policy"
 	"github.com/pratik-anurag/portik/internal/proc"
 	"github.com/pratik-anurag/portik/internal/render"
 	"github.com/pratik-anurag/portik/internal/sockets"
 )
 
+// lintRules describes the finding codes lint reports itself. Policy files
+// may override or allow them; report formats list them as rules.
+var lintRules = []model.LintRule{
+	{Code: "NO_PID", Severity: "info", Summary: "PID not visible",
+		Help: "portik could not see which process owns the socket, usually because it belongs to another user. Re-run with sudo for full details."},
+	{Code: "PRIV_PORT", Severity: "info", Summary: "Privileged port used by a non-root user",
+		Help: "Binding to ports below 1024 typically requires root or CAP_NET_BIND_SERVICE. If this is expected, ignore it; otherwise use a port >= 1024."},
+	{Code: "PUBLIC_SENSITIVE", Severity: "warn", Summary: "Sensitive service port is publicly bound",
+		Help: "Databases, caches and brokers bound to all interfaces may be reachable from your network. Bind to 127.0.0.1/::1 or restrict access with a firewall or security group."},
+	{Code: "PUBLIC_DEV", Severity: "info", Summary: "Dev-style port is publicly bound",
+		Help: "Development servers bound to all interfaces are reachable from the network. Bind to 127.0.0.1/::1 if you only need local access."},
+	{Code: "DYNAMIC_RANGE", Severity: "info", Summary: "Service listens in the dynamic/ephemeral port range",
+		Help: "Ports 49152-65535 are commonly used as ephemeral client ports (RFC 6335). Use a stable registered port (1024-49151) if clients depend on it."},
+	{Code: "IPV6_ONLY", Severity: "info", Summary: "Port is IPv6-only",
+		Help: "Clients that try IPv4 (127.0.0.1) fail to connect. Bind on 0.0.0.0/127.0.0.1 too, or enable dual-stack."},
+	{Code: "UNIX_WORLD_WRITABLE", Severity: "warn", Summary: "Root-owned Unix socket is world-writable",
+		Help: "Any local user can connect to the socket. Restrict it (e.g. chmod 660 with a dedicated group) unless unprivileged access is intended."},
+	{Code: "PUBLIC_DEBUG_PORT", Severity: "error", Summary: "Debugger or profiler reachable from the network",
+		Help: "Node inspector, JDWP, Delve and pprof ports let anyone who can reach them read memory or run code. Bind them to 127.0.0.1 and use an SSH tunnel."},
+	{Code: "EXE_DELETED", Severity: "warn", Summary: "Process runs a deleted or replaced binary",
+		Help: "The executable was removed or replaced after the process started. After a package upgrade, restart the service; otherwise investigate, as malware often deletes its binary."},
+	{Code: "EXE_WRITABLE_DIR", Severity: "error", Summary: "Listener runs a binary from a world-writable directory",
+		Help: "Binaries under /tmp, /var/tmp, /dev/shm or other world-writable directories can be planted or swapped by any local user. Install services in root-owned directories."},
+	{Code: "ROOT_EXE_IN_HOME", Severity: "warn", Summary: "Root process runs a binary from a home directory",
+		Help: "The home directory's owner can replace the binary and get root. Install it in a root-owned directory or run the service as that user."},
+	{Code: "INLINE_CODE", Severity: "warn", Summary: "Interpreter running inline code holds a port",
+		Help: "python -c, perl -e, bash -i and similar run code from the command line rather than a file; bind and reverse shells look like this. Check where the process came from."},
+	{Code: "TLS_EXPIRED", Severity: "error", Summary: "TLS certificate has expired",
+		Help: "Clients that verify certificates refuse the connection. Renew the certificate and restart the service."},
+	{Code: "TLS_EXPIRING", Severity: "warn", Summary: "TLS certificate expires soon",
+		Help: "Renew the certificate before it expires; lint --tls-days sets how far ahead to warn."},
+	{Code: "TLS_WEAK_KEY", Severity: "warn", Summary: "TLS certificate uses a weak key",
+		Help: "RSA keys below 2048 bits and EC keys below 256 bits are rejected by current clients. Reissue with an RSA 2048+ or ECDSA P-256 key."},
+	{Code: "TLS_WEAK_SIGNATURE", Severity: "warn", Summary: "TLS certificate is signed with SHA-1 or MD5",
+		Help: "These signatures are not trusted by current clients. Reissue the certificate with a SHA-256 signature."},
+	{Code: "TLS_SAN_MISMATCH", Severity: "warn", Summary: "TLS certificate does not cover the names clients use",
+		Help: "Clients connecting by the bound address or host name fail hostname verification. Reissue the certificate with those names as subjectAltName entries."},
+	{Code: "TLS_OLD_VERSION", Severity: "info", Summary: "Server accepts TLS 1.0 or 1.1",
+		Help: "These versions are deprecated (RFC 8996). Require TLS 1.2 or later in the server configuration."},
+}
+
+func lintCodes() []string {
+	codes := make([]string, len(lintRules))
+	for i, r := range lintRules {
+		codes[i] = r.Code
+	}
+	return codes
+}
+
 type listenerWithProto struct {
 	Proto string
 	L     model.Listener
@@ -26,23 +79,49 @@ func runLint(args []string) int {
 	var proto string
 	var jsonOut bool
 	var severity string
+	var tlsCheck bool
+	var tlsDays int
+	var policyFile string
+	var baselineFile, writeBaseline string
+	var failOn string
+	var format string
 
-	fs.StringVar(&proto, "proto", "tcp", "protocol: tcp|udp|all")
-	fs.BoolVar(&jsonOut, "json", false, "output JSON")
+	fs.StringVar(&proto, "proto", "tcp", "protocol: tcp|udp|unix|all")
+	fs.BoolVar(&jsonOut, "json", false, "output JSON (same as --format json)")
+	fs.StringVar(&format, "format", "table", "output format: table|json|sarif|junit|markdown|checkstyle")
 	fs.StringVar(&severity, "min-severity", "info", "minimum severity: info|warn|error")
+	fs.BoolVar(&tlsCheck, "tls", false, "handshake with each tcp listener and check its certificate")
+	fs.IntVar(&tlsDays, "tls-days", 30, "with --tls: warn about certificates expiring within this many days")
+	fs.StringVar(&policyFile, "policy", "", "policy file (YAML or JSON): severity overrides, allowlists and custom rules")
+	fs.StringVar(&baselineFile, "baseline", "", "only report findings that are not in this baseline file")
+	fs.StringVar(&writeBaseline, "write-baseline", "", "accept the current findings: write them to this baseline file")
+	fs.StringVar(&failOn, "fail-on", "error", "exit 1 when a reported finding is at least: info|warn|error|none")
 
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 
 	var protos []string
+	var unix bool
 	switch strings.ToLower(strings.TrimSpace(proto)) {
 	case "tcp", "udp":
 		protos = []string{strings.ToLower(proto)}
+	case "unix":
+		unix = true
 	case "all":
 		protos = []string{"tcp", "udp"}
+		unix = true
 	default:
-		fmt.Fprintln(os.Stderr, "lint: invalid --proto (tcp|udp|all)")
+		fmt.Fprintln(os.Stderr, "lint: invalid --proto (tcp|udp|unix|all)")
+		return 2
+	}
+
+	if tlsCheck && fromBundle != "" {
+		fmt.Fprintln(os.Stderr, "lint: --tls connects to live listeners; it cannot be used with --from")
+		return 2
+	}
+	if tlsDays < 0 {
+		fmt.Fprintln(os.Stderr, "lint: invalid --tls-days")
 		return 2
 	}
 
@@ -52,6 +131,52 @@ func runLint(args []string) int {
 		return 2
 	}
 
+	failRank, ok := sevRank(failOn)
+	if failOn == "none" {
+		failRank, ok = 3, true
+	}
+	if !ok {
+		fmt.Fprintln(os.Stderr, "lint: invalid --fail-on (info|warn|error|none)")
+		return 2
+	}
+	switch format {
+	case "table", "json", "sarif", "junit", "markdown", "checkstyle":
+	default:
+		fmt.Fprintln(os.Stderr, "lint: invalid --format (table|json|sarif|junit|markdown|checkstyle)")
+		return 2
+	}
+	if jsonOut {
+		if format != "table" && format != "json" {
+			fmt.Fprintln(os.Stderr, "lint: --json conflicts with --format", format)
+			return 2
+		}
+		format = "json"
+	}
+	// report formats keep exit 0 unless gating was asked for
+	gate := format == "table" || baselineFile != ""
+	fs.Visit(func(f *flag.Flag) { gate = gate || f.Name == "fail-on" })
+
+	var pol *policy.Policy
+	if policyFile != "" {
+		var err error
+		if pol, err = policy.Load(policyFile, lintCodes()); err != nil {
+			fmt.Fprintln(os.Stderr, "lint: --policy:", err)
+			return 2
+		}
+	}
+	if baselineFile != "" && writeBaseline != "" {
+		fmt.Fprintln(os.Stderr, "lint: use only one of --baseline and --write-baseline")
+		return 2
+	}
+	var base *policy.Baseline
+	if baselineFile != "" {
+		var err error
+		if base, err = policy.LoadBaseline(baselineFile); err != nil {
+			fmt.Fprintln(os.Stderr, "lint: --baseline:", err)
+			return 2
+		}
+	}
+
 	// Gather listeners for selected protos
 	var listeners []listenerWithProto
 	for _, p := range protos {
@@ -66,7 +191,29 @@ func runLint(args []string) int {
 		}
 	}
 
-	findings := lintListeners(listeners)
+	findings := lintListeners(listeners, pol)
+	findings = append(findings, lintProcesses(listeners)...)
+	if tlsCheck {
+		findings = append(findings, lintTLSListeners(listeners, tlsDays)...)
+	}
+	if unix {
+		socks, err := sockets.ListUnix()
+		switch {
+		case errors.Is(err, errors.ErrUnsupported) && len(protos) > 0:
+			// --proto all on a platform without unix socket support
+		case err != nil:
+			fmt.Fprintln(os.Stderr, "lint:", err)
+			return 1
+		}
+		findings = append(findings, lintUnix(socks)...)
+	}
+	now := platform.Now()
+	findings, suppressed := pol.Apply(findings, now)
+	// what still occurs, for telling which baseline entries were fixed
+	present := append([]model.LintFinding(nil), findings...)
+	for _, s := range suppressed {
+		present = append(present, s.LintFinding)
+	}
 	// apply min severity filter
 	filtered := findings[:0]
 	for _, f := range findings {
@@ -93,33 +240,109 @@ func runLint(args []string) int {
 		return findings[i].Code < findings[j].Code
 	})
 
-	if jsonOut {
+	if writeBaseline != "" {
+		b := policy.NewBaseline(findings, now)
+		if err := b.Write(writeBaseline); err != nil {
+			fmt.Fprintln(os.Stderr, "lint:", err)
+			return 1
+		}
+		fmt.Printf("Wrote %d findings to %s\n", len(b.Findings), writeBaseline)
+		return 0
+	}
+	var known []model.LintFinding
+	var stale []policy.BaselineEntry
+	if base != nil {
+		findings, known = base.Split(findings)
+		stale = base.Stale(present)
+	}
+	expired := pol.Expired(now)
+
+	switch format {
+	case "json":
+		out := map[string]any{"findings": findings}
+		if base != nil {
+			out["baselined"] = nonNil(known)
+			out["fixed"] = nonNil(stale)
+		}
+		if pol != nil {
+			out["suppressed"] = nonNil(suppressed)
+			out["expired_suppressions"] = nonNil(expired)
+		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		_ = enc.Encode(map[string]any{
-			"findings": findings,
-		})
-		return 0
+		_ = enc.Encode(out)
+	case "sarif":
+		fmt.Print(render.LintSARIF(findings, pol.LintRules(lintRules), platform.HostSummary().Hostname, version))
+	case "junit":
+		fmt.Print(render.LintJUnit(findings, pol.LintRules(lintRules), failOn))
+	case "markdown":
+		fmt.Print(render.LintMarkdown(findings))
+	case "checkstyle":
+		fmt.Print(render.LintCheckstyle(findings))
+	default:
+		fmt.Print(render.LintTable(findings))
+		printLintFooter(baselineFile, known, stale, suppressed, expired)
 	}
 
-	fmt.Print(render.LintTable(findings))
-	if len(findings) == 0 {
+	if !gate {
 		return 0
 	}
-	// non-zero if warn/error exists
-	max := 0
+	// non-zero if a reported finding reaches --fail-on
 	for _, f := range findings {
-		r, _ := sevRank(f.Severity)
-		if r > max {
-			max = r
+		if r, _ := sevRank(f.Severity); r >= failRank {
+			return 1
 		}
 	}
-	if max >= 2 {
-		return 1
-	}
 	return 0
 }
 
+// printLintFooter accounts for findings the table does not show: those in
+// the baseline and those suppressed by the policy.
+func printLintFooter(baselineFile string, known []model.LintFinding, stale []policy.BaselineEntry, suppressed []policy.Suppressed, expired []policy.Allow) {
+	if baselineFile != "" {
+		fmt.Printf("\nBaseline %s: %d known not shown", baselineFile, len(known))
+		if len(stale) > 0 {
+			fmt.Printf(", %d fixed (rewrite it with --write-baseline to drop them)", len(stale))
+		}
+		fmt.Println()
+	}
+	if len(suppressed) > 0 {
+		fmt.Println("\nSuppressed by policy:")
+		for _, s := range suppressed {
+			where := fmt.Sprintf("%d/%s", s.Port, s.Proto)
+			if s.Path != "" {
+				where = s.Path
+			}
+			line := fmt.Sprintf("  %-5s %-18s %s %s", strings.ToUpper(s.Severity), s.Code, where, dash(s.ProcName))
+			if s.Reason != "" {
+				line += ": " + s.Reason
+			}
+			if s.Expires != "" {
+				line += " (until " + s.Expires + ")"
+			}
+			fmt.Println(line)
+		}
+	}
+	if len(expired) > 0 {
+		fmt.Println("\nExpired suppressions (no longer applied):")
+		for _, a := range expired {
+			codes := "all codes"
+			if len(a.Codes) > 0 {
+				codes = strings.Join(a.Codes, ",")
+			}
+			fmt.Printf("  %s, expired %s: %s\n", codes, a.Expires, a.Reason)
+		}
+	}
+}
+
+// nonNil keeps empty lists as [] rather than null in JSON.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
 func sevRank(s string) (int, bool) {
 	s = strings.ToLower(strings.TrimSpace(s))
 	switch s {
@@ -134,7 +357,9 @@ func sevRank(s string) (int, bool) {
 	}
 }
 
-func lintListeners(ls []listenerWithProto) []model.LintFinding {
+// lintListeners runs the built-in checks and the policy's custom rules; pol
+// may be nil.
+func lintListeners(ls []listenerWithProto, pol *policy.Policy) []model.LintFinding {
 	// Build indices to support cross-family checks per (proto,port)
 	byKey := map[string][]model.Listener{}
 	for _, x := range ls {
@@ -186,7 +411,7 @@ func lintListeners(ls []listenerWithProto) []model.LintFinding {
 
 		// 3) Public exposure for commonly sensitive services
 		if public {
-			if isSensitivePort(port) {
+			if pol.Sensitive(port) {
 				out = append(out, model.LintFinding{
 					Severity: "warn",
 					Code:     "PUBLIC_SENSITIVE",
@@ -200,7 +425,7 @@ func lintListeners(ls []listenerWithProto) []model.LintFinding {
 					ProcName: l.ProcName,
 					User:     l.User,
 				})
-			} else if isCommonDevPort(port) {
+			} else if pol.Dev(port) {
 				out = append(out, model.LintFinding{
 					Severity: "info",
 					Code:     "PUBLIC_DEV",
@@ -255,11 +480,82 @@ func lintListeners(ls []listenerWithProto) []model.LintFinding {
 				})
 			}
 		}
+
+		out = append(out, pol.Check(p, l, bind)...)
 	}
 
 	return dedupeLint(out)
 }
 
+// publicUnixSockets are root-owned sockets that are world-writable by design
+// because unprivileged clients are expected to use them.
+var publicUnixSockets = map[string]bool{
+	"/dev/log":                                   true,
+	"/run/dbus/system_bus_socket":                true,
+	"/var/run/dbus/system_bus_socket":            true,
+	"/run/systemd/journal/socket":                true,
+	"/run/systemd/journal/stdout":                true,
+	"/run/systemd/journal/dev-log":               true,
+	"/run/systemd/notify":                        true,
+	"/run/systemd/io.system.ManagedOOM":          true,
+	"/run/systemd/userdb/io.systemd.DynamicUser": true,
+}
+
+func lintUnix(socks []model.UnixSocket) []model.LintFinding {
+	var out []model.LintFinding
+	seen := map[string]bool{}
+	for _, s := range socks {
+		bound := s.State == "LISTEN" || (s.Type == "dgram" && s.State == "UNCONN")
+		if !bound || s.Path == "" || strings.HasPrefix(s.Path, "@") || seen[s.Path] {
+			continue
+		}
+		seen[s.Path] = true
+		if publicUnixSockets[s.Path] {
+			continue
+		}
+		fi, err := platform.Stat(s.Path)
+		if err != nil {
+			continue
+		}
+		uid, _, ok := platform.FileOwner(fi)
+		if !ok || uid != 0 || fi.Mode().Perm()&0o002 == 0 || !dirsSearchable(filepath.Dir(s.Path)) {
+			continue
+		}
+		l := model.Listener{Path: s.Path, PID: s.PID, ProcName: s.ProcName, Holders: s.Holders}
+		proc.Enrich(&l)
+		out = append(out, model.LintFinding{
+			Severity: "warn",
+			Code:     "UNIX_WORLD_WRITABLE",
+			Summary:  "Root-owned Unix socket is world-writable",
+			Details:  fmt.Sprintf("%s has mode %s; any local user can connect to it.", s.Path, fi.Mode().Perm()),
+			Action:   "Restrict it (e.g. chmod 660 with a dedicated group) unless unprivileged access is intended.",
+			Proto:    "unix",
+			Path:     s.Path,
+			PID:      l.PID,
+			ProcName: l.ProcName,
+			User:     l.User,
+		})
+	}
+	return out
+}
+
+// dirsSearchable reports whether every directory from dir up to / grants
+// search (x) permission to other users; otherwise the socket is not
+// reachable by them regardless of its own mode.
+func dirsSearchable(dir string) bool {
+	for {
+		fi, err := platform.Stat(dir)
+		if err != nil || fi.Mode().Perm()&0o001 == 0 {
+			return false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return true
+		}
+		dir = parent
+	}
+}
+
 func normalizeBind(ip string) string {
 	ip = strings.TrimSpace(ip)
 	if ip == "" {
@@ -305,38 +601,6 @@ func looksLikeService(procName string) bool {
 	return true
 }
 
-func isSensitivePort(port int) bool {
-	// A pragmatic list of ports that are commonly sensitive when exposed.
-	sensitive := map[int]bool{
-		5432:  true, // postgres
-		3306:  true, // mysql
-		6379:  true, // redis
-		9200:  true, // elasticsearch
-		27017: true, // mongodb
-		11211: true, // memcached
-		15672: true, // rabbitmq mgmt
-		5672:  true, // rabbitmq
-		9092:  true, // kafka
-		2181:  true, // zookeeper
-	}
-	return sensitive[port]
-}
-
-func isCommonDevPort(port int) bool {
-	dev := map[int]bool{
-		3000: true,
-		3001: true,
-		5173: true,
-		8000: true,
-		8080: true,
-		8081: true,
-		5000: true,
-		4000: true,
-		9229: true, // node inspector
-	}
-	return dev[port]
-}
-
 func isIPv6Only(ls []model.Listener) bool {
 	if len(ls) == 0 {
 		return false
@@ -359,7 +623,7 @@ func dedupeLint(in []model.LintFinding) []model.LintFinding {
 	seen := map[string]bool{}
 	out := make([]model.LintFinding, 0, len(in))
 	for _, f := range in {
-		k := fmt.Sprintf("%s|%s|%d|%s|%s", f.Code, f.Proto, f.Port, f.LocalIP, f.ProcName)
+		k := fmt.Sprintf("%s|%s|%d|%s|%s|%s", f.Code, f.Proto, f.Port, f.LocalIP, f.Path, f.ProcName)
 		if seen[k] {
 			continue
 		}
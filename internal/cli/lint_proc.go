@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/proc"
+)
+
+// tempDirs hold files any local user can create.
+var tempDirs = []string{"/tmp/", "/var/tmp/", "/dev/shm/"}
+
+// lintProcesses looks at the process behind each listener: what binary it
+// runs, from where, as whom, and whether it exposes a debugger. Process
+// checks are reported once per process and port.
+func lintProcesses(ls []listenerWithProto) []model.LintFinding {
+	seen, debugSeen := map[string]bool{}, map[string]bool{}
+	var out []model.LintFinding
+	for _, x := range ls {
+		l := x.L
+		if l.PID <= 0 {
+			continue
+		}
+		info, ok := proc.Lookup(l.PID)
+		if !ok {
+			continue
+		}
+		bind := normalizeBind(l.LocalIP)
+		finding := func(sev, code, summary, details, action string) model.LintFinding {
+			return model.LintFinding{
+				Severity: sev,
+				Code:     code,
+				Summary:  summary,
+				Details:  details,
+				Action:   action,
+				Proto:    x.Proto,
+				Port:     l.LocalPort,
+				LocalIP:  bind,
+				PID:      l.PID,
+				ProcName: l.ProcName,
+				User:     l.User,
+			}
+		}
+
+		k := fmt.Sprintf("%d|%s|%d", l.PID, x.Proto, l.LocalPort)
+		exe, deleted := info.ExePath()
+
+		// debug ports are about the bind: the first public one is reported
+		if !isLoopbackBind(bind) && !debugSeen[k] {
+			isGo := func() bool { return platform.ProcessGoBinary(l.PID, exe) }
+			if svc := proc.DebugService(info.Name, info.Cmdline, l.LocalPort, isGo); svc != "" {
+				debugSeen[k] = true
+				out = append(out, finding("error", "PUBLIC_DEBUG_PORT",
+					svc+" is reachable from the network",
+					fmt.Sprintf("%s on %s port %d lets anyone who can reach it inspect memory and run code in the process.", svc, bind, l.LocalPort),
+					"Bind the debugger to 127.0.0.1 and reach it over an SSH tunnel, or turn it off outside development."))
+			}
+		}
+
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		if deleted {
+			out = append(out, finding("warn", "EXE_DELETED",
+				"Process runs a deleted or replaced binary",
+				exe+" was removed or replaced after the process started.",
+				"If the package was upgraded, restart the service to run the new binary; otherwise find out what removed it. Malware often deletes its binary after starting."))
+		}
+		if exe != "" && inWritableDir(exe) {
+			out = append(out, finding("error", "EXE_WRITABLE_DIR",
+				"Listener runs a binary from a world-writable directory",
+				exe+" is in a directory any local user can write to, so anyone could have placed or swapped it.",
+				"Install the binary in a root-owned directory such as /usr/local/bin, and check how it got there."))
+		}
+		if exe != "" && (info.UID == 0 || isRootUser(l.User)) && inHomeDir(exe) {
+			out = append(out, finding("warn", "ROOT_EXE_IN_HOME",
+				"Root process runs a binary from a home directory",
+				exe+" runs as root but can be replaced by the user who owns that home directory.",
+				"Install it in a root-owned directory, or run the service as that user."))
+		}
+		if how := proc.InlineCode(info.Cmdline); how != "" {
+			out = append(out, finding("warn", "INLINE_CODE",
+				"Interpreter running inline code holds a port",
+				fmt.Sprintf("%q runs code from its command line rather than a file; bind and reverse shells look like this.", how),
+				"Check where the process came from (portik explain, its parent process); run real services from files under version control."))
+		}
+	}
+	return out
+}
+
+// inWritableDir reports whether exe lives in a temp directory or one that
+// grants write permission to other users.
+func inWritableDir(exe string) bool {
+	for _, d := range tempDirs {
+		if strings.HasPrefix(exe, d) {
+			return true
+		}
+	}
+	fi, err := platform.Stat(filepath.Dir(exe))
+	return err == nil && fi.Mode().Perm()&0o002 != 0
+}
+
+// inHomeDir reports whether exe is under a user's home directory.
+func inHomeDir(exe string) bool {
+	return strings.HasPrefix(exe, "/home/") || strings.HasPrefix(exe, "/Users/")
+}
+
+func isLoopbackBind(bind string) bool {
+	ip := net.ParseIP(strings.Trim(bind, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/probe"
+)
+
+// lintTLSListeners handshakes with every TCP listener (lint --tls) and
+// reports certificate problems. Sockets of one process on one port are
+// checked once; listeners that do not speak TLS are skipped.
+func lintTLSListeners(ls []listenerWithProto, days int) []model.LintFinding {
+	seen := map[string]bool{}
+	var todo []model.Listener
+	for _, x := range ls {
+		l := x.L
+		if x.Proto != "tcp" || !strings.EqualFold(l.State, "LISTEN") {
+			continue
+		}
+		k := fmt.Sprintf("%d|%d", l.PID, l.LocalPort)
+		if l.PID <= 0 {
+			k += "|" + l.LocalIP
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		todo = append(todo, l)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var out []model.LintFinding
+	jobs := make(chan model.Listener)
+	now := platform.Now()
+	host := platform.HostSummary().Hostname
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for l := range jobs {
+				info, err := inspectListenerTLS(l, probe.TLSOptions{})
+				if err != nil {
+					continue
+				}
+				fs := tlsFindings(info, l, boundNames(l, host), days, now)
+				mu.Lock()
+				out = append(out, fs...)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, l := range todo {
+		jobs <- l
+	}
+	close(jobs)
+	wg.Wait()
+	return out
+}
+
+// inspectListenerTLS handshakes with the first of the listener's addresses
+// that accepts a connection.
+func inspectListenerTLS(l model.Listener, opt probe.TLSOptions) (model.TLSInfo, error) {
+	var info model.TLSInfo
+	var err error
+	for _, addr := range probe.Addrs(l) {
+		info, err = probe.InspectTLS(addr, l.LocalPort, opt)
+		if err == nil || errors.Is(err, probe.ErrNoTLS) {
+			break
+		}
+	}
+	return info, err
+}
+
+// boundNames are the names clients use to reach a listener, which its
+// certificate should cover: the bound IP, or for wildcard and loopback
+// binds the host name and localhost.
+func boundNames(l model.Listener, hostname string) []string {
+	ip := net.ParseIP(strings.Trim(l.LocalIP, "[]"))
+	switch {
+	case ip == nil || ip.IsUnspecified():
+		return nonEmptyNames(hostname, "localhost")
+	case ip.IsLoopback():
+		return []string{"localhost", ip.String()}
+	default:
+		return nonEmptyNames(hostname, ip.String())
+	}
+}
+
+func nonEmptyNames(names ...string) []string {
+	var out []string
+	for _, n := range names {
+		if n != "" {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// coversAny reports whether c is valid for one of names. A short host name
+// also matches a SAN for its fully qualified form.
+func coversAny(c model.Cert, names []string) bool {
+	for _, n := range names {
+		if c.Covers(n) {
+			return true
+		}
+		if !strings.Contains(n, ".") && net.ParseIP(n) == nil {
+			for _, san := range c.DNSNames {
+				if strings.HasPrefix(strings.ToLower(san), strings.ToLower(n)+".") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// tlsFindings checks the presented chain: expiry within days, weak keys and
+// signatures, a leaf that does not cover names, and old protocol versions.
+func tlsFindings(info model.TLSInfo, l model.Listener, names []string, days int, now time.Time) []model.LintFinding {
+	finding := func(sev, code, summary, details, action string) model.LintFinding {
+		return model.LintFinding{
+			Severity: sev, Code: code, Summary: summary, Details: details, Action: action,
+			Proto: "tcp", Port: l.LocalPort, LocalIP: normalizeBind(l.LocalIP),
+			PID: l.PID, ProcName: l.ProcName, User: l.User,
+		}
+	}
+	var out []model.LintFinding
+	for i, c := range info.Chain {
+		which := "Certificate"
+		if i > 0 {
+			which = "Chain certificate"
+		}
+		root := i > 0 && c.SelfSigned // trust anchors are checked by clients' stores, not as sent
+		renew := "Renew the certificate"
+		if c.SelfSigned {
+			renew = "Regenerate the self-signed certificate (e.g. mkcert localhost 127.0.0.1 ::1) and restart the service"
+		}
+		left := c.NotAfter.Sub(now)
+		switch {
+		case left <= 0:
+			out = append(out, finding("error", "TLS_EXPIRED",
+				fmt.Sprintf("%s expired %s ago (%s)", which, humanDays(-left), c.Subject),
+				fmt.Sprintf("Not after %s; clients that verify certificates refuse it.", c.NotAfter.Format(time.RFC3339)),
+				renew))
+		case left < time.Duration(days)*24*time.Hour:
+			out = append(out, finding("warn", "TLS_EXPIRING",
+				fmt.Sprintf("%s expires in %s (%s)", which, humanDays(left), c.Subject),
+				fmt.Sprintf("Not after %s.", c.NotAfter.Format(time.RFC3339)),
+				renew))
+		}
+		if root {
+			continue
+		}
+		if weak := weakKey(c); weak != "" {
+			out = append(out, finding("warn", "TLS_WEAK_KEY",
+				fmt.Sprintf("%s uses a weak %s key (%s)", which, weak, c.Subject),
+				"RSA keys below 2048 bits and EC keys below 256 bits are rejected by current clients or soon will be.",
+				"Reissue with an RSA 2048+ or ECDSA P-256 key"))
+		}
+		if sig := strings.ToUpper(c.Signature); strings.Contains(sig, "SHA1") || strings.Contains(sig, "MD5") || strings.Contains(sig, "MD2") {
+			out = append(out, finding("warn", "TLS_WEAK_SIGNATURE",
+				fmt.Sprintf("%s is signed with %s (%s)", which, c.Signature, c.Subject),
+				"SHA-1 and MD5 signatures are not trusted by current clients.",
+				"Reissue the certificate with a SHA-256 signature"))
+		}
+	}
+	if len(info.Chain) > 0 && len(names) > 0 && !coversAny(info.Chain[0], names) {
+		sans := append(append([]string(nil), info.Chain[0].DNSNames...), info.Chain[0].IPs...)
+		out = append(out, finding("warn", "TLS_SAN_MISMATCH",
+			fmt.Sprintf("Certificate does not cover %s", strings.Join(names, " or ")),
+			fmt.Sprintf("Subject %s; SANs: %s. Clients connecting by those names fail hostname verification.", info.Chain[0].Subject, dash(strings.Join(sans, ", "))),
+			"Reissue the certificate with the names clients use as subjectAltName entries"))
+	}
+	var old []string
+	for _, v := range info.Versions {
+		if v == "TLS 1.0" || v == "TLS 1.1" {
+			old = append(old, v)
+		}
+	}
+	if len(old) > 0 {
+		out = append(out, finding("info", "TLS_OLD_VERSION",
+			"Server accepts "+strings.Join(old, " and "),
+			"Accepted versions: "+strings.Join(info.Versions, ", ")+".",
+			"Require TLS 1.2 or later in the server configuration"))
+	}
+	return out
+}
+
+func weakKey(c model.Cert) string {
+	switch {
+	case c.Key == "RSA" && c.KeyBits > 0 && c.KeyBits < 2048:
+		return fmt.Sprintf("RSA %d", c.KeyBits)
+	case c.Key == "ECDSA" && c.KeyBits > 0 && c.KeyBits < 256:
+		return fmt.Sprintf("ECDSA %d", c.KeyBits)
+	}
+	return ""
+}
+
+func humanDays(d time.Duration) string {
+	n := int(d.Hours() / 24)
+	switch n {
+	case 0:
+		return "less than a day"
+	case 1:
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pratik-anurag/portik/internal/inspect"
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/netns"
+)
+
+type netnsFlags struct {
+	Spec string
+	All  bool
+}
+
+func addNetnsFlags(fs *flag.FlagSet) *netnsFlags {
+	f := &netnsFlags{}
+	fs.StringVar(&f.Spec, "netns", "", "inspect inside a network namespace: ip-netns name, pid, or path (linux)")
+	fs.BoolVar(&f.All, "all-netns", false, "inspect every network namespace (linux; usually needs root)")
+	return f
+}
+
+func (f *netnsFlags) active() bool {
+	return f.Spec != "" || f.All
+}
+
+// namespaces returns the namespaces to inspect; just the host's when no
+// flag is set.
+func (f *netnsFlags) namespaces() ([]netns.Namespace, error) {
+	switch {
+	case f.active() && fromBundle != "":
+		return nil, errors.New("--netns/--all-netns need a live system, not --from")
+	case f.Spec != "" && f.All:
+		return nil, errors.New("use only one of --netns and --all-netns")
+	case f.All:
+		return netns.All()
+	case f.Spec != "":
+		ns, err := netns.Resolve(f.Spec)
+		if err != nil {
+			return nil, err
+		}
+		return []netns.Namespace{ns}, nil
+	}
+	cur, err := netns.Current()
+	return []netns.Namespace{cur}, err
+}
+
+// each runs fn inside every namespace. With --all-netns, namespaces that
+// cannot be entered are reported on stderr and skipped.
+func (f *netnsFlags) each(cmd string, nss []netns.Namespace, fn func(ns netns.Namespace) error) error {
+	for _, ns := range nss {
+		err := netns.Do(ns, func() error { return fn(ns) })
+		if err == nil {
+			continue
+		}
+		if !f.All {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%s: skipping netns %s: %v\n", cmd, ns.Label(), err)
+	}
+	return nil
+}
+
+// label is the Netns tag for output rows; empty unless namespaces were
+// selected explicitly.
+func (f *netnsFlags) label(ns netns.Namespace) string {
+	if !f.active() {
+		return ""
+	}
+	return ns.Label()
+}
+
+// inspectNetns inspects t in every selected namespace and merges the
+// results into one report with listeners tagged by namespace.
+func inspectNetns(cmd string, t target, f *netnsFlags, opt inspect.Options) (model.Report, error) {
+	if !f.active() {
+		return t.inspect(opt)
+	}
+	nss, err := f.namespaces()
+	if err != nil {
+		return model.Report{}, err
+	}
+	var merged model.Report
+	found := false
+	err = f.each(cmd, nss, func(ns netns.Namespace) error {
+		rep, err := t.inspect(opt)
+		if err != nil {
+			return err
+		}
+		for i := range rep.Listeners {
+			rep.Listeners[i].Netns = f.label(ns)
+		}
+		if !found {
+			merged, found = rep, true
+			return nil
+		}
+		merged.Listeners = append(merged.Listeners, rep.Listeners...)
+		merged.Connections = append(merged.Connections, rep.Connections...)
+		merged.Diagnostics = append(merged.Diagnostics, rep.Diagnostics...)
+		return nil
+	})
+	if err != nil {
+		return model.Report{}, err
+	}
+	if !found {
+		return model.Report{}, errors.New("no network namespace could be inspected")
+	}
+	merged.Diagnostics = model.DedupeDiagnostics(merged.Diagnostics)
+	return merged, nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pratik-anurag/portik/internal/inspect"
+)
+
+// loadRules applies a rules file to the default diagnostic registry: the
+// one given with --rules or $PORTIK_RULES, else ~/.portik/rules.json when
+// it exists. Only an explicitly given file is fatal; a broken default one
+// is reported and skipped.
+func loadRules(file string) error {
+	explicit := file != ""
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		file = filepath.Join(home, ".portik", "rules.json")
+	}
+	f, err := inspect.LoadRules(file)
+	if err == nil {
+		err = inspect.Default.Apply(f, file)
+	}
+	switch {
+	case err == nil:
+		return nil
+	case explicit:
+		return fmt.Errorf("--rules: %w", err)
+	case !errors.Is(err, fs.ErrNotExist):
+		fmt.Fprintln(os.Stderr, "portik warning: rules file ignored:", err)
+	}
+	return nil
+}
+
+func runRules(args []string) int {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	asJSON := fs.Bool("json", false, "output JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	rules := inspect.Default.Rules()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(rules)
+		return 0
+	}
+	fmt.Println("RULE                  STATUS    SEVERITY  SOURCE")
+	fmt.Println("--------------------  --------  --------  ------")
+	for _, r := range rules {
+		status := "enabled"
+		if !r.Enabled {
+			status = "disabled"
+		}
+		sev := r.Severity
+		if sev == "" {
+			sev = "-"
+		}
+		fmt.Printf("%-20s  %-8s  %-8s  %s\n", r.ID, status, sev, r.Source)
+	}
+	return 0
+}
@@ -6,6 +6,11 @@ import (
 )
 
 func Run(args []string) int {
+	args, err := applyGlobal(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "portik:", err)
+		return 2
+	}
 	if len(args) == 0 {
 		printHelp()
 		return 0
@@ -52,8 +57,16 @@ func Run(args []string) int {
 		return runTrace(args[1:])
 	case "lint":
 		return runLint(args[1:])
+	case "tls":
+		return runTLS(args[1:])
+	case "check":
+		return runManifestCheck(args[1:])
 	case "graph":
 		return runGraph(args[1:])
+	case "rules":
+		return runRules(args[1:])
+	case "capture":
+		return runCapture(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", args[0])
 		printHelp()
@@ -84,20 +97,43 @@ Commands:
   use               Run a command with a free PORT selected automatically
   conn              Show active connections to/from a port (top clients)
   top               Top ports by connection count
-  wait              Wait until a port is listening or becomes free
-  trace             Trace ownership and routing hints for a port
+  wait              Wait until a port is listening, free, or passes a health check
+  trace             Trace ownership, tunnels and NAT forwards for a port
+  tls <port>        Show a listener's TLS certificate chain, versions and expiry problems
+  check             Compare listeners with a manifest of expected ports (--manifest FILE)
   graph             Local dependency graph between processes
+  capture           Write a snapshot bundle (sockets, processes, docker) for offline analysis
+  rules             List diagnostic rules, with overrides from the rules file
 
   version           Show version
 
+Global flags (before the command):
+  --backend NAME    Socket backend: auto|netlink|procfs|ss on Linux, auto|lsof on macOS
+                    (default from $PORTIK_BACKEND, else auto)
+  --from BUNDLE     Run who/explain/lint/check/graph/trace/scan against a bundle from
+                    "portik capture" instead of the live system
+  --rules FILE      Diagnostic rules file for who/explain/rules (default $PORTIK_RULES,
+                    else ~/.portik/rules.json)
+
 Common flags (per command):
-  --proto tcp|udp
+  --proto tcp|udp   (unix: who/explain/kill/wait take a socket path instead of a port)
   --docker          Enable Docker mapping (shells out to docker)
   --json            JSON output (where supported)
   --yes             Skip confirmation prompts
   --summary         Short output (where supported)
   --verbose         Verbose output (where supported)
   --no-hints        Suppress diagnostic hints (where supported)
+  --netns NAME|PID|PATH  who/scan/graph inside another network namespace (linux)
+  --all-netns       who/scan/graph across every network namespace (linux)
+  --probe           who/explain/scan: connect and identify the protocol served
+                    (HTTP(S), TLS, gRPC, SSH, Redis, Postgres, MySQL)
+  --http PATH [--expect 2xx] | --tls | --grpc-health | --tcp-connect
+                    wait/scan: readiness check (wait retries until it passes)
   --color           Color: auto|always|never
+
+explain flags:
+  --bind ADDR       Try a real bind on ADDR:<port> and explain conflicts
+  --reuseaddr, --reuseport, --v6only   Socket options for --bind
+  --exe PROGRAM     Check PROGRAM's file capabilities for ports below the unprivileged start
 `)
 }
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/pratik-anurag/portik/internal/inspect"
 	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/netns"
 	"github.com/pratik-anurag/portik/internal/ports"
+	"github.com/pratik-anurag/portik/internal/probe"
 	"github.com/pratik-anurag/portik/internal/render"
 	"github.com/pratik-anurag/portik/internal/sockets"
 )
@@ -23,10 +26,17 @@ type scanRow struct {
 	Owner     string `json:"owner,omitempty"`
 	PID       int32  `json:"pid,omitempty"`
 	Addr      string `json:"addr,omitempty"`
+	RecvQ     int    `json:"recv_q,omitempty"`
+	SendQ     int    `json:"send_q,omitempty"`
 	Docker    string `json:"docker,omitempty"`
+	Netns     string `json:"netns,omitempty"`
+	Container string `json:"container,omitempty"`
 	Hint      string `json:"hint,omitempty"`
 	Error     string `json:"error,omitempty"`
 	Signature string `json:"signature,omitempty"`
+
+	Probe  *model.Fingerprint `json:"probe,omitempty"`
+	Health *model.Health      `json:"health,omitempty"`
 }
 
 func runScan(args []string) int {
@@ -34,18 +44,23 @@ func runScan(args []string) int {
 	fs.SetOutput(os.Stderr)
 
 	c := parseCommon(fs)
+	nf := addNetnsFlags(fs)
 
 	var portsSpec string
 	var concurrency int
 	var all bool
 	var owner string
 	var minPort, maxPort int
+	var probeFlag bool
 	fs.StringVar(&portsSpec, "ports", "", "ports spec: e.g. 5432,6379,3000-3010")
 	fs.BoolVar(&all, "all", false, "scan all listening ports on the system")
 	fs.IntVar(&concurrency, "concurrency", 0, "number of concurrent checks (default: CPU count, max 32)")
 	fs.StringVar(&owner, "owner", "", "filter by owner/process name")
 	fs.IntVar(&minPort, "min-port", 0, "minimum port in results (after discovery)")
 	fs.IntVar(&maxPort, "max-port", 65535, "maximum port in results (after discovery)")
+	fs.BoolVar(&probeFlag, "probe", false, "connect to each listener and identify the protocol it serves (tcp)")
+
+	hf := addHealthFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return 2
@@ -62,17 +77,70 @@ func runScan(args []string) int {
 		fmt.Fprintln(os.Stderr, "scan: invalid --proto (tcp|udp)")
 		return 2
 	}
+	if probeFlag && (c.Proto != "tcp" || fromBundle != "") {
+		fmt.Fprintln(os.Stderr, "scan: --probe connects to live tcp listeners; it cannot be used with --proto udp or --from")
+		return 2
+	}
+	check, err := hf.check()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "scan:", err)
+		return 2
+	}
+	if check != nil && (c.Proto != "tcp" || fromBundle != "") {
+		fmt.Fprintln(os.Stderr, "scan: health checks connect to live tcp listeners; they cannot be used with --proto udp or --from")
+		return 2
+	}
 
-	var portsList []int
-	var err error
+	nss, err := nf.namespaces()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "scan:", err)
+		return 2
+	}
 
-	if all {
-		// Auto-discover all listening ports
-		portsList, err = getAllListeningPorts(c.Proto, minPort, maxPort)
+	var portsList []int
+	if !all {
+		portsList, err = ports.ParseSpec(portsSpec)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "scan: failed to discover ports: %v\n", err)
+			fmt.Fprintln(os.Stderr, "scan:", err)
 			return 2
 		}
+	}
+
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	if concurrency > 32 {
+		concurrency = 32
+	}
+
+	var rows []scanRow
+	discovered := map[int]bool{}
+	err = nf.each("scan", nss, func(ns netns.Namespace) error {
+		nsPorts := portsList
+		if all {
+			// Auto-discover all listening ports
+			var err error
+			nsPorts, err = getAllListeningPorts(c.Proto, minPort, maxPort)
+			if err != nil {
+				return fmt.Errorf("failed to discover ports: %w", err)
+			}
+			for _, p := range nsPorts {
+				discovered[p] = true
+			}
+		}
+		rows = append(rows, scanPorts(nsPorts, c.Proto, inspect.Options{EnableDocker: c.Docker, Probe: probeFlag}, check, concurrency, ns, nf.label(ns))...)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "scan:", err)
+		return 2
+	}
+	sortScanRows(rows)
+	if all {
+		for p := range discovered {
+			portsList = append(portsList, p)
+		}
+		sort.Ints(portsList)
 		if len(portsList) == 0 {
 			if c.JSON {
 				enc := json.NewEncoder(os.Stdout)
@@ -88,23 +156,8 @@ func runScan(args []string) int {
 			}
 			return 0
 		}
-	} else {
-		portsList, err = ports.ParseSpec(portsSpec)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "scan:", err)
-			return 2
-		}
 	}
 
-	if concurrency <= 0 {
-		concurrency = runtime.NumCPU()
-	}
-	if concurrency > 32 {
-		concurrency = 32
-	}
-
-	rows := scanPorts(portsList, c.Proto, c.Docker, concurrency)
-
 	// Apply owner filter if specified
 	if owner != "" {
 		var filtered []scanRow
@@ -142,7 +195,10 @@ func runScan(args []string) int {
 	return 0
 }
 
-func scanPorts(portsList []int, proto string, docker bool, conc int) []scanRow {
+// scanPorts inspects each port inside ns and, with a check, runs it against
+// every port in use. Every worker enters the namespace itself since
+// goroutines do not inherit it.
+func scanPorts(portsList []int, proto string, opt inspect.Options, check *probe.Check, conc int, ns netns.Namespace, nsLabel string) []scanRow {
 	type job struct {
 		port int
 	}
@@ -154,11 +210,25 @@ func scanPorts(portsList []int, proto string, docker bool, conc int) []scanRow {
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
-			rep, err := inspect.InspectPort(j.port, proto, inspect.Options{
-				EnableDocker:       docker,
-				IncludeConnections: false, // fast scan
+			var rep model.Report
+			var health *model.Health
+			err := netns.Do(ns, func() error {
+				var err error
+				rep, err = inspect.InspectPort(j.port, proto, opt) // no connections: fast scan
+				if err != nil || check == nil || isFree(rep) {
+					return err
+				}
+				l, _ := rep.PrimaryListener()
+				h := check.Listener(l)
+				health = &h
+				return nil
 			})
+			if err != nil {
+				rep.Port, rep.Proto = j.port, proto
+			}
 			row := reportToScanRow(rep, err)
+			row.Netns = nsLabel
+			row.Health = health
 			mu.Lock()
 			out = append(out, row)
 			mu.Unlock()
@@ -198,11 +268,18 @@ func reportToScanRow(rep model.Report, err error) scanRow {
 		Signature: rep.Signature(),
 	}
 
-	if l, ok := rep.PrimaryListener(); ok && l.PID > 0 && strings.ToUpper(l.State) == "LISTEN" {
+	l, ok := rep.PrimaryListener()
+	if ok {
+		row.Probe = l.Probe
+	}
+	if ok && l.PID > 0 && strings.ToUpper(l.State) == "LISTEN" {
 		row.Status = "in-use"
 		row.PID = l.PID
 		row.Owner = ownerShort(l)
 		row.Addr = addrShort(l.LocalIP, l.LocalPort)
+		row.RecvQ = l.RecvQ
+		row.SendQ = l.SendQ
+		row.Container = l.Container
 	} else if len(rep.Listeners) > 0 {
 		row.Status = "unknown"
 	}
@@ -255,19 +332,20 @@ func addrShort(ip string, port int) string {
 }
 
 func sortScanRows(rows []scanRow) {
-	// tiny custom sorter to avoid extra imports in this file
-	for i := 0; i < len(rows); i++ {
-		for j := i + 1; j < len(rows); j++ {
-			if rows[j].Port < rows[i].Port {
-				rows[i], rows[j] = rows[j], rows[i]
-			}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Port != rows[j].Port {
+			return rows[i].Port < rows[j].Port
 		}
-	}
+		return rows[i].Netns < rows[j].Netns
+	})
 }
 
 func toRenderRows(in []scanRow) render.ScanRows {
 	out := make(render.ScanRows, 0, len(in))
 	for _, r := range in {
+		if r.Docker == "" {
+			r.Docker = r.Container
+		}
 		out = append(out, struct {
 			Port   int
 			Proto  string
@@ -275,17 +353,40 @@ func toRenderRows(in []scanRow) render.ScanRows {
 			Owner  string
 			PID    int32
 			Addr   string
+			Queue  string
 			Docker string
+			Netns  string
+			Probe  string
+			Health string
 			Hint   string
 			Error  string
 		}{
 			Port: r.Port, Proto: r.Proto, Status: r.Status, Owner: r.Owner,
-			PID: r.PID, Addr: r.Addr, Docker: r.Docker, Hint: r.Hint, Error: r.Error,
+			PID: r.PID, Addr: r.Addr, Queue: render.QueueLabel(model.Listener{RecvQ: r.RecvQ, SendQ: r.SendQ}),
+			Docker: r.Docker, Netns: r.Netns, Probe: probeLabel(r.Probe), Health: healthLabel(r.Health), Hint: r.Hint, Error: r.Error,
 		})
 	}
 	return out
 }
 
+// probeLabel is the short SERVES cell: "https 200 nginx", "postgres".
+func probeLabel(fp *model.Fingerprint) string {
+	if fp == nil {
+		return ""
+	}
+	if fp.Protocol == "" {
+		return "no answer"
+	}
+	label := fp.Protocol
+	if fp.Status != 0 {
+		label += fmt.Sprintf(" %d", fp.Status)
+	}
+	if fp.Server != "" {
+		label += " " + fp.Server
+	}
+	return label
+}
+
 // getAllListeningPorts discovers all listening ports via OS socket inspection
 // and optionally filters by minPort/maxPort range.
 func getAllListeningPorts(proto string, minPort, maxPort int) ([]int, error) {
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/history"
+	"github.com/pratik-anurag/portik/internal/inspect"
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/render"
+)
+
+// target is what who/explain/kill/wait act on: a port, or a socket path
+// with --proto unix.
+type target struct {
+	Port  int
+	Path  string
+	Proto string
+	Netns bool // inspected with --netns/--all-netns
+}
+
+func parseTarget(arg, proto string) (target, error) {
+	if proto != "unix" {
+		port, err := parsePort(arg)
+		if err != nil {
+			return target{}, err
+		}
+		return target{Port: port, Proto: proto}, nil
+	}
+	path := strings.TrimSpace(arg)
+	if path == "" {
+		return target{}, errors.New("missing socket path")
+	}
+	// abstract sockets ("@name") have no file
+	if !strings.HasPrefix(path, "@") {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+	}
+	return target{Path: path, Proto: proto}, nil
+}
+
+func (t target) String() string {
+	if t.Path != "" {
+		return t.Path
+	}
+	return fmt.Sprintf("%d/%s", t.Port, t.Proto)
+}
+
+func (t target) inspect(opt inspect.Options) (model.Report, error) {
+	if t.Path != "" {
+		return inspect.InspectUnix(t.Path, opt)
+	}
+	return inspect.InspectPort(t.Port, t.Proto, opt)
+}
+
+// record saves ownership history. History is keyed by host port, so unix
+// sockets, other namespaces and replayed bundles are not tracked.
+func (t target) record(rep model.Report) {
+	if t.Path == "" && !t.Netns && fromBundle == "" {
+		_ = history.Record(rep)
+	}
+}
+
+func (t target) recentOwners(n int) []render.OwnerEvent {
+	if t.Path != "" || t.Netns || fromBundle != "" {
+		return nil
+	}
+	return recentOwners(t.Port, t.Proto, n)
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/inspect"
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/probe"
+	"github.com/pratik-anurag/portik/internal/render"
+)
+
+type tlsOutput struct {
+	Port     int                 `json:"port"`
+	Listener model.Listener      `json:"listener"`
+	TLS      model.TLSInfo       `json:"tls"`
+	Names    []string            `json:"names"` // names the leaf certificate was checked against
+	Findings []model.LintFinding `json:"findings"`
+}
+
+func runTLS(args []string) int {
+	fs := flag.NewFlagSet("tls", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	var jsonOut bool
+	var serverName, startTLS string
+	var days int
+	fs.BoolVar(&jsonOut, "json", false, "output JSON")
+	fs.StringVar(&serverName, "servername", "", "SNI to send; the certificate is checked against it instead of the bound address")
+	fs.StringVar(&startTLS, "starttls", "auto", "how to reach TLS: auto|none|postgres|smtp (auto: by port)")
+	fs.IntVar(&days, "days", 30, "warn about certificates expiring within this many days")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "tls: missing <port>")
+		fmt.Fprintln(os.Stderr, "Usage: portik tls [--servername NAME] [--starttls auto|none|postgres|smtp] [--days 30] [--json] <port>")
+		return 2
+	}
+	port, err := parsePort(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tls:", err)
+		return 2
+	}
+	switch startTLS {
+	case "auto":
+		startTLS = ""
+	case "none", "postgres", "smtp":
+	default:
+		fmt.Fprintln(os.Stderr, "tls: invalid --starttls (auto|none|postgres|smtp)")
+		return 2
+	}
+	if days < 0 {
+		fmt.Fprintln(os.Stderr, "tls: invalid --days")
+		return 2
+	}
+
+	rep, err := inspect.InspectPort(port, "tcp", inspect.Options{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	l, ok := rep.PrimaryListener()
+	if !ok || !strings.EqualFold(l.State, "LISTEN") {
+		fmt.Fprintf(os.Stderr, "tls: nothing is listening on %d/tcp\n", port)
+		return 1
+	}
+
+	info, err := inspectListenerTLS(l, probe.TLSOptions{ServerName: serverName, StartTLS: startTLS})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tls: %s: %v\n", info.Addr, err)
+		if errors.Is(err, probe.ErrNoTLS) && startTLS == "" {
+			fmt.Fprintln(os.Stderr, "If the service negotiates TLS in-protocol, try --starttls postgres|smtp.")
+		}
+		return 1
+	}
+
+	names := boundNames(l, platform.HostSummary().Hostname)
+	if serverName != "" {
+		names = []string{serverName}
+	}
+	findings := tlsFindings(info, l, names, days, platform.Now())
+
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(tlsOutput{Port: port, Listener: l, TLS: info, Names: names, Findings: findings})
+	} else {
+		fmt.Print(render.TLS(port, l, info, findings, platform.Now()))
+	}
+	for _, f := range findings {
+		if f.Severity == "error" {
+			return 1
+		}
+	}
+	return 0
+}
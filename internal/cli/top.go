@@ -110,16 +110,28 @@ func runTop(args []string) int {
 
 func topClients(conns []model.Conn, limit int) []render.TopClient {
 	counts := map[string]int{}
+	health := map[string]*connHealth{}
 	for _, c := range conns {
 		ip := strings.TrimSpace(c.RemoteIP)
 		if ip == "" {
 			ip = "(unknown)"
 		}
 		counts[ip]++
+		if c.TCP != nil {
+			if health[ip] == nil {
+				health[ip] = &connHealth{}
+			}
+			health[ip].add(c.TCP)
+		}
 	}
 	var out []render.TopClient
 	for ip, n := range counts {
-		out = append(out, render.TopClient{IP: ip, Count: n})
+		tc := render.TopClient{IP: ip, Count: n}
+		if h := health[ip]; h != nil {
+			tc.AvgRTTMs = h.AvgRTTMs
+			tc.Retrans = h.Retrans
+		}
+		out = append(out, tc)
 	}
 	sort.Slice(out, func(i, j int) bool {
 		if out[i].Count == out[j].Count {
@@ -13,9 +13,10 @@ import (
 )
 
 type traceOutput struct {
-	Port  int          `json:"port"`
-	Proto string       `json:"proto"`
-	Steps []trace.Step `json:"steps"`
+	Port     int          `json:"port"`
+	Proto    string       `json:"proto"`
+	Forwards []trace.Path `json:"forwards,omitempty"`
+	Steps    []trace.Step `json:"steps"`
 }
 
 func runTrace(args []string) int {
@@ -46,12 +47,15 @@ func runTrace(args []string) int {
 	if l, ok := rep.PrimaryListener(); ok && l.PID > 0 {
 		chain, started = proctree.Build(l.PID, 8)
 	}
-	steps := trace.Steps(rep, chain, started)
+	// NAT happens before the packet reaches any listener, so its path
+	// comes first.
+	paths := trace.FollowNAT(port, c.Proto, rep.Docker)
+	steps := append(trace.ForwardSteps(port, paths), trace.Steps(rep, chain, started)...)
 
 	if c.JSON {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		_ = enc.Encode(traceOutput{Port: port, Proto: c.Proto, Steps: steps})
+		_ = enc.Encode(traceOutput{Port: port, Proto: c.Proto, Forwards: paths, Steps: steps})
 		return 0
 	}
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pratik-anurag/portik/internal/inspect"
 	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/probe"
 )
 
 func runWait(args []string) int {
@@ -19,28 +20,31 @@ func runWait(args []string) int {
 	var docker bool
 	var timeoutStr string
 	var intervalStr string
+	var maxIntervalStr string
 	var wantListening bool
 	var wantFree bool
 	var quiet bool
 
-	fs.StringVar(&proto, "proto", "tcp", "protocol: tcp|udp (default tcp)")
+	fs.StringVar(&proto, "proto", "tcp", "protocol: tcp|udp|unix (default tcp)")
 	fs.BoolVar(&docker, "docker", false, "enable docker mapping (optional; not required)")
 	fs.StringVar(&timeoutStr, "timeout", "30s", "max time to wait")
-	fs.StringVar(&intervalStr, "interval", "500ms", "poll interval")
+	fs.StringVar(&intervalStr, "interval", "500ms", "poll interval; with a readiness check it doubles after each failed attempt")
+	fs.StringVar(&maxIntervalStr, "max-interval", "", "longest poll interval for readiness checks (default --interval)")
 	fs.BoolVar(&wantListening, "listening", false, "wait until port is LISTENING")
 	fs.BoolVar(&wantFree, "free", false, "wait until port is FREE (no listener)")
 	fs.BoolVar(&quiet, "quiet", false, "no output (exit code only)")
+	hf := addHealthFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 	if fs.NArg() < 1 {
 		fmt.Fprintln(os.Stderr, "wait: missing <port>")
-		fmt.Fprintln(os.Stderr, "Usage: portik wait <port> --listening|--free [--timeout 30s] [--interval 500ms]")
+		fmt.Fprintln(os.Stderr, "Usage: portik wait [--listening|--free] [--http PATH [--expect 200] | --tcp-connect | --tls | --grpc-health] [--proto unix] [--timeout 30s] [--interval 500ms] <port>|<path>")
 		return 2
 	}
-	if proto != "tcp" && proto != "udp" {
-		fmt.Fprintln(os.Stderr, "wait: invalid --proto (tcp|udp)")
+	if proto != "tcp" && proto != "udp" && proto != "unix" {
+		fmt.Fprintln(os.Stderr, "wait: invalid --proto (tcp|udp|unix)")
 		return 2
 	}
 
@@ -53,7 +57,17 @@ func runWait(args []string) int {
 		return 2
 	}
 
-	port, err := parsePort(fs.Arg(0))
+	check, err := hf.check()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "wait:", err)
+		return 2
+	}
+	if check != nil && (wantFree || proto != "tcp") {
+		fmt.Fprintln(os.Stderr, "wait: readiness checks need a tcp port and cannot be combined with --free")
+		return 2
+	}
+
+	t, err := parseTarget(fs.Arg(0), proto)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "wait:", err)
 		return 2
@@ -69,44 +83,84 @@ func runWait(args []string) int {
 		fmt.Fprintln(os.Stderr, "wait: invalid --interval")
 		return 2
 	}
+	maxInterval := interval
+	if maxIntervalStr != "" {
+		maxInterval, err = time.ParseDuration(maxIntervalStr)
+		if err != nil || maxInterval < interval {
+			fmt.Fprintln(os.Stderr, "wait: invalid --max-interval (must be at least --interval)")
+			return 2
+		}
+	}
 
+	mode := "LISTENING"
+	switch {
+	case wantFree:
+		mode = "FREE"
+	case check != nil:
+		mode = "READY"
+	}
 	deadline := time.Now().Add(timeout)
+	var last string // why the last attempt failed
 	for {
-		rep, err := inspect.InspectPort(port, proto, inspect.Options{
+		rep, err := t.inspect(inspect.Options{
 			EnableDocker:       docker,
 			IncludeConnections: false,
 		})
-		if err == nil {
-			ok := false
-			if wantListening {
-				ok = isListening(rep)
-			} else {
-				ok = isFree(rep)
-			}
+		ok := false
+		switch {
+		case err != nil:
+			last = err.Error()
+		case wantFree:
+			ok = isFree(rep)
+		case check == nil:
+			ok = isListening(rep)
+		case !isFree(rep):
+			// the owner may be hidden from us; the check is what counts
+			h := runCheck(*check, rep, time.Until(deadline))
+			ok, last = h.OK, h.Detail
 			if ok {
-				if !quiet {
-					if wantListening {
-						fmt.Printf("%d/%s is LISTENING\n", port, proto)
-					} else {
-						fmt.Printf("%d/%s is FREE\n", port, proto)
-					}
+				last = fmt.Sprintf("%s, %.0fms", h.Detail, h.LatencyMs)
+			}
+		default:
+			last = "not listening"
+		}
+		if ok {
+			if !quiet {
+				if check != nil {
+					fmt.Printf("%s is %s (%s)\n", t, mode, last)
+				} else {
+					fmt.Printf("%s is %s\n", t, mode)
 				}
-				return 0
 			}
+			return 0
 		}
 
 		if time.Now().After(deadline) {
 			if !quiet {
-				mode := "LISTENING"
-				if wantFree {
-					mode = "FREE"
+				if check != nil && last != "" {
+					fmt.Fprintf(os.Stderr, "wait: timeout waiting for %s to be %s: %s\n", t, mode, last)
+				} else {
+					fmt.Fprintf(os.Stderr, "wait: timeout waiting for %s to be %s\n", t, mode)
 				}
-				fmt.Fprintf(os.Stderr, "wait: timeout waiting for %d/%s to be %s\n", port, proto, mode)
 			}
 			return 1
 		}
-		time.Sleep(interval)
+		time.Sleep(min(interval, time.Until(deadline)+time.Millisecond))
+		if check != nil {
+			// back off while a service warms up; plain polls stay fixed
+			interval = min(2*interval, maxInterval)
+		}
+	}
+}
+
+// runCheck checks the port's primary listener, never running past the
+// overall deadline.
+func runCheck(c probe.Check, rep model.Report, left time.Duration) model.Health {
+	if left > 0 && left < c.Timeout {
+		c.Timeout = left
 	}
+	l, _ := rep.PrimaryListener()
+	return c.Listener(l)
 }
 
 func isListening(rep model.Report) bool {
@@ -7,7 +7,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/pratik-anurag/portik/internal/history"
 	"github.com/pratik-anurag/portik/internal/inspect"
 	"github.com/pratik-anurag/portik/internal/render"
 )
@@ -16,38 +15,50 @@ func runWho(args []string) int {
 	fs := flag.NewFlagSet("who", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 	c := parseCommon(fs)
-	var follow bool
+	nf := addNetnsFlags(fs)
+	var follow, probe bool
 	var intervalStr string
 	fs.BoolVar(&follow, "follow", false, "stream changes (delta-only)")
 	fs.StringVar(&intervalStr, "interval", "2s", "poll interval for --follow")
+	fs.BoolVar(&probe, "probe", false, "connect to the listener and identify the protocol it serves")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "who: missing <port>")
+		fmt.Fprintln(os.Stderr, "who: missing <port> (or <path> with --proto unix)")
 		return 2
 	}
-	port, err := parsePort(fs.Arg(0))
+	t, err := parseTarget(fs.Arg(0), c.Proto)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "who:", err)
 		return 2
 	}
+	t.Netns = nf.active()
 
+	if follow && fromBundle != "" {
+		fmt.Fprintln(os.Stderr, "who: --follow needs a live system, not --from")
+		return 2
+	}
+	if probe && (t.Path != "" || fromBundle != "") {
+		fmt.Fprintln(os.Stderr, "who: --probe connects to a live tcp listener; it cannot be used with unix sockets or --from")
+		return 2
+	}
+	opt := inspect.Options{EnableDocker: c.Docker, IncludeConnections: false, Probe: probe}
 	if follow {
 		interval, err := time.ParseDuration(intervalStr)
 		if err != nil || interval < 200*time.Millisecond {
 			fmt.Fprintln(os.Stderr, "who: invalid --interval")
 			return 2
 		}
-		return followWho(port, c, interval)
+		return followWho(t, c, nf, opt, interval)
 	}
 
-	rep, err := inspect.InspectPort(port, c.Proto, inspect.Options{EnableDocker: c.Docker, IncludeConnections: false})
+	rep, err := inspectNetns("who", t, nf, opt)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		return 1
 	}
-	_ = history.Record(rep)
+	t.record(rep)
 
 	if c.JSON {
 		enc := json.NewEncoder(os.Stdout)
@@ -56,21 +67,21 @@ func runWho(args []string) int {
 		return 0
 	}
 
-	opt := renderOptions(c)
-	opt.RecentOwners = recentOwners(port, c.Proto, 3)
-	fmt.Print(render.Who(rep, opt))
+	ropt := renderOptions(c)
+	ropt.RecentOwners = t.recentOwners(3)
+	fmt.Print(render.Who(rep, ropt))
 	return 0
 }
 
-func followWho(port int, c *commonFlags, interval time.Duration) int {
+func followWho(t target, c *commonFlags, nf *netnsFlags, opt inspect.Options, interval time.Duration) int {
 	var lastSig string
-	t := time.NewTicker(interval)
-	defer t.Stop()
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
 
 	for {
-		rep, err := inspect.InspectPort(port, c.Proto, inspect.Options{EnableDocker: c.Docker, IncludeConnections: false})
+		rep, err := inspectNetns("who", t, nf, opt)
 		if err == nil {
-			_ = history.Record(rep)
+			t.record(rep)
 			sig := rep.Signature()
 			if sig != lastSig {
 				lastSig = sig
@@ -79,20 +90,20 @@ func followWho(port int, c *commonFlags, interval time.Duration) int {
 					enc.SetIndent("", "  ")
 					_ = enc.Encode(rep)
 				} else {
-					opt := renderOptions(c)
-					opt.RecentOwners = recentOwners(port, c.Proto, 3)
-					fmt.Print(changeBanner(opt.Color, port, c.Proto))
-					fmt.Print(render.Who(rep, opt))
+					ropt := renderOptions(c)
+					ropt.RecentOwners = t.recentOwners(3)
+					fmt.Print(changeBanner(ropt.Color, t))
+					fmt.Print(render.Who(rep, ropt))
 					fmt.Println("---")
 				}
 			}
 		}
-		<-t.C
+		<-tick.C
 	}
 }
 
-func changeBanner(color bool, port int, proto string) string {
-	msg := fmt.Sprintf("Change @ %s for %d/%s\n", time.Now().Format("15:04:05"), port, proto)
+func changeBanner(color bool, t target) string {
+	msg := fmt.Sprintf("Change @ %s for %s\n", time.Now().Format("15:04:05"), t)
 	if !color {
 		return msg
 	}
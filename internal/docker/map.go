@@ -8,18 +8,102 @@ import (
 	"github.com/pratik-anurag/portik/internal/model"
 )
 
+// Container is one running container and its published ports, as recorded
+// in a snapshot bundle.
+type Container struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Ports          string `json:"ports,omitempty"` // `docker port <id>` output
+	ComposeService string `json:"compose_service,omitempty"`
+}
+
+// Source maps a host port to the container publishing it. Live uses the
+// docker CLI (or a replayed bundle).
+type Source interface {
+	MapPort(port int, proto string) model.DockerMap
+}
+
+type live struct{}
+
+func (live) MapPort(port int, proto string) model.DockerMap { return MapPort(port, proto) }
+
+// Live queries the local docker daemon.
+var Live Source = live{}
+
+var (
+	replaying bool
+	replayed  []Container
+)
+
+// Replay makes MapPort answer from captured containers instead of the
+// docker CLI. nil means docker was not captured, so nothing is checked.
+func Replay(cs []Container) {
+	replaying, replayed = true, cs
+}
+
 func MapPort(port int, proto string) model.DockerMap {
+	if replaying {
+		if replayed == nil {
+			return model.DockerMap{}
+		}
+		return mapContainers(replayed, port, proto)
+	}
 	m := model.DockerMap{Checked: true}
-	if _, err := exec.LookPath("docker"); err != nil {
-		return m
+	for _, c := range listContainers() {
+		po, err := exec.Command("docker", "port", c.ID).Output()
+		if err != nil {
+			continue
+		}
+		if mapped, cport := parseDockerPortOutput(po, port, proto); mapped {
+			m.Mapped = true
+			m.ContainerID = c.ID
+			m.ContainerName = c.Name
+			m.ContainerPort = cport
+			m.ComposeService = composeServiceLabel(c.ID)
+			return m
+		}
 	}
+	return m
+}
 
+// Containers returns every running container with its published ports and
+// compose service, or nil when docker is unavailable.
+func Containers() []Container {
+	cs := listContainers()
+	for i := range cs {
+		if po, err := exec.Command("docker", "port", cs[i].ID).Output(); err == nil {
+			cs[i].Ports = strings.TrimSpace(string(po))
+		}
+		cs[i].ComposeService = composeServiceLabel(cs[i].ID)
+	}
+	return cs
+}
+
+func mapContainers(cs []Container, port int, proto string) model.DockerMap {
+	m := model.DockerMap{Checked: true}
+	for _, c := range cs {
+		if mapped, cport := parseDockerPortOutput([]byte(c.Ports), port, proto); mapped {
+			m.Mapped = true
+			m.ContainerID = c.ID
+			m.ContainerName = c.Name
+			m.ContainerPort = cport
+			m.ComposeService = c.ComposeService
+			return m
+		}
+	}
+	return m
+}
+
+func listContainers() []Container {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil
+	}
 	out, err := exec.Command("docker", "ps", "--format", "{{.ID}} {{.Names}}").Output()
 	if err != nil {
-		return m
+		return nil
 	}
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	for _, line := range lines {
+	var cs []Container
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
@@ -27,23 +111,9 @@ func MapPort(port int, proto string) model.DockerMap {
 		if len(parts) < 2 {
 			continue
 		}
-		id := strings.TrimSpace(parts[0])
-		name := strings.TrimSpace(parts[1])
-
-		po, err := exec.Command("docker", "port", id).Output()
-		if err != nil {
-			continue
-		}
-		if mapped, cport := parseDockerPortOutput(po, port, proto); mapped {
-			m.Mapped = true
-			m.ContainerID = id
-			m.ContainerName = name
-			m.ContainerPort = cport
-			m.ComposeService = composeServiceLabel(id)
-			return m
-		}
+		cs = append(cs, Container{ID: strings.TrimSpace(parts[0]), Name: strings.TrimSpace(parts[1])})
 	}
-	return m
+	return cs
 }
 
 func parseDockerPortOutput(b []byte, hostPort int, proto string) (bool, string) {
@@ -0,0 +1,191 @@
+// Package firewall evaluates host firewall rulesets (ufw, nftables,
+// iptables) for one inbound packet: is traffic to a listener's address,
+// port and protocol accepted, dropped or rejected, and by which rule.
+//
+// The packet is a new connection from some remote host arriving on a
+// non-loopback interface. Rules that only apply to some sources, or that
+// depend on what a client sent before (rate limits, recent lists), are not
+// taken to match; they are reported as notes instead.
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Packet is the inbound traffic to evaluate.
+type Packet struct {
+	Proto   string // tcp|udp
+	Family  string // ipv4|ipv6
+	DstIP   net.IP // nil: any local address
+	DstPort int
+	Ifaces  []string // the host's interfaces; nil: unknown
+}
+
+func (p Packet) String() string {
+	dst := "*"
+	if p.DstIP != nil {
+		dst = p.DstIP.String()
+	}
+	return fmt.Sprintf("%s %s", p.Proto, net.JoinHostPort(dst, strconv.Itoa(p.DstPort)))
+}
+
+// Verdict is what the firewall does with a packet.
+type Verdict struct {
+	Action string   `json:"action"`           // accept|drop|reject, or unknown when unresolved rules precede the policy
+	Source string   `json:"source"`           // ufw|nftables|iptables
+	Rule   string   `json:"rule"`             // the deciding rule
+	Chain  string   `json:"chain,omitempty"`  // nftables base chain that decided, "family table name"
+	Policy bool     `json:"policy,omitempty"` // decided by a default policy, not a rule
+	Notes  []string `json:"notes,omitempty"`  // rules that apply to some sources or interfaces only
+}
+
+// Dumps are raw ruleset listings keyed by tool, as platform.FirewallRules
+// returns them: "ufw" (ufw status verbose + numbered), "ufw-apps" (the
+// profiles in /etc/ufw/applications.d), "nft" (nft list ruleset -j),
+// "iptables" and "ip6tables" (iptables-save).
+type Dumps map[string]string
+
+// Evaluate decides p against the most specific ruleset available: ufw when
+// it is active, else native nftables, else iptables. ok is false when no
+// ruleset could be read.
+func Evaluate(d Dumps, p Packet) (Verdict, bool) {
+	if txt := d["ufw"]; txt != "" {
+		if u, err := ParseUFW(txt); err == nil && u.Active {
+			if apps := d["ufw-apps"]; apps != "" {
+				u.Apps = ParseUFWApps(apps)
+			}
+			return u.Evaluate(p), true
+		}
+	}
+	if txt := d["nft"]; txt != "" {
+		if n, err := ParseNft([]byte(txt)); err == nil && n.HasInput() {
+			return n.Evaluate(p), true
+		}
+	}
+	key := "iptables"
+	if p.Family == "ipv6" {
+		key = "ip6tables"
+	}
+	if txt := d[key]; txt != "" {
+		if t, err := ParseIptables(txt); err == nil && t.HasInput() {
+			return t.Evaluate(p), true
+		}
+	}
+	return Verdict{}, false
+}
+
+// portSpec is a set of ports written as "22", "1000:2000", "80,443,8000:8100"
+// or "1000-2000".
+func portInSpec(spec string, port int) bool {
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, ":")
+		if !isRange {
+			lo, hi, isRange = strings.Cut(part, "-")
+		}
+		a, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		if port >= a && port <= b {
+			return true
+		}
+	}
+	return false
+}
+
+// addrMatches reports whether ip is in one of the comma-separated
+// addresses or CIDRs of spec.
+func addrMatches(spec string, ip net.IP) bool {
+	for _, a := range strings.Split(spec, ",") {
+		a = strings.TrimSpace(a)
+		if strings.Contains(a, "/") {
+			if _, n, err := net.ParseCIDR(a); err == nil && n.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if x := net.ParseIP(a); x != nil && x.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyAddr reports whether an address spec matches every address.
+func anyAddr(spec string) bool {
+	switch spec {
+	case "", "0.0.0.0/0", "::/0", "Anywhere", "any":
+		return true
+	}
+	return false
+}
+
+func isLoopbackIface(name string) bool {
+	return name == "lo" || name == "lo+"
+}
+
+// bridgePrefixes name the interfaces of container and VM bridges. Traffic
+// from a remote host does not arrive on them.
+var bridgePrefixes = []string{"docker", "br-", "cni", "virbr", "veth", "cali", "flannel", "podman", "lxc", "kube-"}
+
+// externalIface reports whether a rule's input interface may be the one
+// remote traffic arrives on: anything but loopback and bridges.
+func externalIface(name string) bool {
+	if isLoopbackIface(name) {
+		return false
+	}
+	for _, p := range bridgePrefixes {
+		if strings.HasPrefix(name, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// elsewhere reports whether remote traffic may arrive on an interface other
+// than those named by a negated input-interface match ("! -i eth0"): always
+// when they are all loopback or bridges, otherwise only when the host has
+// another external interface. With the interfaces unknown, a named external
+// interface is taken to be the only one.
+func (p Packet) elsewhere(names []string) bool {
+	external := false
+	for _, n := range names {
+		external = external || externalIface(n)
+	}
+	if !external {
+		return true
+	}
+	for _, i := range p.Ifaces {
+		if externalIface(i) && !ifaceIn(i, names) {
+			return true
+		}
+	}
+	return false
+}
+
+// ifaceIn reports whether iface matches one of the names, which may end in
+// a wildcard ("eth+" in iptables, "eth*" in nftables).
+func ifaceIn(iface string, names []string) bool {
+	for _, n := range names {
+		if prefix, ok := strings.CutSuffix(n, "+"); ok && strings.HasPrefix(iface, prefix) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(n, "*"); ok && strings.HasPrefix(iface, prefix) {
+			return true
+		}
+		if n == iface {
+			return true
+		}
+	}
+	return false
+}
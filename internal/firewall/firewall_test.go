@@ -0,0 +1,285 @@
+package firewall
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+type fwCase struct {
+	name   string
+	p      Packet
+	action string
+	rule   string // substring of the deciding rule
+	policy bool
+	note   string // substring of a note, if any
+}
+
+func tcp4(port int) Packet { return Packet{Proto: "tcp", Family: "ipv4", DstPort: port} }
+
+func check(t *testing.T, eval func(Packet) Verdict, cases []fwCase) {
+	t.Helper()
+	for _, c := range cases {
+		v := eval(c.p)
+		if v.Action != c.action || !strings.Contains(v.Rule, c.rule) || v.Policy != c.policy {
+			t.Errorf("%s: got %s by %q (policy=%v), want %s by %q", c.name, v.Action, v.Rule, v.Policy, c.action, c.rule)
+		}
+		if c.note != "" && !strings.Contains(strings.Join(v.Notes, "\n"), c.note) {
+			t.Errorf("%s: notes %q lack %q", c.name, v.Notes, c.note)
+		}
+	}
+}
+
+func TestIptables(t *testing.T) {
+	ipt, err := ParseIptables(fixture(t, "iptables-save.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ipt.HasInput() {
+		t.Fatal("HasInput = false")
+	}
+	web := tcp4(9090)
+	web.DstIP = net.ParseIP("192.168.1.10")
+	other := tcp4(9090)
+	other.DstIP = net.ParseIP("192.168.1.11")
+	check(t, ipt.Evaluate, []fwCase{
+		{name: "ssh", p: tcp4(22), action: "accept", rule: "--dport 22"},
+		{name: "source-restricted", p: tcp4(5432), action: "drop", rule: "INPUT policy DROP", policy: true, note: "only from 10.0.0.0/8"},
+		{name: "user chain reject", p: tcp4(8080), action: "reject", rule: "-A app-in -p tcp -m tcp --dport 8080"},
+		{name: "multiport", p: tcp4(443), action: "accept", rule: "--dports 80,443"},
+		{name: "udp range", p: Packet{Proto: "udp", Family: "ipv4", DstPort: 60500}, action: "accept", rule: "60000:61000"},
+		{name: "dst match", p: web, action: "accept", rule: "-d 192.168.1.10/32"},
+		{name: "dst mismatch", p: other, action: "drop", rule: "INPUT policy DROP", policy: true},
+		{name: "wildcard dst", p: tcp4(9090), action: "drop", policy: true, note: "only to 192.168.1.10/32"},
+		{name: "return to policy", p: tcp4(6000), action: "drop", rule: "INPUT policy DROP", policy: true},
+	})
+
+	ip6, err := ParseIptables(fixture(t, "ip6tables-save.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcp6 := func(port int) Packet { return Packet{Proto: "tcp", Family: "ipv6", DstPort: port} }
+	check(t, ip6.Evaluate, []fwCase{
+		{name: "v6 drop", p: tcp6(6379), action: "drop", rule: "--dport 6379"},
+		{name: "negated source", p: tcp6(9200), action: "accept", policy: true, note: "not from fd00::/8"},
+		{name: "v6 policy", p: tcp6(22), action: "accept", rule: "INPUT policy ACCEPT", policy: true},
+	})
+}
+
+func TestIptablesRateLimit(t *testing.T) {
+	ipt, err := ParseIptables(fixture(t, "iptables-ratelimit.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, ipt.Evaluate, []fwCase{
+		{name: "ssh past the recent list", p: tcp4(22), action: "accept", rule: "--dport 22 -j ACCEPT", note: "depends on -m recent"},
+		{name: "limited web", p: tcp4(80), action: "drop", rule: "INPUT policy DROP", policy: true, note: "depends on -m limit"},
+	})
+}
+
+func TestIptablesNegatedInterface(t *testing.T) {
+	ipt, err := ParseIptables("*filter\n:INPUT ACCEPT [0:0]\n-A INPUT ! -i eth0 -j DROP\nCOMMIT\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	on := func(ifaces ...string) Packet {
+		p := tcp4(8080)
+		p.Ifaces = ifaces
+		return p
+	}
+	check(t, ipt.Evaluate, []fwCase{
+		{name: "only external interface", p: on("lo", "eth0", "docker0"), action: "accept", rule: "INPUT policy ACCEPT", policy: true},
+		{name: "interfaces unknown", p: tcp4(8080), action: "accept", rule: "INPUT policy ACCEPT", policy: true},
+		{name: "second external interface", p: on("lo", "eth0", "wlan0"), action: "drop", rule: "! -i eth0 -j DROP"},
+	})
+
+	bridge, err := ParseIptables("*filter\n:INPUT ACCEPT [0:0]\n-A INPUT ! -i docker0 -j DROP\nCOMMIT\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, bridge.Evaluate, []fwCase{
+		{name: "not the bridge", p: on("lo", "eth0", "docker0"), action: "drop", rule: "! -i docker0 -j DROP"},
+	})
+}
+
+func TestNft(t *testing.T) {
+	n, err := ParseNft([]byte(fixture(t, "nft.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !n.HasInput() {
+		t.Fatal("HasInput = false")
+	}
+	web := tcp4(9090)
+	web.DstIP = net.ParseIP("192.168.1.10")
+	check(t, n.Evaluate, []fwCase{
+		{name: "ssh", p: tcp4(22), action: "accept", rule: "tcp dport 22 accept (handle 9) # ssh"},
+		{name: "named set", p: tcp4(443), action: "accept", rule: "tcp dport @web accept"},
+		{name: "reject", p: tcp4(8080), action: "reject", rule: "handle 12"},
+		{name: "source-restricted", p: tcp4(5432), action: "drop", rule: "chain inet filter input policy drop", policy: true, note: "only from 10.0.0.0/8"},
+		{name: "jump chain", p: web, action: "accept", rule: "inet filter app: ip daddr 192.168.1.10"},
+		{name: "anonymous set range", p: tcp4(3150), action: "accept", rule: "handle 16"},
+		{name: "udp range", p: Packet{Proto: "udp", Family: "ipv4", DstPort: 60001}, action: "accept", rule: "udp dport 60000-61000"},
+		{name: "earlier base chain", p: tcp4(6379), action: "drop", rule: "ip guard early"},
+		{name: "ip table skipped for v6", p: Packet{Proto: "tcp", Family: "ipv6", DstPort: 6379}, action: "drop", rule: "chain inet filter input policy drop", policy: true},
+		{name: "v6 source rule ignored", p: Packet{Proto: "tcp", Family: "ipv6", DstPort: 22}, action: "accept", rule: "handle 9"},
+	})
+	if v := n.Evaluate(tcp4(22)); v.Chain != "inet filter input" {
+		t.Fatalf("Chain = %q", v.Chain)
+	}
+}
+
+func TestNftNegatedInterface(t *testing.T) {
+	n, err := ParseNft([]byte(`{"nftables": [
+{"table": {"family": "inet", "name": "filter", "handle": 1}},
+{"chain": {"family": "inet", "table": "filter", "name": "input", "handle": 1, "type": "filter", "hook": "input", "prio": 0, "policy": "accept"}},
+{"rule": {"family": "inet", "table": "filter", "chain": "input", "handle": 2, "expr": [{"match": {"op": "!=", "left": {"meta": {"key": "iifname"}}, "right": "eth0"}}, {"drop": null}]}}
+]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	two := tcp4(8080)
+	two.Ifaces = []string{"lo", "eth0", "eth1"}
+	check(t, n.Evaluate, []fwCase{
+		{name: "only external interface", p: tcp4(8080), action: "accept", policy: true},
+		{name: "second external interface", p: two, action: "drop", rule: "handle 2"},
+	})
+}
+
+func TestUFW(t *testing.T) {
+	u, err := ParseUFW(fixture(t, "ufw.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Apps = ParseUFWApps(fixture(t, "ufw-apps.txt"))
+	if !u.Active || u.Default != "deny" {
+		t.Fatalf("status: active=%v default=%q", u.Active, u.Default)
+	}
+	web := tcp4(9090)
+	web.DstIP = net.ParseIP("192.168.1.10")
+	check(t, u.Evaluate, []fwCase{
+		{name: "ssh", p: tcp4(22), action: "accept", rule: "[1] 22/tcp"},
+		{name: "list", p: tcp4(443), action: "accept", rule: "[3] 80,443/tcp"},
+		{name: "source-restricted", p: tcp4(5432), action: "drop", rule: "default deny (incoming)", policy: true, note: "only from 10.0.0.0/8"},
+		{name: "reject", p: tcp4(8080), action: "reject", rule: "[5]"},
+		{name: "to address", p: web, action: "accept", rule: "[6]"},
+		{name: "wildcard to address", p: tcp4(9090), action: "drop", policy: true, note: "only to 192.168.1.10"},
+		{name: "range deny", p: tcp4(3005), action: "drop", rule: "[7] 3000:3010/tcp"},
+		{name: "udp not allowed", p: Packet{Proto: "udp", Family: "ipv4", DstPort: 22}, action: "drop", policy: true},
+		{name: "v6 rule", p: Packet{Proto: "tcp", Family: "ipv6", DstPort: 443}, action: "accept", rule: "[9]"},
+		{name: "app profile", p: Packet{Proto: "udp", Family: "ipv4", DstPort: 8443}, action: "accept", rule: "[10] Nginx Full"},
+	})
+}
+
+func TestUFWUnknownApps(t *testing.T) {
+	u, err := ParseUFW(fixture(t, "ufw.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, u.Evaluate, []fwCase{
+		{name: "listed port", p: tcp4(22), action: "accept", rule: "[1] 22/tcp"},
+		{name: "profile may cover it", p: tcp4(2222), action: "unknown", rule: "default deny (incoming)", policy: true, note: "application profile OpenSSH; its ports are unknown"},
+	})
+}
+
+func TestEvaluatePrefersActiveUFW(t *testing.T) {
+	d := Dumps{
+		"ufw":      fixture(t, "ufw.txt"),
+		"iptables": fixture(t, "iptables-save.txt"),
+	}
+	if v, ok := Evaluate(d, tcp4(8080)); !ok || v.Source != "ufw" {
+		t.Fatalf("got %+v ok=%v, want ufw", v, ok)
+	}
+	d["ufw"] = "Status: inactive\n"
+	if v, ok := Evaluate(d, tcp4(8080)); !ok || v.Source != "iptables" || v.Action != "reject" {
+		t.Fatalf("got %+v ok=%v, want iptables reject", v, ok)
+	}
+	d["nft"] = fixture(t, "nft.json")
+	if v, _ := Evaluate(d, tcp4(8080)); v.Source != "nftables" {
+		t.Fatalf("got %+v, want nftables", v)
+	}
+	if _, ok := Evaluate(Dumps{}, tcp4(22)); ok {
+		t.Fatal("ok with no rulesets")
+	}
+}
+
+func TestForwardsIptables(t *testing.T) {
+	d := Dumps{"iptables": fixture(t, "iptables-nat.txt")}
+
+	fs := Forwards(d, tcp4(8080))
+	if len(fs) != 1 || fs[0].Kind != "dnat" || fs[0].Target(8080) != "172.17.0.3:80" {
+		t.Fatalf("docker DNAT: %+v", fs)
+	}
+	if got := strings.Join(fs[0].Via, " > "); got != "PREROUTING > DOCKER" {
+		t.Fatalf("via = %q", got)
+	}
+
+	fs = Forwards(d, tcp4(30080))
+	if len(fs) != 2 || fs[0].Target(0) != "10.244.1.5:8080" || fs[1].Target(0) != "10.244.2.7:8080" {
+		t.Fatalf("kube endpoints: %+v", fs)
+	}
+	if fs[0].Comment != "default/webapp:http" || fs[1].Via[len(fs[1].Via)-1] != "KUBE-SEP-BBBB" {
+		t.Fatalf("kube forward details: %+v", fs[1])
+	}
+
+	fs = Forwards(d, tcp4(6380))
+	if len(fs) != 1 || fs[0].Only != "only to 127.0.0.1/32" {
+		t.Fatalf("destination-specific DNAT: %+v", fs)
+	}
+
+	fs = Forwards(d, tcp4(8888))
+	if len(fs) != 1 || fs[0].Kind != "redirect" || fs[0].ToIP != "" || fs[0].ToPort != 3128 {
+		t.Fatalf("redirect: %+v", fs)
+	}
+
+	if fs := Forwards(d, tcp4(80)); len(fs) != 0 {
+		t.Fatalf("cluster IP rules should not apply to the host: %+v", fs)
+	}
+	if fs := Forwards(d, Packet{Proto: "udp", Family: "ipv4", DstPort: 8080}); len(fs) != 0 {
+		t.Fatalf("udp: %+v", fs)
+	}
+}
+
+func TestForwardsNft(t *testing.T) {
+	d := Dumps{"nft": fixture(t, "nft-nat.json")}
+	fs := Forwards(d, tcp4(3000))
+	if len(fs) != 1 || fs[0].Target(3000) != "192.168.122.20:3000" || fs[0].Comment != "grafana" {
+		t.Fatalf("dnat: %+v", fs)
+	}
+	if got := strings.Join(fs[0].Via, " > "); got != "ip nat prerouting > forwards" {
+		t.Fatalf("via = %q", got)
+	}
+	fs = Forwards(d, tcp4(80))
+	if len(fs) != 1 || fs[0].Kind != "redirect" || fs[0].ToPort != 8080 {
+		t.Fatalf("redirect: %+v", fs)
+	}
+	if fs := Forwards(d, Packet{Proto: "tcp", Family: "ipv6", DstPort: 3000}); len(fs) != 0 {
+		t.Fatalf("ip table applied to ipv6: %+v", fs)
+	}
+}
+
+func TestSplitNATTarget(t *testing.T) {
+	for in, want := range map[string]string{
+		"172.17.0.3:80":           "172.17.0.3:80",
+		"172.17.0.3":              "172.17.0.3:0",
+		"[fd00::3]:8080":          "[fd00::3]:8080",
+		"10.0.0.1-10.0.0.4:80-90": "10.0.0.1:80",
+	} {
+		ip, port := splitNATTarget(in)
+		if got := net.JoinHostPort(ip, strconv.Itoa(port)); got != want {
+			t.Errorf("splitNATTarget(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
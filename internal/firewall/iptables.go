@@ -0,0 +1,302 @@
+package firewall
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// Iptables holds the filter and nat tables of an iptables-save dump.
+type Iptables struct {
+	tables map[string]map[string]*iptChain // table -> chain name -> chain
+}
+
+type iptChain struct {
+	policy string // ACCEPT|DROP for built-in chains, "-" for user chains
+	rules  []iptRule
+}
+
+type iptRule struct {
+	line string
+	args []string
+}
+
+// ParseIptables reads iptables-save (or ip6tables-save) output and keeps
+// its filter and nat tables.
+func ParseIptables(txt string) (*Iptables, error) {
+	t := &Iptables{tables: map[string]map[string]*iptChain{}}
+	table := ""
+	sc := bufio.NewScanner(strings.NewReader(txt))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || line == "COMMIT":
+		case strings.HasPrefix(line, "*"):
+			table = line[1:]
+			if table == "filter" || table == "nat" {
+				t.tables[table] = map[string]*iptChain{}
+			}
+		case t.tables[table] == nil:
+		case strings.HasPrefix(line, ":"):
+			f := strings.Fields(line[1:])
+			if len(f) < 2 {
+				return nil, fmt.Errorf("iptables: bad chain line %q", line)
+			}
+			t.tables[table][f[0]] = &iptChain{policy: f[1]}
+		case strings.HasPrefix(line, "-A "):
+			args := splitArgs(line)
+			if len(args) < 2 {
+				return nil, fmt.Errorf("iptables: bad rule %q", line)
+			}
+			c := t.tables[table][args[1]]
+			if c == nil {
+				c = &iptChain{policy: "-"}
+				t.tables[table][args[1]] = c
+			}
+			c.rules = append(c.rules, iptRule{line: line, args: args[2:]})
+		}
+	}
+	return t, sc.Err()
+}
+
+func (t *Iptables) chain(table, name string) *iptChain {
+	return t.tables[table][name]
+}
+
+// HasInput reports whether the INPUT chain filters anything.
+func (t *Iptables) HasInput() bool {
+	c := t.chain("filter", "INPUT")
+	return c != nil && (len(c.rules) > 0 || c.policy != "ACCEPT")
+}
+
+// Evaluate walks INPUT, following jumps into user chains.
+func (t *Iptables) Evaluate(p Packet) Verdict {
+	v := Verdict{Source: "iptables"}
+	if t.walk("INPUT", p, &v, 0) {
+		return v
+	}
+	policy := "ACCEPT"
+	if c := t.chain("filter", "INPUT"); c != nil && c.policy != "-" {
+		policy = c.policy
+	}
+	v.Action = strings.ToLower(policy)
+	v.Rule = "INPUT policy " + policy
+	v.Policy = true
+	return v
+}
+
+// walk returns true once a terminal verdict is set.
+func (t *Iptables) walk(chain string, p Packet, v *Verdict, depth int) bool {
+	c := t.chain("filter", chain)
+	if c == nil || depth > 32 {
+		return false
+	}
+	for _, r := range c.rules {
+		ok, target, note := r.match(p)
+		if note != "" && !passive(target) {
+			v.Notes = append(v.Notes, fmt.Sprintf("%s (%s)", r.line, note))
+		}
+		if !ok {
+			continue
+		}
+		switch target {
+		case "ACCEPT", "DROP", "REJECT":
+			v.Action = strings.ToLower(target)
+			v.Rule = r.line
+			return true
+		case "RETURN":
+			return false
+		default:
+			if passive(target) {
+				continue
+			}
+			if t.chain("filter", target) != nil && t.walk(target, p, v, depth+1) {
+				return true
+			}
+			if r.isGoto() {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// passive reports whether a rule with this target leaves the verdict to
+// later rules.
+func passive(target string) bool {
+	switch target {
+	case "", "LOG", "NFLOG", "MARK", "CONNMARK", "AUDIT", "TRACE":
+		return true
+	}
+	return false
+}
+
+func (r iptRule) isGoto() bool {
+	for _, a := range r.args {
+		if a == "-g" || a == "--goto" {
+			return true
+		}
+	}
+	return false
+}
+
+// match evaluates the rule's conditions against p. A non-empty note means
+// the rule was skipped because it only applies to some sources or
+// destinations, or depends on a match module portik does not model;
+// target is set either way.
+func (r iptRule) match(p Packet) (ok bool, target, note string) {
+	args := r.args
+	neg := false
+	cond := ""
+	restrict := func(c string) {
+		if cond != "" {
+			cond += ", "
+		}
+		cond += c
+	}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "!" {
+			neg = true
+			continue
+		}
+		val := ""
+		if i+1 < len(args) && !isOption(args[i+1]) {
+			val = args[i+1]
+		}
+		hit := true
+		consumed := val != ""
+		switch a {
+		case "-j", "--jump", "-g", "--goto":
+			target = val
+		case "-p", "--protocol":
+			hit = protoMatches(val, p.Proto)
+		case "-s", "--source":
+			if !anyAddr(val) {
+				if neg {
+					restrict("not from " + val)
+				} else {
+					restrict("only from " + val)
+				}
+				neg = false
+			}
+		case "-d", "--destination":
+			if !anyAddr(val) {
+				if p.DstIP == nil {
+					restrict("only to " + val)
+					break
+				}
+				hit = addrMatches(val, p.DstIP)
+			}
+		case "-i", "--in-interface":
+			// traffic from a remote host arrives on some external
+			// interface: "-i eth0" and "! -i docker0" both hold, and
+			// "! -i eth0" does when the host has another one
+			hit = externalIface(val)
+			if neg {
+				hit, neg = p.elsewhere([]string{val}), false
+			}
+		case "--dport", "--destination-port", "--dports", "--destination-ports":
+			hit = portInSpec(val, p.DstPort)
+		case "--sport", "--source-port", "--sports", "--source-ports":
+			hit = false // client ports are arbitrary
+		case "--ctstate", "--state":
+			hit = stateHasNew(val)
+		case "--dst-type":
+			hit = strings.Contains(val, "LOCAL")
+		case "--src-type":
+			hit = !strings.Contains(val, "LOCAL")
+		case "--syn":
+			hit = p.Proto == "tcp"
+			consumed = false
+		case "--icmp-type", "--icmpv6-type":
+			hit = false
+		case "-m", "--match":
+			// rate limits, recent lists, payload matches and the like
+			// depend on the client and its history, like -s does
+			if !iptModules[val] {
+				restrict("depends on -m " + val)
+			}
+		default:
+			// options of the modules above that portik does not
+			// model are assumed to match
+		}
+		if consumed {
+			i++
+		}
+		if neg {
+			hit = !hit
+			neg = false
+		}
+		if !hit {
+			return false, "", ""
+		}
+	}
+	if cond != "" {
+		return false, target, cond
+	}
+	return true, target, ""
+}
+
+// iptModules are the match modules whose options match models or that do
+// not narrow which new connections a rule applies to. statistic is left to
+// the NAT walk, which treats such rules as applying to some packets.
+var iptModules = map[string]bool{
+	"tcp": true, "udp": true, "icmp": true, "icmp6": true, "multiport": true,
+	"state": true, "conntrack": true, "addrtype": true, "comment": true,
+	"statistic": true,
+}
+
+func isOption(s string) bool {
+	return s == "!" || (strings.HasPrefix(s, "-") && len(s) > 1 && (s[1] < '0' || s[1] > '9'))
+}
+
+func protoMatches(spec, proto string) bool {
+	switch strings.ToLower(spec) {
+	case "all", "0", "":
+		return true
+	case "6":
+		return proto == "tcp"
+	case "17":
+		return proto == "udp"
+	}
+	return strings.EqualFold(spec, proto)
+}
+
+func stateHasNew(list string) bool {
+	for _, s := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(s), "NEW") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitArgs splits an iptables-save line on spaces, keeping double-quoted
+// values (comments) together.
+func splitArgs(line string) []string {
+	var out []string
+	var b strings.Builder
+	quoted, started := false, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case r == ' ' && !quoted:
+			if started {
+				out = append(out, b.String())
+				b.Reset()
+				started = false
+			}
+		default:
+			b.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		out = append(out, b.String())
+	}
+	return out
+}
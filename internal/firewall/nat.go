@@ -0,0 +1,243 @@
+package firewall
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Forward is a destination NAT rule that rewrites inbound traffic for a
+// port: DNAT to another address (Docker's DOCKER chain, kube-proxy service
+// endpoints) or REDIRECT to a local port.
+type Forward struct {
+	Kind    string   `json:"kind"`              // dnat|redirect
+	Source  string   `json:"source"`            // iptables|nftables
+	Via     []string `json:"via"`               // chains traversed, ending with the one holding Rule
+	Rule    string   `json:"rule"`              // the rewriting rule
+	Comment string   `json:"comment,omitempty"` // rule comment, e.g. kube-proxy's "default/web:http"
+	ToIP    string   `json:"to_ip,omitempty"`   // empty for redirect: a local port
+	ToPort  int      `json:"to_port,omitempty"` // 0: port unchanged
+	Only    string   `json:"only,omitempty"`    // set when the rule applies to some destinations only
+}
+
+// Target is where the forward sends traffic, as "ip:port" or ":port" for a
+// redirect.
+func (f Forward) Target(port int) string {
+	if f.ToPort != 0 {
+		port = f.ToPort
+	}
+	return net.JoinHostPort(f.ToIP, strconv.Itoa(port))
+}
+
+// Forwards lists the NAT rules that rewrite p on its way in (the
+// PREROUTING hook), from iptables and native nftables. Load-balanced rules
+// such as kube-proxy endpoints yield one Forward per endpoint.
+func Forwards(d Dumps, p Packet) []Forward {
+	var out []Forward
+	key := "iptables"
+	if p.Family == "ipv6" {
+		key = "ip6tables"
+	}
+	if txt := d[key]; txt != "" {
+		if t, err := ParseIptables(txt); err == nil {
+			t.forwards([]string{"PREROUTING"}, p, &out, 0)
+		}
+	}
+	if txt := d["nft"]; txt != "" {
+		if n, err := ParseNft([]byte(txt)); err == nil {
+			for _, c := range n.hookChains("prerouting", "nat", p.Family) {
+				n.forwards(c, []string{c.family + " " + c.table + " " + c.name}, p, &out, 0)
+			}
+		}
+	}
+	seen := map[string]bool{}
+	uniq := out[:0]
+	for _, f := range out {
+		k := f.Kind + f.Target(p.DstPort)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		uniq = append(uniq, f)
+	}
+	return uniq
+}
+
+// forwards collects the DNAT and REDIRECT rules p can reach from the last
+// chain of via. It returns true once a rule ends NAT traversal for every
+// packet; rules that hold for some packets only (random load balancing,
+// destination-specific rules) are collected and traversal continues.
+func (t *Iptables) forwards(via []string, p Packet, out *[]Forward, depth int) bool {
+	name := via[len(via)-1]
+	c := t.chain("nat", name)
+	if c == nil || depth > 32 {
+		return false
+	}
+	for _, r := range c.rules {
+		ok, target, note := r.match(p)
+		if !ok && !(strings.HasPrefix(note, "only to ") && (target == "DNAT" || target == "REDIRECT")) {
+			continue
+		}
+		partial := !ok || r.probabilistic()
+		switch target {
+		case "DNAT", "REDIRECT":
+			f := r.forward(target)
+			f.Via = append([]string(nil), via...)
+			if !ok {
+				f.Only = note
+			}
+			*out = append(*out, f)
+			if !partial {
+				return true
+			}
+		case "ACCEPT":
+			if !partial {
+				return true
+			}
+		case "RETURN":
+			if !partial {
+				return false
+			}
+		default:
+			if t.chain("nat", target) == nil {
+				continue // MARK, MASQUERADE, LOG, ...
+			}
+			if t.forwards(append(via[:len(via):len(via)], target), p, out, depth+1) && !partial {
+				return true
+			}
+			if r.isGoto() && !partial {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// probabilistic reports whether the rule matches only some packets
+// (-m statistic, as kube-proxy uses to spread traffic over endpoints).
+func (r iptRule) probabilistic() bool {
+	for _, a := range r.args {
+		if a == "statistic" || a == "--probability" || a == "--every" {
+			return true
+		}
+	}
+	return false
+}
+
+// forward reads the rewrite from a DNAT or REDIRECT rule.
+func (r iptRule) forward(target string) Forward {
+	f := Forward{Kind: strings.ToLower(target), Source: "iptables", Rule: r.line}
+	for i := 0; i+1 < len(r.args); i++ {
+		switch r.args[i] {
+		case "--comment":
+			f.Comment = r.args[i+1]
+		case "--to-destination":
+			f.ToIP, f.ToPort = splitNATTarget(r.args[i+1])
+		case "--to-ports":
+			f.ToPort, _ = strconv.Atoi(firstOfRange(r.args[i+1]))
+		}
+	}
+	return f
+}
+
+// splitNATTarget parses "172.17.0.3:80", "172.17.0.3", "[fd00::3]:80" or
+// ranges such as "10.0.0.1-10.0.0.4:80-90", keeping the first of each.
+func splitNATTarget(s string) (string, int) {
+	host, port := s, ""
+	if h, p, err := net.SplitHostPort(s); err == nil {
+		host, port = h, p
+	} else if i := strings.LastIndexByte(s, ':'); i >= 0 && strings.Count(s, ":") == 1 {
+		host, port = s[:i], s[i+1:]
+	}
+	host = strings.Trim(firstOfRange(host), "[]")
+	n, _ := strconv.Atoi(firstOfRange(port))
+	return host, n
+}
+
+func firstOfRange(s string) string {
+	if i := strings.IndexByte(s, '-'); i > 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// forwards is the nftables counterpart of Iptables.forwards.
+func (n *Nft) forwards(c *nftChain, via []string, p Packet, out *[]Forward, depth int) bool {
+	if depth > 32 {
+		return false
+	}
+rules:
+	for _, r := range c.rules {
+		cond := ""
+		for _, e := range r.expr {
+			for k, val := range e {
+				switch k {
+				case "match":
+					m, _ := val.(map[string]any)
+					hit, why := n.evalMatch(c, m, p)
+					if why != "" {
+						cond = why
+						continue
+					}
+					if !hit {
+						continue rules
+					}
+				case "dnat", "redirect":
+					if cond != "" && !strings.HasPrefix(cond, "only to ") {
+						continue rules
+					}
+					f := nftForward(k, val)
+					f.Via = append([]string(nil), via...)
+					f.Rule = n.describe(c, r)
+					f.Comment = r.comment
+					f.Only = cond
+					*out = append(*out, f)
+					if cond == "" {
+						return true
+					}
+					continue rules
+				case "accept", "drop":
+					if cond == "" {
+						return true
+					}
+					continue rules
+				case "return":
+					if cond == "" {
+						return false
+					}
+					continue rules
+				case "jump", "goto":
+					if cond != "" {
+						continue rules
+					}
+					m, _ := val.(map[string]any)
+					t, _ := m["target"].(string)
+					child := n.chains[c.family+"/"+c.table+"/"+t]
+					if child == nil {
+						continue rules
+					}
+					if n.forwards(child, append(via[:len(via):len(via)], t), p, out, depth+1) {
+						return true
+					}
+					if k == "goto" {
+						return false
+					}
+					continue rules
+				}
+			}
+		}
+	}
+	return false
+}
+
+func nftForward(kind string, val any) Forward {
+	f := Forward{Kind: kind, Source: "nftables"}
+	m, _ := val.(map[string]any)
+	if addr, ok := m["addr"].(string); ok {
+		f.ToIP = addr
+	}
+	if port, ok := m["port"].(float64); ok {
+		f.ToPort = int(port)
+	}
+	return f
+}
@@ -0,0 +1,493 @@
+package firewall
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Nft is a ruleset from "nft -j list ruleset".
+type Nft struct {
+	chains map[string]*nftChain // by family/table/name
+	sets   map[string][]any     // named sets, by family/table/name
+}
+
+type nftChain struct {
+	family, table, name string
+	hook, typ, policy   string
+	prio                int
+	rules               []nftRule
+}
+
+type nftRule struct {
+	handle  int
+	comment string
+	expr    []map[string]any
+}
+
+// ParseNft reads "nft -j list ruleset" output.
+func ParseNft(b []byte) (*Nft, error) {
+	var doc struct {
+		Nftables []map[string]json.RawMessage `json:"nftables"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("nft: %w", err)
+	}
+	n := &Nft{chains: map[string]*nftChain{}, sets: map[string][]any{}}
+	for _, obj := range doc.Nftables {
+		switch {
+		case obj["chain"] != nil:
+			var c struct {
+				Family, Table, Name, Hook, Type, Policy string
+				Prio                                    int
+			}
+			if err := json.Unmarshal(obj["chain"], &c); err != nil {
+				return nil, fmt.Errorf("nft chain: %w", err)
+			}
+			key := c.Family + "/" + c.Table + "/" + c.Name
+			ch := n.chains[key]
+			if ch == nil {
+				ch = &nftChain{}
+				n.chains[key] = ch
+			}
+			ch.family, ch.table, ch.name = c.Family, c.Table, c.Name
+			ch.hook, ch.typ, ch.policy, ch.prio = c.Hook, c.Type, c.Policy, c.Prio
+		case obj["rule"] != nil:
+			var r struct {
+				Family, Table, Chain, Comment string
+				Handle                        int
+				Expr                          []map[string]any
+			}
+			if err := json.Unmarshal(obj["rule"], &r); err != nil {
+				return nil, fmt.Errorf("nft rule: %w", err)
+			}
+			key := r.Family + "/" + r.Table + "/" + r.Chain
+			ch := n.chains[key]
+			if ch == nil {
+				ch = &nftChain{family: r.Family, table: r.Table, name: r.Chain}
+				n.chains[key] = ch
+			}
+			ch.rules = append(ch.rules, nftRule{handle: r.Handle, comment: r.Comment, expr: r.Expr})
+		case obj["set"] != nil:
+			var s struct {
+				Family, Table, Name string
+				Elem                []any
+			}
+			if err := json.Unmarshal(obj["set"], &s); err != nil {
+				return nil, fmt.Errorf("nft set: %w", err)
+			}
+			n.sets[s.Family+"/"+s.Table+"/"+s.Name] = s.Elem
+		}
+	}
+	return n, nil
+}
+
+// inputChains are the filter base chains on the input hook that see a
+// packet of family, in priority order.
+func (n *Nft) inputChains(family string) []*nftChain {
+	return n.hookChains("input", "filter", family)
+}
+
+// hookChains are the base chains of a type on a hook that see a packet of
+// family, in priority order.
+func (n *Nft) hookChains(hook, typ, family string) []*nftChain {
+	var out []*nftChain
+	for _, c := range n.chains {
+		if c.hook != hook || (c.typ != "" && c.typ != typ) {
+			continue
+		}
+		if c.family == "inet" || (c.family == "ip" && family == "ipv4") || (c.family == "ip6" && family == "ipv6") {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].prio != out[j].prio {
+			return out[i].prio < out[j].prio
+		}
+		return out[i].family+out[i].table < out[j].family+out[j].table
+	})
+	return out
+}
+
+// HasInput reports whether any base chain filters input.
+func (n *Nft) HasInput() bool {
+	for _, c := range append(n.inputChains("ipv4"), n.inputChains("ipv6")...) {
+		if len(c.rules) > 0 || c.policy == "drop" {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate runs the packet through every input base chain in priority
+// order. A drop or reject anywhere is final; an accept only ends its chain.
+func (n *Nft) Evaluate(p Packet) Verdict {
+	v := Verdict{Source: "nftables", Action: "accept", Rule: "no input chain", Policy: true}
+	for _, c := range n.inputChains(p.Family) {
+		action, rule := n.walk(c, p, &v, 0)
+		if action == "" {
+			action = c.policy
+			if action == "" {
+				action = "accept"
+			}
+			rule = fmt.Sprintf("chain %s %s %s policy %s", c.family, c.table, c.name, action)
+			v.Policy = true
+		} else {
+			v.Policy = false
+		}
+		v.Action, v.Rule = action, rule
+		v.Chain = c.family + " " + c.table + " " + c.name
+		if action != "accept" {
+			return v
+		}
+	}
+	return v
+}
+
+// walk returns the verdict of chain c ("" when it falls through).
+func (n *Nft) walk(c *nftChain, p Packet, v *Verdict, depth int) (string, string) {
+	if depth > 32 {
+		return "", ""
+	}
+	for _, r := range c.rules {
+		action, target, note := n.evalRule(c, r, p)
+		if note != "" {
+			v.Notes = append(v.Notes, fmt.Sprintf("%s (%s)", n.describe(c, r), note))
+		}
+		switch action {
+		case "accept", "drop", "reject":
+			return action, n.describe(c, r)
+		case "return":
+			return "", ""
+		case "jump", "goto":
+			child := n.chains[c.family+"/"+c.table+"/"+target]
+			if child != nil {
+				if a, rule := n.walk(child, p, v, depth+1); a != "" {
+					return a, rule
+				}
+			}
+			if action == "goto" {
+				return "", ""
+			}
+		}
+	}
+	return "", ""
+}
+
+// evalRule returns the rule's verdict for p ("" if it does not match or has
+// no verdict), the jump target and a note for source-only matches.
+func (n *Nft) evalRule(c *nftChain, r nftRule, p Packet) (action, target, note string) {
+	cond := ""
+	for _, e := range r.expr {
+		for k, val := range e {
+			switch k {
+			case "match":
+				m, _ := val.(map[string]any)
+				hit, why := n.evalMatch(c, m, p)
+				if why != "" {
+					cond = why
+					continue
+				}
+				if !hit {
+					return "", "", ""
+				}
+			case "vmap":
+				m, _ := val.(map[string]any)
+				a, ok := n.evalVmap(c, m, p)
+				if !ok {
+					continue
+				}
+				if cond != "" {
+					return "", "", cond
+				}
+				return a, "", ""
+			case "accept", "drop", "reject", "return":
+				if cond != "" {
+					return "", "", cond
+				}
+				return k, "", ""
+			case "jump", "goto":
+				if cond != "" {
+					return "", "", cond
+				}
+				m, _ := val.(map[string]any)
+				t, _ := m["target"].(string)
+				return k, t, ""
+			}
+		}
+	}
+	return "", "", ""
+}
+
+// evalMatch returns whether a match expression holds. why is set when it
+// depends on the source address, which the packet leaves open.
+func (n *Nft) evalMatch(c *nftChain, m map[string]any, p Packet) (hit bool, why string) {
+	op, _ := m["op"].(string)
+	neg := op == "!="
+	left, _ := m["left"].(map[string]any)
+	right := n.resolve(c, m["right"])
+
+	result := func(b bool) (bool, string) {
+		if neg {
+			return !b, ""
+		}
+		return b, ""
+	}
+	switch {
+	case left["payload"] != nil:
+		pl, _ := left["payload"].(map[string]any)
+		proto, _ := pl["protocol"].(string)
+		field, _ := pl["field"].(string)
+		switch {
+		case field == "dport" && (proto == p.Proto || proto == "th"):
+			return result(valueHas(right, func(x any) bool { return numIn(x, p.DstPort) }))
+		case field == "dport" || field == "sport":
+			return false, "" // other protocol, or arbitrary client port
+		case field == "saddr":
+			if !familyMatches(proto, p.Family) {
+				return false, ""
+			}
+			if neg {
+				return false, "not from " + describeValue(right)
+			}
+			return false, "only from " + describeValue(right)
+		case field == "daddr":
+			if !familyMatches(proto, p.Family) {
+				return false, ""
+			}
+			if p.DstIP == nil {
+				return false, "only to " + describeValue(right)
+			}
+			return result(valueHas(right, func(x any) bool { return addrIn(x, p.DstIP) }))
+		}
+		if proto != "" && proto != p.Proto && !familyMatches(proto, p.Family) {
+			return false, ""
+		}
+		return true, ""
+	case left["meta"] != nil:
+		mt, _ := left["meta"].(map[string]any)
+		switch key, _ := mt["key"].(string); key {
+		case "l4proto", "protocol":
+			return result(valueHas(right, func(x any) bool { s, _ := x.(string); return s == p.Proto }))
+		case "nfproto":
+			return result(valueHas(right, func(x any) bool { s, _ := x.(string); return familyMatches(s, p.Family) }))
+		case "iifname", "iif":
+			if neg {
+				// as with iptables: "! -i docker0" holds, "! -i eth0"
+				// only on a host with another external interface
+				var names []string
+				valueHas(right, func(x any) bool {
+					if s, ok := x.(string); ok {
+						names = append(names, s)
+					}
+					return false
+				})
+				return p.elsewhere(names), ""
+			}
+			return valueHas(right, func(x any) bool { s, _ := x.(string); return externalIface(s) }), ""
+		}
+		return true, ""
+	case left["ct"] != nil:
+		ct, _ := left["ct"].(map[string]any)
+		if key, _ := ct["key"].(string); key == "state" {
+			return result(valueHas(right, func(x any) bool { s, _ := x.(string); return s == "new" }))
+		}
+		return true, ""
+	}
+	// fib, limits, marks, ...: assumed to match
+	return true, ""
+}
+
+func (n *Nft) evalVmap(c *nftChain, m map[string]any, p Packet) (string, bool) {
+	key, _ := m["key"].(map[string]any)
+	data := n.resolve(c, m["data"])
+	set, _ := data.(map[string]any)
+	elems, _ := set["set"].([]any)
+	if elems == nil {
+		elems, _ = data.([]any)
+	}
+	for _, el := range elems {
+		pair, _ := el.([]any)
+		if len(pair) != 2 {
+			continue
+		}
+		hit, why := n.evalMatch(c, map[string]any{"op": "==", "left": key, "right": pair[0]}, p)
+		if !hit || why != "" {
+			continue
+		}
+		verdict, _ := pair[1].(map[string]any)
+		for k := range verdict {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// resolve replaces a "@name" reference with the named set's elements.
+func (n *Nft) resolve(c *nftChain, v any) any {
+	if s, ok := v.(string); ok && strings.HasPrefix(s, "@") {
+		if elems, ok := n.sets[c.family+"/"+c.table+"/"+s[1:]]; ok {
+			return map[string]any{"set": elems}
+		}
+	}
+	return v
+}
+
+// valueHas reports whether a value, set or list contains an element for
+// which f holds.
+func valueHas(v any, f func(any) bool) bool {
+	switch x := v.(type) {
+	case []any:
+		for _, e := range x {
+			if valueHas(e, f) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		if s, ok := x["set"]; ok {
+			return valueHas(s, f)
+		}
+		if e, ok := x["elem"]; ok {
+			return valueHas(e, f)
+		}
+	}
+	return f(v)
+}
+
+func numIn(v any, port int) bool {
+	switch x := v.(type) {
+	case float64:
+		return int(x) == port
+	case map[string]any:
+		if r, ok := x["range"].([]any); ok && len(r) == 2 {
+			lo, _ := r[0].(float64)
+			hi, _ := r[1].(float64)
+			return port >= int(lo) && port <= int(hi)
+		}
+	}
+	return false
+}
+
+func addrIn(v any, ip net.IP) bool {
+	switch x := v.(type) {
+	case string:
+		return addrMatches(x, ip)
+	case map[string]any:
+		if pf, ok := x["prefix"].(map[string]any); ok {
+			addr, _ := pf["addr"].(string)
+			l, _ := pf["len"].(float64)
+			return addrMatches(fmt.Sprintf("%s/%d", addr, int(l)), ip)
+		}
+		if r, ok := x["range"].([]any); ok && len(r) == 2 {
+			lo, _ := r[0].(string)
+			hi, _ := r[1].(string)
+			a, b := net.ParseIP(lo), net.ParseIP(hi)
+			return a != nil && b != nil && bytesCompare(ip, a) >= 0 && bytesCompare(ip, b) <= 0
+		}
+	}
+	return false
+}
+
+func bytesCompare(a, b net.IP) int {
+	a, b = a.To16(), b.To16()
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func familyMatches(proto, family string) bool {
+	switch proto {
+	case "ip", "ipv4":
+		return family == "ipv4"
+	case "ip6", "ipv6":
+		return family == "ipv6"
+	}
+	return false
+}
+
+// describe renders a rule roughly the way "nft list ruleset" prints it.
+func (n *Nft) describe(c *nftChain, r nftRule) string {
+	var parts []string
+	for _, e := range r.expr {
+		for k, val := range e {
+			switch k {
+			case "match":
+				m, _ := val.(map[string]any)
+				op, _ := m["op"].(string)
+				s := describeLeft(m["left"])
+				if op == "!=" {
+					s += " !="
+				}
+				parts = append(parts, s+" "+describeValue(m["right"]))
+			case "vmap":
+				m, _ := val.(map[string]any)
+				parts = append(parts, describeLeft(m["key"])+" vmap { ... }")
+			case "accept", "drop", "reject", "return":
+				parts = append(parts, k)
+			case "jump", "goto":
+				m, _ := val.(map[string]any)
+				parts = append(parts, fmt.Sprintf("%s %v", k, m["target"]))
+			}
+		}
+	}
+	s := fmt.Sprintf("%s %s %s: %s (handle %d)", c.family, c.table, c.name, strings.Join(parts, " "), r.handle)
+	if r.comment != "" {
+		s += " # " + r.comment
+	}
+	return s
+}
+
+func describeLeft(v any) string {
+	m, _ := v.(map[string]any)
+	switch {
+	case m["payload"] != nil:
+		pl, _ := m["payload"].(map[string]any)
+		return fmt.Sprintf("%v %v", pl["protocol"], pl["field"])
+	case m["meta"] != nil:
+		mt, _ := m["meta"].(map[string]any)
+		key, _ := mt["key"].(string)
+		if key == "iifname" || key == "oifname" || key == "l4proto" {
+			return key
+		}
+		return "meta " + key
+	case m["ct"] != nil:
+		ct, _ := m["ct"].(map[string]any)
+		return fmt.Sprintf("ct %v", ct["key"])
+	}
+	return "expr"
+}
+
+func describeValue(v any) string {
+	switch x := v.(type) {
+	case float64:
+		return fmt.Sprint(int(x))
+	case string:
+		return x
+	case []any:
+		var s []string
+		for _, e := range x {
+			s = append(s, describeValue(e))
+		}
+		return "{ " + strings.Join(s, ", ") + " }"
+	case map[string]any:
+		if s, ok := x["set"]; ok {
+			return describeValue(s)
+		}
+		if r, ok := x["range"].([]any); ok && len(r) == 2 {
+			return describeValue(r[0]) + "-" + describeValue(r[1])
+		}
+		if pf, ok := x["prefix"].(map[string]any); ok {
+			return fmt.Sprintf("%v/%v", pf["addr"], describeValue(pf["len"]))
+		}
+	}
+	return fmt.Sprint(v)
+}
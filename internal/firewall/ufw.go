@@ -0,0 +1,231 @@
+package firewall
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// UFW is the output of "ufw status verbose" and/or "ufw status numbered".
+type UFW struct {
+	Active  bool
+	Default string            // incoming policy: allow|deny|reject (deny when not shown)
+	Apps    map[string]string // application profile name -> ports ("22/tcp", "80,443/tcp|53/udp"); nil: unknown
+	rules   []ufwRule
+}
+
+type ufwRule struct {
+	to     string // "22/tcp", "80,443/tcp", "192.168.1.5 443/tcp", "Anywhere", "OpenSSH"
+	action string // ALLOW|DENY|REJECT|LIMIT
+	dir    string // IN|OUT|FWD
+	from   string
+	v6     bool
+	line   string
+}
+
+var (
+	ufwRuleRE    = regexp.MustCompile(`^\[\s*(\d+)\]\s+(.*)$`)
+	ufwDefaultRE = regexp.MustCompile(`(?i)^Default:\s*(\w+)\s*\(incoming\)`)
+	ufwColumns   = regexp.MustCompile(`\s{2,}`)
+)
+
+// ParseUFW reads "ufw status" output; numbered rules are needed to name
+// them, verbose output adds the default policy. Both may be concatenated.
+func ParseUFW(txt string) (*UFW, error) {
+	u := &UFW{Default: "deny"}
+	sc := bufio.NewScanner(strings.NewReader(txt))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(strings.ToLower(line), "status:"):
+			u.Active = strings.Contains(strings.ToLower(line), "active") && !strings.Contains(strings.ToLower(line), "inactive")
+		case ufwDefaultRE.MatchString(line):
+			u.Default = strings.ToLower(ufwDefaultRE.FindStringSubmatch(line)[1])
+		default:
+			m := ufwRuleRE.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			cols := ufwColumns.Split(strings.TrimSpace(m[2]), -1)
+			if len(cols) < 3 {
+				return nil, fmt.Errorf("ufw: bad rule %q", line)
+			}
+			r := ufwRule{to: cols[0], from: cols[2], line: "[" + strings.TrimSpace(m[1]) + "] " + strings.Join(cols, "  ")}
+			act := strings.Fields(cols[1])
+			r.action = strings.ToUpper(act[0])
+			r.dir = "IN"
+			if len(act) > 1 {
+				r.dir = strings.ToUpper(act[1])
+			}
+			if strings.HasSuffix(r.to, "(v6)") {
+				r.v6 = true
+				r.to = strings.TrimSpace(strings.TrimSuffix(r.to, "(v6)"))
+			}
+			r.from = strings.TrimSpace(strings.TrimSuffix(strings.SplitN(r.from, "#", 2)[0], "(v6)"))
+			u.rules = append(u.rules, r)
+		}
+	}
+	return u, sc.Err()
+}
+
+// ParseUFWApps reads ufw application profiles (the INI files in
+// /etc/ufw/applications.d) into profile name -> ports.
+func ParseUFWApps(txt string) map[string]string {
+	apps := map[string]string{}
+	name := ""
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name = strings.TrimSpace(line[1 : len(line)-1])
+		case name != "":
+			if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(strings.ToLower(k)) == "ports" {
+				apps[name] = strings.TrimSpace(v)
+			}
+		}
+	}
+	return apps
+}
+
+// Evaluate applies the first matching incoming rule, else the default.
+// When a rule names an application profile whose ports are not known, the
+// default is not a definite verdict: the action is "unknown".
+func (u *UFW) Evaluate(p Packet) Verdict {
+	v := Verdict{Source: "ufw"}
+	unresolved := false
+	for _, r := range u.rules {
+		if r.dir != "IN" || r.v6 != (p.Family == "ipv6") {
+			continue
+		}
+		ok, unknown, note := r.match(p, u.Apps)
+		unresolved = unresolved || unknown
+		if note != "" {
+			v.Notes = append(v.Notes, fmt.Sprintf("%s (%s)", r.line, note))
+		}
+		if !ok {
+			continue
+		}
+		switch r.action {
+		case "ALLOW", "LIMIT":
+			v.Action = "accept"
+		case "REJECT":
+			v.Action = "reject"
+		default:
+			v.Action = "drop"
+		}
+		v.Rule = r.line
+		return v
+	}
+	switch u.Default {
+	case "allow":
+		v.Action = "accept"
+	case "reject":
+		v.Action = "reject"
+	default:
+		v.Action = "drop"
+	}
+	v.Rule = "default " + u.Default + " (incoming)"
+	v.Policy = true
+	if unresolved {
+		v.Action = "unknown"
+	}
+	return v
+}
+
+// match reports whether r applies to p; a note explains a rule that might
+// apply but is not taken to. unknown is set when r names an application
+// profile whose ports apps does not list, so it may or may not apply.
+func (r ufwRule) match(p Packet, apps map[string]string) (ok, unknown bool, note string) {
+	to := r.to
+	if i := strings.Index(to, " on "); i >= 0 {
+		to = to[:i] // "22/tcp on eth0": assume the traffic uses that interface
+	}
+	addr, ports := "", to
+	if f := strings.Fields(to); len(f) > 1 && isAddr(f[0]) {
+		addr, ports = f[0], strings.TrimSpace(strings.TrimPrefix(to, f[0]))
+	} else if isAddr(to) {
+		addr, ports = to, ""
+	}
+	app := ""
+	if pp, _, _ := strings.Cut(ports, "/"); ports != "" && !isPortSpec(pp) {
+		app = ports // application profile such as "OpenSSH" or "Apache Full"
+		spec, known := apps[app]
+		if !known {
+			switch {
+			case addr != "" && !anyAddr(addr) && p.DstIP != nil && !addrMatches(addr, p.DstIP):
+				return false, false, ""
+			case !anyAddr(r.from):
+				return false, false, "only from " + r.from
+			}
+			return false, true, "application profile " + app + "; its ports are unknown"
+		}
+		ports = spec
+	}
+	if addr != "" && !anyAddr(addr) {
+		if p.DstIP == nil {
+			if appPortsMatch(ports, p) {
+				return false, false, "only to " + addr
+			}
+			return false, false, ""
+		}
+		if !addrMatches(addr, p.DstIP) {
+			return false, false, ""
+		}
+	}
+	if !appPortsMatch(ports, p) {
+		return false, false, ""
+	}
+	if !anyAddr(r.from) {
+		return false, false, "only from " + r.from
+	}
+	return true, false, ""
+}
+
+// appPortsMatch checks ports listed as in an application profile: "ports[/proto]"
+// entries separated by "|".
+func appPortsMatch(spec string, p Packet) bool {
+	for _, s := range strings.Split(spec, "|") {
+		if portsMatch(strings.TrimSpace(s), p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAddr(s string) bool {
+	if anyAddr(s) || net.ParseIP(s) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+// isPortSpec reports whether s looks like "22", "80,443" or "3000:3010".
+func isPortSpec(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != ',' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// portsMatch checks a ufw "ports[/proto]" column.
+func portsMatch(spec string, p Packet) bool {
+	if spec == "" {
+		return true
+	}
+	ports, proto, hasProto := strings.Cut(spec, "/")
+	if hasProto && proto != p.Proto {
+		return false
+	}
+	if !isPortSpec(ports) {
+		return false
+	}
+	return portInSpec(ports, p.DstPort)
+}
@@ -0,0 +1,69 @@
+package forwarder
+
+import (
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// Closer says how to close a tunnel without collateral damage.
+type Closer struct {
+	// Argv removes just this forward from a shared process (ssh -O cancel).
+	// Nil means terminating the forwarder's pid closes the tunnel cleanly.
+	Argv []string
+	// Refuse is set when the pid must not be killed because something else
+	// manages it; it says what to do instead.
+	Refuse string
+}
+
+// HowToClose picks the least disruptive way to close t. cmdline is the
+// forwarder's command line, used to reach an ssh ControlMaster.
+func HowToClose(t model.Tunnel, cmdline string) Closer {
+	switch t.Tool {
+	case "ssh":
+		if !t.Shared {
+			return Closer{}
+		}
+		if t.Spec != "" && t.Via != "" && strings.HasPrefix(t.Spec, "-L ") {
+			argv := []string{"ssh"}
+			argv = append(argv, controlArgs(strings.Fields(cmdline))...)
+			return Closer{Argv: append(argv, "-O", "cancel", "-L", strings.TrimPrefix(t.Spec, "-L "), t.Via)}
+		}
+		return Closer{Refuse: "This ssh process is a ControlMaster shared by other sessions. Cancel the forward with 'ssh -O cancel -L <spec> <host>', or end every session with 'ssh -O exit " + dashIfEmpty(t.Via) + "'."}
+	case "docker-proxy":
+		return Closer{Refuse: "docker-proxy is managed by dockerd. Stop or re-create the container that publishes this port instead (docker ps --filter publish=<port>)."}
+	case "gvproxy":
+		return Closer{Refuse: "gvproxy carries all networking of the podman machine. Stop the container publishing the port, or the VM with 'podman machine stop'."}
+	case "rootlessport":
+		return Closer{Refuse: "rootlessport is managed by podman. Stop the container publishing this port instead (podman ps)."}
+	case "vscode":
+		return Closer{Refuse: "This port is forwarded by VS Code; killing the process closes the editor. Stop forwarding it in VS Code's Ports view."}
+	}
+	return Closer{}
+}
+
+// controlArgs keeps the options that locate the ControlMaster's socket.
+func controlArgs(args []string) []string {
+	var out []string
+	for i := 1; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case (a == "-S" || a == "-F" || a == "-p" || a == "-l") && i+1 < len(args):
+			out = append(out, a, args[i+1])
+			i++
+		case a == "-o" && i+1 < len(args):
+			if k, _, _ := strings.Cut(strings.ToLower(args[i+1]), "="); k == "controlpath" {
+				out = append(out, a, args[i+1])
+			}
+			i++
+		}
+	}
+	return out
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "<host>"
+	}
+	return s
+}
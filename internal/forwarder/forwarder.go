@@ -0,0 +1,326 @@
+// Package forwarder recognises userspace port forwarders and tunnels (ssh -L,
+// kubectl port-forward, socat, docker-proxy, gvproxy, VS Code port
+// forwarding) holding a port, and works out where they forward to.
+package forwarder
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// Detect recognises the forwarder behind a listener from its process name
+// and command line.
+func Detect(l model.Listener) (model.Tunnel, bool) {
+	args := strings.Fields(l.Cmdline)
+	name := strings.ToLower(l.ProcName)
+	if len(args) > 0 {
+		if argv0 := strings.ToLower(filepath.Base(args[0])); argv0 != "" {
+			name = strings.TrimSuffix(argv0, ":") // "ssh:" in a ControlMaster's title
+		}
+	}
+	lower := strings.ToLower(l.Cmdline + " " + l.ProcName)
+	switch {
+	case name == "ssh" || name == "autossh":
+		return parseSSH(args, l.LocalPort), true
+	case name == "kubectl" || name == "oc":
+		return parseKubectl(args, l.LocalPort)
+	case name == "socat":
+		return parseSocat(args, l.LocalPort)
+	case strings.Contains(lower, "docker-proxy"):
+		return parseDockerProxy(args), true
+	case strings.Contains(lower, "gvproxy"):
+		return parseGvproxy(args, l.LocalPort), true
+	case strings.Contains(lower, "rootlessport"):
+		return model.Tunnel{Tool: "rootlessport", Target: "rootless podman container", Via: "podman"}, true
+	case strings.Contains(lower, "vscode-server"), strings.Contains(lower, "code-tunnel"), strings.Contains(lower, "code helper"):
+		return model.Tunnel{Tool: "vscode", Target: "port forwarded by VS Code", Via: "VS Code remote"}, true
+	}
+	return model.Tunnel{}, false
+}
+
+// Peer returns the upstream connection the forwarder holds, "ip:port": an
+// established connection of its pid that is not one of its clients.
+func Peer(l model.Listener, conns []model.Conn) string {
+	if l.PID <= 0 {
+		return ""
+	}
+	for _, c := range conns {
+		if c.PID != l.PID || c.LocalPort == l.LocalPort || c.RemotePort == 0 {
+			continue
+		}
+		if s := strings.ToUpper(c.State); s != "ESTAB" && s != "ESTABLISHED" {
+			continue
+		}
+		return net.JoinHostPort(c.RemoteIP, strconv.Itoa(c.RemotePort))
+	}
+	return ""
+}
+
+// sshArgOpts are the ssh options that take a value.
+const sshArgOpts = "BbcDEeFIiJLlmOopQRSWw"
+
+// parseSSH finds the -L or -D forward listening on port. A ControlMaster
+// renames itself "ssh: <control path> [mux]", hiding its forwards.
+func parseSSH(args []string, port int) model.Tunnel {
+	t := model.Tunnel{Tool: "ssh"}
+	if len(args) > 0 && strings.HasSuffix(args[0], ":") {
+		t.Shared = true
+		if len(args) > 1 {
+			t.Via = controlHost(args[1])
+		}
+		return t
+	}
+	var locals, dynamic []string
+	dest := ""
+	for i := 1; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") || len(a) < 2 {
+			if dest == "" {
+				dest = a
+				continue
+			}
+			break // remote command
+		}
+		for j := 1; j < len(a); j++ {
+			c := a[j]
+			if strings.IndexByte(sshArgOpts, c) < 0 {
+				if c == 'M' {
+					t.Shared = true
+				}
+				continue
+			}
+			val := a[j+1:]
+			if val == "" && i+1 < len(args) {
+				i++
+				val = args[i]
+			}
+			switch c {
+			case 'L':
+				locals = append(locals, val)
+			case 'D':
+				dynamic = append(dynamic, val)
+			case 'o':
+				k, v, _ := strings.Cut(strings.ToLower(val), "=")
+				if k == "controlmaster" && v != "no" {
+					t.Shared = true
+				}
+			}
+			break
+		}
+	}
+	t.Via = dest
+	for _, spec := range locals {
+		f := splitForward(spec)
+		switch {
+		case len(f) == 3 && f[0] == strconv.Itoa(port):
+			t.Target = net.JoinHostPort(f[1], f[2])
+		case len(f) == 4 && f[1] == strconv.Itoa(port):
+			t.Target = net.JoinHostPort(f[2], f[3])
+		case len(f) == 2 && f[0] == strconv.Itoa(port) && strings.HasPrefix(f[1], "/"):
+			t.Target = f[1] // remote unix socket
+		case len(f) == 3 && f[1] == strconv.Itoa(port) && strings.HasPrefix(f[2], "/"):
+			t.Target = f[2]
+		default:
+			continue
+		}
+		t.Spec = "-L " + spec
+		return t
+	}
+	for _, spec := range dynamic {
+		f := splitForward(spec)
+		if f[len(f)-1] == strconv.Itoa(port) {
+			t.Target = "SOCKS proxy (dynamic forwarding)"
+			t.Spec = "-D " + spec
+			return t
+		}
+	}
+	return t // forwards from ~/.ssh/config (LocalForward) are not on the command line
+}
+
+// controlHost guesses the server from a control socket path such as
+// ~/.ssh/cm-alice@bastion:22.
+func controlHost(path string) string {
+	base := filepath.Base(path)
+	if i := strings.LastIndexByte(base, '@'); i >= 0 {
+		base = base[i+1:]
+	}
+	if h, _, err := net.SplitHostPort(base); err == nil {
+		return h
+	}
+	return base
+}
+
+// splitForward splits an ssh forward spec on ':' (or '/'), keeping
+// bracketed IPv6 addresses whole.
+func splitForward(spec string) []string {
+	sep := byte(':')
+	if !strings.Contains(spec, ":") {
+		sep = '/'
+	}
+	var out []string
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(spec); i++ {
+		c := spec[i]
+		switch {
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == sep && depth == 0:
+			out = append(out, b.String())
+			b.Reset()
+			continue
+		}
+		if c != '[' && c != ']' {
+			b.WriteByte(c)
+		}
+	}
+	return append(out, b.String())
+}
+
+// parseKubectl reads "kubectl port-forward [-n ns] TYPE/NAME [LOCAL:]REMOTE...".
+func parseKubectl(args []string, port int) (model.Tunnel, bool) {
+	t := model.Tunnel{Tool: "kubectl"}
+	forwarding := false
+	ns, ctx := "", ""
+	var pos []string
+	for i := 1; i < len(args); i++ {
+		a := args[i]
+		if a == "port-forward" {
+			forwarding = true
+			continue
+		}
+		if strings.HasPrefix(a, "-") {
+			key, val, hasVal := strings.Cut(a, "=")
+			switch key {
+			case "-n", "--namespace", "--context", "--address", "--kubeconfig", "--cluster", "--user", "-s", "--server", "--pod-running-timeout":
+				if !hasVal && i+1 < len(args) {
+					i++
+					val = args[i]
+				}
+			}
+			switch key {
+			case "-n", "--namespace":
+				ns = val
+			case "--context":
+				ctx = val
+			}
+			continue
+		}
+		pos = append(pos, a)
+	}
+	if !forwarding {
+		return t, false
+	}
+	if len(pos) < 2 {
+		return t, true
+	}
+	res := pos[0]
+	if !strings.Contains(res, "/") {
+		res = "pod/" + res
+	}
+	for _, spec := range pos[1:] {
+		local, remote, ok := strings.Cut(spec, ":")
+		if !ok {
+			remote = local
+		}
+		if local == strconv.Itoa(port) || (local == "" && len(pos) == 2) {
+			t.Target = res + ":" + remote
+			t.Spec = "port-forward " + pos[0] + " " + spec
+			break
+		}
+	}
+	var via []string
+	if ctx != "" {
+		via = append(via, "context "+ctx)
+	}
+	if ns != "" {
+		via = append(via, "namespace "+ns)
+	}
+	t.Via = strings.Join(via, ", ")
+	return t, true
+}
+
+// parseSocat reads "socat TCP-LISTEN:8080,fork TCP:10.0.0.5:80": the
+// listening address and the one it connects to.
+func parseSocat(args []string, port int) (model.Tunnel, bool) {
+	t := model.Tunnel{Tool: "socat"}
+	if len(args) < 2 {
+		return t, false
+	}
+	var listen, upstream string
+	for _, a := range args[1:] {
+		if strings.HasPrefix(a, "-") && a != "-" {
+			continue
+		}
+		kind, _, _ := strings.Cut(strings.ToUpper(a), ":")
+		if strings.Contains(kind, "LISTEN") || strings.Contains(kind, "RECVFROM") {
+			listen = a
+		} else if upstream == "" {
+			upstream = a
+		}
+	}
+	if listen == "" {
+		return t, false
+	}
+	if _, rest, ok := strings.Cut(listen, ":"); ok {
+		lp, _, _ := strings.Cut(rest, ",")
+		if lp != strconv.Itoa(port) {
+			return t, false
+		}
+	}
+	t.Spec = listen + " " + upstream
+	kind, rest, _ := strings.Cut(upstream, ":")
+	rest, _, _ = strings.Cut(rest, ",")
+	switch k := strings.ToUpper(kind); {
+	case strings.HasPrefix(k, "TCP"), strings.HasPrefix(k, "UDP"), strings.HasPrefix(k, "OPENSSL"), k == "SSL", strings.HasPrefix(k, "SCTP"):
+		t.Target = rest
+	case strings.HasPrefix(k, "UNIX"), k == "ABSTRACT-CONNECT":
+		t.Target = rest
+	case k == "EXEC", k == "SYSTEM":
+		t.Target = "command " + rest
+	default:
+		t.Target = upstream
+	}
+	return t, true
+}
+
+// parseDockerProxy reads "docker-proxy -proto tcp -host-ip 0.0.0.0
+// -host-port 8080 -container-ip 172.17.0.2 -container-port 80".
+func parseDockerProxy(args []string) model.Tunnel {
+	t := model.Tunnel{Tool: "docker-proxy", Via: "dockerd"}
+	ip, port := flagValue(args, "container-ip"), flagValue(args, "container-port")
+	if ip != "" && port != "" {
+		t.Target = "container " + net.JoinHostPort(ip, port)
+	}
+	return t
+}
+
+// parseGvproxy handles podman machine's (and CRC's) network proxy, which
+// forwards published ports into the VM.
+func parseGvproxy(args []string, port int) model.Tunnel {
+	t := model.Tunnel{Tool: "gvproxy", Target: "podman machine VM", Via: "podman machine"}
+	if flagValue(args, "ssh-port") == strconv.Itoa(port) {
+		t.Target = "sshd in the podman machine VM"
+	}
+	return t
+}
+
+// flagValue finds "-name v", "--name v" or "-name=v".
+func flagValue(args []string, name string) string {
+	for i, a := range args {
+		a = strings.TrimLeft(a, "-")
+		if a == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if v, ok := strings.CutPrefix(a, name+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package forwarder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		name    string
+		proc    string
+		cmdline string
+		port    int
+		want    model.Tunnel
+	}{
+		{
+			name: "ssh -L", proc: "ssh", port: 5432,
+			cmdline: "ssh -fN -L 8080:web:80 -L 5432:db.internal:5432 alice@bastion",
+			want:    model.Tunnel{Tool: "ssh", Target: "db.internal:5432", Via: "alice@bastion", Spec: "-L 5432:db.internal:5432"},
+		},
+		{
+			name: "ssh combined flags and bind address", proc: "ssh", port: 6379,
+			cmdline: "/usr/bin/ssh -p 2222 -fNL127.0.0.1:6379:[fd00::7]:6379 bastion",
+			want:    model.Tunnel{Tool: "ssh", Target: "[fd00::7]:6379", Via: "bastion", Spec: "-L 127.0.0.1:6379:[fd00::7]:6379"},
+		},
+		{
+			name: "ssh remote socket", proc: "ssh", port: 2375,
+			cmdline: "ssh -N -L 2375:/var/run/docker.sock build-host",
+			want:    model.Tunnel{Tool: "ssh", Target: "/var/run/docker.sock", Via: "build-host", Spec: "-L 2375:/var/run/docker.sock"},
+		},
+		{
+			name: "ssh dynamic", proc: "ssh", port: 1080,
+			cmdline: "ssh -D 1080 -N jump",
+			want:    model.Tunnel{Tool: "ssh", Target: "SOCKS proxy (dynamic forwarding)", Via: "jump", Spec: "-D 1080"},
+		},
+		{
+			name: "ssh forward from config", proc: "ssh", port: 5432,
+			cmdline: "ssh -N db-tunnel",
+			want:    model.Tunnel{Tool: "ssh", Via: "db-tunnel"},
+		},
+		{
+			name: "ssh control master", proc: "ssh", port: 5432,
+			cmdline: "ssh: /home/alice/.ssh/cm-alice@bastion:22 [mux]",
+			want:    model.Tunnel{Tool: "ssh", Via: "bastion", Shared: true},
+		},
+		{
+			name: "kubectl service", proc: "kubectl", port: 8080,
+			cmdline: "kubectl --context prod port-forward -n web svc/api 8080:80 9090:9090",
+			want:    model.Tunnel{Tool: "kubectl", Target: "svc/api:80", Via: "context prod, namespace web", Spec: "port-forward svc/api 8080:80"},
+		},
+		{
+			name: "kubectl bare pod", proc: "kubectl", port: 5000,
+			cmdline: "kubectl port-forward --namespace=ml trainer-7f9c 5000",
+			want:    model.Tunnel{Tool: "kubectl", Target: "pod/trainer-7f9c:5000", Via: "namespace ml", Spec: "port-forward trainer-7f9c 5000"},
+		},
+		{
+			name: "socat", proc: "socat", port: 8080,
+			cmdline: "socat -d TCP-LISTEN:8080,fork,reuseaddr TCP:10.0.0.5:80,nodelay",
+			want:    model.Tunnel{Tool: "socat", Target: "10.0.0.5:80", Spec: "TCP-LISTEN:8080,fork,reuseaddr TCP:10.0.0.5:80,nodelay"},
+		},
+		{
+			name: "docker-proxy", proc: "docker-proxy", port: 8080,
+			cmdline: "/usr/bin/docker-proxy -proto tcp -host-ip 0.0.0.0 -host-port 8080 -container-ip 172.17.0.2 -container-port 80",
+			want:    model.Tunnel{Tool: "docker-proxy", Target: "container 172.17.0.2:80", Via: "dockerd"},
+		},
+		{
+			name: "gvproxy ssh", proc: "gvproxy", port: 50110,
+			cmdline: "/opt/podman/bin/gvproxy -mtu 1500 -ssh-port 50110 -listen-vfkit unixgram:///tmp/vfkit.sock",
+			want:    model.Tunnel{Tool: "gvproxy", Target: "sshd in the podman machine VM", Via: "podman machine"},
+		},
+	}
+	for _, c := range cases {
+		got, ok := Detect(model.Listener{ProcName: c.proc, Cmdline: c.cmdline, LocalPort: c.port})
+		if !ok || got != c.want {
+			t.Errorf("%s: got %+v (ok=%v)\n want %+v", c.name, got, ok, c.want)
+		}
+	}
+
+	for _, l := range []model.Listener{
+		{ProcName: "postgres", Cmdline: "postgres -D /var/lib/postgresql", LocalPort: 5432},
+		{ProcName: "kubectl", Cmdline: "kubectl proxy --port 8001", LocalPort: 8001},
+		{ProcName: "socat", Cmdline: "socat TCP-LISTEN:9000,fork TCP:db:5432", LocalPort: 8080},
+	} {
+		if got, ok := Detect(l); ok {
+			t.Errorf("%q detected as %+v", l.Cmdline, got)
+		}
+	}
+}
+
+func TestPeer(t *testing.T) {
+	l := model.Listener{PID: 42, LocalPort: 5432}
+	conns := []model.Conn{
+		{PID: 42, LocalPort: 5432, RemoteIP: "127.0.0.1", RemotePort: 51000, State: "ESTAB"}, // a client
+		{PID: 42, LocalPort: 40312, RemoteIP: "10.0.0.5", RemotePort: 22, State: "SYN-SENT"},
+		{PID: 7, LocalPort: 40313, RemoteIP: "10.0.0.9", RemotePort: 22, State: "ESTAB"},
+		{PID: 42, LocalPort: 40314, RemoteIP: "10.0.0.5", RemotePort: 22, State: "ESTAB"},
+	}
+	if got := Peer(l, conns); got != "10.0.0.5:22" {
+		t.Fatalf("Peer = %q", got)
+	}
+}
+
+func TestHowToClose(t *testing.T) {
+	plain := model.Tunnel{Tool: "ssh", Target: "db:5432", Via: "bastion", Spec: "-L 5432:db:5432"}
+	if c := HowToClose(plain, ""); c.Argv != nil || c.Refuse != "" {
+		t.Fatalf("plain ssh should just be terminated: %+v", c)
+	}
+
+	shared := plain
+	shared.Shared = true
+	c := HowToClose(shared, "ssh -M -S /tmp/cm -fN -L 5432:db:5432 bastion")
+	if got := strings.Join(c.Argv, " "); got != "ssh -S /tmp/cm -O cancel -L 5432:db:5432 bastion" {
+		t.Fatalf("cancel argv = %q", got)
+	}
+
+	if c := HowToClose(model.Tunnel{Tool: "ssh", Via: "bastion", Shared: true}, ""); !strings.Contains(c.Refuse, "ssh -O exit bastion") {
+		t.Fatalf("unknown spec on a master: %+v", c)
+	}
+	for _, tool := range []string{"docker-proxy", "gvproxy", "rootlessport", "vscode"} {
+		if c := HowToClose(model.Tunnel{Tool: tool}, ""); c.Refuse == "" {
+			t.Errorf("%s should not be killed", tool)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package graph
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"net"
-	"os/exec"
 	"sort"
 	"strings"
 
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
 	"github.com/pratik-anurag/portik/internal/proc"
 	"github.com/pratik-anurag/portik/internal/sockets"
 )
@@ -15,6 +16,12 @@ import (
 type Options struct {
 	Ports     []int
 	LocalOnly bool
+	Unix      bool   // also add Unix stream socket dependencies (ignored with Ports)
+	Netns     string // namespace label for port and socket nodes; empty for the host
+
+	// Where data comes from; nil means the live system.
+	Sockets   sockets.Source
+	Processes proc.Source
 }
 
 type listenerRec struct {
@@ -48,12 +55,20 @@ func Build(proto string, opt Options) (Graph, []Dependency, []string, error) {
 		portsFilter[p] = true
 	}
 
-	listeners, err := sockets.ListListeners(proto)
+	socks, procSrc := opt.Sockets, opt.Processes
+	if socks == nil {
+		socks = sockets.Live
+	}
+	if procSrc == nil {
+		procSrc = proc.Live
+	}
+
+	listeners, err := socks.ListListeners(proto)
 	if err != nil {
 		return Graph{}, nil, nil, err
 	}
 	for i := range listeners {
-		proc.Enrich(&listeners[i])
+		proc.EnrichFrom(procSrc, &listeners[i])
 	}
 
 	listenerRecs := make([]listenerRec, 0, len(listeners))
@@ -72,7 +87,7 @@ func Build(proto string, opt Options) (Graph, []Dependency, []string, error) {
 		})
 	}
 
-	conns, err := sockets.ListConnections(proto)
+	conns, err := socks.ListConnections(proto)
 	if err != nil {
 		return Graph{}, nil, nil, err
 	}
@@ -123,12 +138,12 @@ func Build(proto string, opt Options) (Graph, []Dependency, []string, error) {
 		name := strings.TrimSpace(nameHint)
 		cmd := strings.TrimSpace(cmdHint)
 		if name == "" || cmd == "" {
-			psName, psCmd := psProcess(pid)
+			info, _ := procSrc.Lookup(pid)
 			if name == "" {
-				name = psName
+				name = info.Name
 			}
 			if cmd == "" {
-				cmd = psCmd
+				cmd = info.Cmdline
 			}
 		}
 		info := procInfo{pid: pid, name: name, cmdline: truncateCmdline(cmd, 120)}
@@ -143,7 +158,7 @@ func Build(proto string, opt Options) (Graph, []Dependency, []string, error) {
 		}
 		pi := cacheProc(l.PID, l.ProcName, l.Cmdline)
 		pNode := processNode(pi)
-		portNode := portNode(proto, l.LocalIP, l.Port)
+		portNode := portNode(proto, l.LocalIP, l.Port, opt.Netns)
 		addNode(pNode)
 		addNode(portNode)
 		addEdge(Edge{From: pNode.ID, To: portNode.ID, Type: EdgeListensOn})
@@ -190,7 +205,7 @@ func Build(proto string, opt Options) (Graph, []Dependency, []string, error) {
 
 		clientNode := processNode(clientInfo)
 		serverNode := processNode(serverInfo)
-		portNode := portNode(proto, match.LocalIP, match.Port)
+		portNode := portNode(proto, match.LocalIP, match.Port, opt.Netns)
 
 		addNode(clientNode)
 		addNode(serverNode)
@@ -206,6 +221,36 @@ func Build(proto string, opt Options) (Graph, []Dependency, []string, error) {
 		}
 	}
 
+	if opt.Unix && len(portsFilter) == 0 {
+		usocks, err := socks.ListUnix()
+		switch {
+		case errors.Is(err, errors.ErrUnsupported):
+		case err != nil:
+			addWarn(fmt.Sprintf("unix sockets unavailable: %v", err))
+		case !hasUnixPeers(usocks):
+			addWarn("unix socket peers are not reported by this backend (try --backend netlink)")
+		}
+		for _, p := range unixPairs(usocks) {
+			if p.client.PID <= 0 || p.server.PID <= 0 {
+				addWarn(fmt.Sprintf("connection pid missing for %s", p.server.Path))
+				continue
+			}
+			if p.client.PID == p.server.PID {
+				continue
+			}
+			clientNode := processNode(cacheProc(p.client.PID, p.client.ProcName, ""))
+			serverNode := processNode(cacheProc(p.server.PID, p.server.ProcName, ""))
+			sockNode := unixNode(p.server.Path, opt.Netns)
+
+			addNode(clientNode)
+			addNode(serverNode)
+			addNode(sockNode)
+			addEdge(Edge{From: serverNode.ID, To: sockNode.ID, Type: EdgeListensOn})
+			recordDependency(depsMap, clientNode, serverNode, sockNode, true)
+			addEdge(Edge{From: clientNode.ID, To: sockNode.ID, Type: EdgeConnectsTo, Established: 1})
+		}
+	}
+
 	graph := Graph{
 		Nodes: sortedNodes(nodes),
 		Edges: sortedEdges(edges),
@@ -260,6 +305,42 @@ func matchListener(listeners []listenerRec, remoteIP string, remotePort int) (*l
 	return nil, false
 }
 
+type unixPair struct {
+	client model.UnixSocket
+	server model.UnixSocket
+}
+
+// unixPairs matches connected stream sockets by peer inode. The accepted
+// (server) end carries the listener's path and the client end is unnamed;
+// pairs where both or neither end is named have no clear direction.
+func unixPairs(socks []model.UnixSocket) []unixPair {
+	byInode := make(map[uint64]model.UnixSocket, len(socks))
+	for _, s := range socks {
+		byInode[s.Inode] = s
+	}
+	var out []unixPair
+	for _, s := range socks {
+		if s.Type != "stream" || s.State != "ESTAB" || s.Path == "" || s.PeerInode == 0 {
+			continue
+		}
+		peer, ok := byInode[s.PeerInode]
+		if !ok || peer.Path != "" {
+			continue
+		}
+		out = append(out, unixPair{client: peer, server: s})
+	}
+	return out
+}
+
+func hasUnixPeers(socks []model.UnixSocket) bool {
+	for _, s := range socks {
+		if s.PeerInode != 0 {
+			return true
+		}
+	}
+	return len(socks) == 0
+}
+
 func normalizeState(state string) string {
 	state = strings.ToUpper(strings.TrimSpace(state))
 	switch state {
@@ -296,18 +377,81 @@ func processNode(p procInfo) Node {
 	}
 }
 
-func portNode(proto, ip string, port int) Node {
+func portNode(proto, ip string, port int, netns string) Node {
 	ip = normalizeIP(ip)
 	if ip == "" {
 		ip = "*"
 	}
 	return Node{
-		ID:       fmt.Sprintf("port:%s:%s:%d", proto, ip, port),
+		ID:       nsID(netns) + fmt.Sprintf("port:%s:%s:%d", proto, ip, port),
 		Type:     NodePort,
 		Protocol: proto,
 		LocalIP:  ip,
 		Port:     port,
+		Netns:    netns,
+	}
+}
+
+func unixNode(path, netns string) Node {
+	return Node{
+		ID:       nsID(netns) + "unix:" + path,
+		Type:     NodeSocket,
+		Protocol: "unix",
+		Path:     path,
+		Netns:    netns,
+	}
+}
+
+// nsID prefixes node IDs so the same port in two namespaces stays two nodes.
+// Process nodes are not prefixed: pids are global on the host.
+func nsID(netns string) string {
+	if netns == "" {
+		return ""
 	}
+	return "netns:" + netns + ":"
+}
+
+// Merge combines graphs built in different network namespaces. Nodes are
+// matched by ID and edge counters are summed.
+func Merge(gs []Graph, deps [][]Dependency) (Graph, []Dependency) {
+	nodes := map[string]Node{}
+	edges := map[string]Edge{}
+	for _, g := range gs {
+		for _, n := range g.Nodes {
+			if _, ok := nodes[n.ID]; !ok {
+				nodes[n.ID] = n
+			}
+		}
+		for _, e := range g.Edges {
+			key := e.From + "|" + e.To + "|" + string(e.Type)
+			if existing, ok := edges[key]; ok {
+				existing.Established += e.Established
+				existing.TimeWait += e.TimeWait
+				edges[key] = existing
+				continue
+			}
+			edges[key] = e
+		}
+	}
+
+	depsMap := map[depKey]*Dependency{}
+	for _, ds := range deps {
+		for _, d := range ds {
+			key := depKey{clientPID: d.Client.PID, serverPID: d.Server.PID, portID: d.Port.ID}
+			if existing := depsMap[key]; existing != nil {
+				existing.Established += d.Established
+				existing.TimeWait += d.TimeWait
+				continue
+			}
+			depsMap[key] = &d
+		}
+	}
+	out := make([]Dependency, 0, len(depsMap))
+	for _, d := range depsMap {
+		out = append(out, *d)
+	}
+	sortDependencies(out)
+	return Graph{Nodes: sortedNodes(nodes), Edges: sortedEdges(edges)}, out
 }
 
 func sortedNodes(in map[string]Node) []Node {
@@ -407,24 +551,9 @@ func localIPSet() map[string]bool {
 		"127.0.0.1": true,
 		"::1":       true,
 	}
-	ifaces, err := net.Interfaces()
-	if err != nil {
-		return out
-	}
-	for _, iface := range ifaces {
-		addrs, err := iface.Addrs()
-		if err != nil {
-			continue
-		}
-		for _, addr := range addrs {
-			ip := addr.String()
-			if i := strings.Index(ip, "/"); i >= 0 {
-				ip = ip[:i]
-			}
-			ip = normalizeIP(ip)
-			if ip != "" {
-				out[ip] = true
-			}
+	for _, ip := range platform.LocalIPs() {
+		if ip = normalizeIP(ip); ip != "" {
+			out[ip] = true
 		}
 	}
 	return out
@@ -447,32 +576,3 @@ func fmtIP(ip string) string {
 	}
 	return ip
 }
-
-func psProcess(pid int32) (string, string) {
-	name := psField(pid, "comm=")
-	cmd := psField(pid, "command=")
-	return strings.TrimSpace(name), strings.TrimSpace(cmd)
-}
-
-func psField(pid int32, format string) string {
-	cmd := exec.Command("ps", "-p", itoa32(pid), "-o", format)
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	_ = cmd.Run()
-	return strings.TrimSpace(buf.String())
-}
-
-func itoa32(n int32) string {
-	if n == 0 {
-		return "0"
-	}
-	x := int(n)
-	var b [32]byte
-	i := len(b)
-	for x > 0 {
-		i--
-		b[i] = byte('0' + x%10)
-		x /= 10
-	}
-	return string(b[i:])
-}
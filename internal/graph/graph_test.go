@@ -1,6 +1,10 @@
 package graph
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
 
 func TestMatchListener(t *testing.T) {
 	listeners := []listenerRec{
@@ -158,3 +162,45 @@ func convertDepsToSlice(deps map[depKey]*Dependency) []Dependency {
 	}
 	return result
 }
+
+func TestUnixPairs(t *testing.T) {
+	path := "/run/postgresql/.s.PGSQL.5432"
+	socks := []model.UnixSocket{
+		{Path: path, Type: "stream", State: "LISTEN", Inode: 1, PID: 812},
+		{Path: path, Type: "stream", State: "ESTAB", Inode: 2, PeerInode: 3, PID: 4410},
+		{Type: "stream", State: "ESTAB", Inode: 3, PeerInode: 2, PID: 4409, ProcName: "psql"},
+		// socketpair: neither end named
+		{Type: "stream", State: "ESTAB", Inode: 5, PeerInode: 6, PID: 7},
+		{Type: "stream", State: "ESTAB", Inode: 6, PeerInode: 5, PID: 7},
+	}
+	pairs := unixPairs(socks)
+	if len(pairs) != 1 {
+		t.Fatalf("expected 1 pair, got %+v", pairs)
+	}
+	if pairs[0].client.PID != 4409 || pairs[0].server.PID != 4410 || pairs[0].server.Path != path {
+		t.Fatalf("unexpected pair: %+v", pairs[0])
+	}
+}
+
+func TestMergeKeepsNamespacesApart(t *testing.T) {
+	client := Node{ID: "proc:10", Type: NodeProcess, PID: 10, ProcName: "curl"}
+	server := Node{ID: "proc:20", Type: NodeProcess, PID: 20, ProcName: "nginx"}
+	hostPort := portNode("tcp", "127.0.0.1", 80, "host")
+	nsPort := portNode("tcp", "127.0.0.1", 80, "blue")
+	if hostPort.ID == nsPort.ID {
+		t.Fatalf("expected distinct port nodes per namespace, got %s", hostPort.ID)
+	}
+
+	g1 := Graph{Nodes: []Node{client, server, hostPort}, Edges: []Edge{{From: client.ID, To: hostPort.ID, Type: EdgeConnectsTo, Established: 1}}}
+	g2 := Graph{Nodes: []Node{client, server, nsPort}, Edges: []Edge{{From: client.ID, To: nsPort.ID, Type: EdgeConnectsTo, Established: 2}}}
+	d1 := []Dependency{{Client: client, Server: server, Port: hostPort, Established: 1}}
+	d2 := []Dependency{{Client: client, Server: server, Port: nsPort, Established: 2}, {Client: client, Server: server, Port: nsPort, Established: 1}}
+
+	g, deps := Merge([]Graph{g1, g2}, [][]Dependency{d1, d2})
+	if len(g.Nodes) != 4 || len(g.Edges) != 2 {
+		t.Fatalf("unexpected merged graph: %d nodes, %d edges", len(g.Nodes), len(g.Edges))
+	}
+	if len(deps) != 2 || deps[0].Port.Netns != "blue" || deps[0].Established != 3 {
+		t.Fatalf("unexpected merged dependencies: %+v", deps)
+	}
+}
@@ -5,6 +5,7 @@ type NodeType string
 const (
 	NodeProcess NodeType = "process"
 	NodePort    NodeType = "port"
+	NodeSocket  NodeType = "socket" // unix socket path
 )
 
 type EdgeType string
@@ -23,6 +24,8 @@ type Node struct {
 	Protocol string   `json:"protocol,omitempty"`
 	LocalIP  string   `json:"local_ip,omitempty"`
 	Port     int      `json:"port,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	Netns    string   `json:"netns,omitempty"`
 }
 
 type Edge struct {
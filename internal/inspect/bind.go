@@ -0,0 +1,287 @@
+package inspect
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+)
+
+// BindRequest describes the bind an application would do (explain --bind).
+type BindRequest struct {
+	Addr      string // IP literal; brackets optional for IPv6
+	ReuseAddr bool   // SO_REUSEADDR
+	ReusePort bool   // SO_REUSEPORT
+	V6Only    bool   // IPV6_V6ONLY (IPv6 addresses only)
+}
+
+// ParseBindAddr normalizes an --bind value to an IP literal.
+func ParseBindAddr(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return "0.0.0.0", nil
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return "", fmt.Errorf("invalid bind address %q (want an IP such as 127.0.0.1, 0.0.0.0 or ::)", s)
+	}
+	return ip.String(), nil
+}
+
+// TryBind binds (and for tcp, listens on) addr:port with the requested
+// socket options, closes the socket at once and reports the outcome.
+func TryBind(port int, proto string, req BindRequest) model.BindCheck {
+	bc := model.BindCheck{
+		Addr:      req.Addr,
+		Port:      port,
+		Proto:     proto,
+		ReuseAddr: req.ReuseAddr,
+		ReusePort: req.ReusePort,
+		V6Only:    req.V6Only,
+	}
+	ip := net.ParseIP(req.Addr)
+	if ip == nil {
+		bc.Error = "invalid bind address"
+		return bc
+	}
+	if ip.To4() != nil {
+		bc.V6Only = false
+	}
+	bc.Errno, bc.Error = tryBind(ip, port, proto, bc)
+	bc.OK = bc.Error == ""
+	return bc
+}
+
+func bindHostPort(bc model.BindCheck) string {
+	return net.JoinHostPort(bc.Addr, fmt.Sprint(bc.Port))
+}
+
+// diagnoseBind explains a bind result against the listeners already on the
+// port, following Linux semantics: a LISTEN socket blocks every
+// overlapping address unless both sides set SO_REUSEPORT under the same
+// effective uid; SO_REUSEADDR only helps against TIME_WAIT. A permission
+// failure points at priv's diagnostic when the report has one.
+func diagnoseBind(bc model.BindCheck, listeners []model.Listener, me string, priv *model.PrivilegeCheck) []model.Diagnostic {
+	target := bindHostPort(bc)
+	if bc.OK {
+		d := model.Diagnostic{
+			Kind:     "bind",
+			Severity: "info",
+			Summary:  fmt.Sprintf("Bind to %s succeeded", target),
+			Details:  fmt.Sprintf("A %s socket with %s could bind just now.", bc.Proto, bindOpts(bc)),
+		}
+		if len(listeners) > 0 {
+			d.Details += fmt.Sprintf(" It can coexist with the current listener(s): %s.", listenerList(listeners))
+		}
+		return []model.Diagnostic{d}
+	}
+
+	reason := bc.Errno
+	if reason == "" {
+		reason = bc.Error
+	}
+	out := []model.Diagnostic{{
+		Kind:     "bind",
+		Severity: "error",
+		Summary:  fmt.Sprintf("Bind to %s failed: %s", target, reason),
+		Details:  fmt.Sprintf("%s (tried %s with %s)", errnoMeaning(bc), bc.Proto, bindOpts(bc)),
+	}}
+
+	switch bc.Errno {
+	case "EADDRINUSE":
+		out = append(out, addrInUse(bc, listeners, me)...)
+	case "EACCES", "EPERM":
+		switch {
+		case priv != nil && priv.Privileged && !priv.Allowed:
+			out[0].Details += fmt.Sprintf(" Port %d is privileged; see the permission hint under Port & process.", bc.Port)
+		case priv == nil && bc.Port < 1024:
+			out = append(out, model.Diagnostic{
+				Kind:     "bind-conflict",
+				Severity: "error",
+				Summary:  fmt.Sprintf("Port %d is privileged", bc.Port),
+				Details:  "Binding ports below 1024 needs root or CAP_NET_BIND_SERVICE.",
+				Action:   "Grant the capability (sudo setcap cap_net_bind_service=+ep <binary>) or use a port >= 1024.",
+			})
+		}
+	case "EADDRNOTAVAIL":
+		d := model.Diagnostic{
+			Kind:     "bind-conflict",
+			Severity: "error",
+			Summary:  fmt.Sprintf("%s is not a local address", bc.Addr),
+			Details:  "No interface on this host has that address assigned.",
+			Action:   "Bind an address this host owns, or 0.0.0.0 / :: for all of them.",
+		}
+		if ips := platform.LocalIPs(); len(ips) > 0 {
+			d.Details += " Local addresses: " + strings.Join(ips, ", ") + "."
+		}
+		out = append(out, d)
+	case "EAFNOSUPPORT":
+		out = append(out, model.Diagnostic{
+			Kind:     "bind-conflict",
+			Severity: "error",
+			Summary:  "IPv6 is not available on this host",
+			Details:  "The kernel refused an IPv6 socket; IPv6 may be disabled.",
+			Action:   "Bind an IPv4 address instead.",
+		})
+	}
+	return out
+}
+
+func addrInUse(bc model.BindCheck, listeners []model.Listener, me string) []model.Diagnostic {
+	var out []model.Diagnostic
+	for _, l := range listeners {
+		rel := bindOverlap(bc, l)
+		if rel == "" {
+			continue
+		}
+		laddr := displayIP(l.LocalIP)
+		d := model.Diagnostic{
+			Kind:     "bind-conflict",
+			Severity: "error",
+			Summary:  fmt.Sprintf("%s listener blocks %s bind", laddr, displayIP(bc.Addr)),
+			Action:   fmt.Sprintf("Stop the listener (portik kill %d) or bind another address or port.", bc.Port),
+		}
+		owner := fmt.Sprintf("pid %d (%s)", l.PID, dash(l.ProcName))
+		switch rel {
+		case "same":
+			d.Summary = fmt.Sprintf("%s is already bound", bindHostPort(bc))
+			d.Details = fmt.Sprintf("%s listens on exactly this address.", owner)
+		case "listener-wildcard":
+			d.Details = fmt.Sprintf("%s listens on the wildcard address, which covers every local address on port %d.", owner, bc.Port)
+		case "request-wildcard":
+			d.Details = fmt.Sprintf("A wildcard bind covers every local address, including %s already held by %s.", laddr, owner)
+		case "listener-dualstack":
+			d.Details = fmt.Sprintf("%s listens on [::] without IPV6_V6ONLY, so it also owns IPv4 port %d (dual-stack).", owner, bc.Port)
+			d.Action = "Have that service set IPV6_V6ONLY (e.g. bind [::] with ipv6only=on), or bind only IPv6 yourself."
+		case "request-dualstack":
+			d.Details = fmt.Sprintf("Without IPV6_V6ONLY a [::] socket is dual-stack and also claims IPv4 port %d, held by %s.", bc.Port, owner)
+			d.Action = "Retry with --v6only to bind IPv6 only."
+		}
+		if bc.ReusePort {
+			if l.User != "" && me != "" && l.User != me {
+				d.Summary = "SO_REUSEPORT group owned by another uid"
+				d.Details = fmt.Sprintf("%s runs as %s; the kernel only lets sockets of the same effective uid share a port with SO_REUSEPORT.", owner, l.User)
+				d.Action = fmt.Sprintf("Run as %s, or stop the existing listener.", l.User)
+			} else {
+				d.Details += " SO_REUSEPORT shares a port only when every socket in the group set it; the existing listener did not."
+			}
+		} else if bc.ReuseAddr {
+			d.Details += " SO_REUSEADDR does not override a socket in LISTEN state."
+		}
+		out = append(out, d)
+	}
+	if len(out) > 0 {
+		return out
+	}
+	d := model.Diagnostic{
+		Kind:     "bind-conflict",
+		Severity: "warn",
+		Summary:  "No visible listener overlaps this bind",
+		Details:  "The port may be held by connections in TIME_WAIT, by a process portik cannot see (another user without root), or by a socket in another network namespace.",
+		Action:   "Retry with --reuseaddr if TIME_WAIT is the cause, or run with sudo to see every socket.",
+	}
+	if bc.ReuseAddr {
+		d.Action = "Run with sudo to see every socket."
+	}
+	return append(out, d)
+}
+
+// bindOverlap reports how the requested address collides with listener l,
+// or "" if it does not. An IPv6 wildcard listener is assumed dual-stack
+// (Linux default net.ipv6.bindv6only=0); the backends do not report
+// IPV6_V6ONLY.
+func bindOverlap(bc model.BindCheck, l model.Listener) string {
+	req := net.ParseIP(bc.Addr)
+	lip := parseListenerIP(l.LocalIP)
+	if req == nil {
+		return ""
+	}
+	if lip == nil {
+		// "*" from ss/lsof: wildcard of unknown family
+		return "listener-wildcard"
+	}
+	req4, l4 := req.To4() != nil, lip.To4() != nil
+	switch {
+	case req4 == l4 && req.Equal(lip):
+		return "same"
+	case req4 == l4 && lip.IsUnspecified():
+		return "listener-wildcard"
+	case req4 == l4 && req.IsUnspecified():
+		return "request-wildcard"
+	case req4 && !l4 && lip.IsUnspecified():
+		return "listener-dualstack"
+	case !req4 && l4 && req.IsUnspecified() && !bc.V6Only:
+		return "request-dualstack"
+	}
+	return ""
+}
+
+func parseListenerIP(s string) net.IP {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "["), "]")
+	if i := strings.IndexByte(s, '%'); i >= 0 {
+		s = s[:i]
+	}
+	return net.ParseIP(s)
+}
+
+func displayIP(s string) string {
+	ip := parseListenerIP(s)
+	switch {
+	case ip == nil:
+		return "*"
+	case ip.To4() == nil:
+		return "[" + ip.String() + "]"
+	default:
+		return ip.String()
+	}
+}
+
+func bindOpts(bc model.BindCheck) string {
+	var opts []string
+	if bc.ReuseAddr {
+		opts = append(opts, "SO_REUSEADDR")
+	}
+	if bc.ReusePort {
+		opts = append(opts, "SO_REUSEPORT")
+	}
+	if bc.V6Only {
+		opts = append(opts, "IPV6_V6ONLY")
+	}
+	if len(opts) == 0 {
+		return "no socket options"
+	}
+	return strings.Join(opts, ", ")
+}
+
+func errnoMeaning(bc model.BindCheck) string {
+	switch bc.Errno {
+	case "EADDRINUSE":
+		return "Another socket already holds an overlapping address and port."
+	case "EACCES", "EPERM":
+		return "The kernel denied permission for this bind."
+	case "EADDRNOTAVAIL":
+		return "The address is not available on this host."
+	case "":
+		return bc.Error + "."
+	default:
+		return "The kernel refused the bind."
+	}
+}
+
+func listenerList(ls []model.Listener) string {
+	parts := make([]string, 0, len(ls))
+	for _, l := range ls {
+		parts = append(parts, fmt.Sprintf("%s pid %d", displayIP(l.LocalIP), l.PID))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package inspect
+
+import (
+	"net"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func tryBind(net.IP, int, string, model.BindCheck) (string, string) {
+	return "", "bind test is not supported on this platform"
+}
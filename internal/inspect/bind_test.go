@@ -0,0 +1,118 @@
+package inspect
+
+import (
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func TestBindOverlap(t *testing.T) {
+	cases := []struct {
+		addr   string
+		v6only bool
+		laddr  string
+		want   string
+	}{
+		{"127.0.0.1", false, "0.0.0.0", "listener-wildcard"},
+		{"127.0.0.1", false, "127.0.0.1", "same"},
+		{"0.0.0.0", false, "127.0.0.1", "request-wildcard"},
+		{"127.0.0.1", false, "10.0.0.1", ""},
+		{"127.0.0.1", false, "::", "listener-dualstack"},
+		{"127.0.0.1", false, "[::]", "listener-dualstack"},
+		{"::", false, "0.0.0.0", "request-dualstack"},
+		{"::", true, "0.0.0.0", ""},
+		{"::1", false, "::", "listener-wildcard"},
+		{"::1", false, "127.0.0.1", ""},
+		{"127.0.0.1", false, "*", "listener-wildcard"},
+	}
+	for _, c := range cases {
+		bc := model.BindCheck{Addr: c.addr, Port: 8080, V6Only: c.v6only}
+		if got := bindOverlap(bc, model.Listener{LocalIP: c.laddr}); got != c.want {
+			t.Errorf("bindOverlap(%s v6only=%v, listener %s) = %q, want %q", c.addr, c.v6only, c.laddr, got, c.want)
+		}
+	}
+}
+
+func TestDiagnoseBindWildcardListener(t *testing.T) {
+	bc := model.BindCheck{Addr: "127.0.0.1", Port: 8080, Proto: "tcp", Errno: "EADDRINUSE", Error: "address already in use"}
+	ls := []model.Listener{{Family: "ipv4", LocalIP: "0.0.0.0", State: "LISTEN", PID: 42, ProcName: "nginx", User: "www"}}
+	d := diagnoseBind(bc, ls, "me", nil)
+	if len(d) != 2 {
+		t.Fatalf("got %d diagnostics, want 2: %+v", len(d), d)
+	}
+	if !strings.Contains(d[0].Summary, "EADDRINUSE") {
+		t.Fatalf("first diagnostic should name the errno: %q", d[0].Summary)
+	}
+	if d[1].Summary != "0.0.0.0 listener blocks 127.0.0.1 bind" {
+		t.Fatalf("conflict summary = %q", d[1].Summary)
+	}
+}
+
+func TestDiagnoseBindReusePortOtherUID(t *testing.T) {
+	bc := model.BindCheck{Addr: "0.0.0.0", Port: 8080, Proto: "tcp", ReusePort: true, Errno: "EADDRINUSE"}
+	ls := []model.Listener{{Family: "ipv4", LocalIP: "0.0.0.0", State: "LISTEN", PID: 42, ProcName: "app", User: "root"}}
+	d := diagnoseBind(bc, ls, "alice", nil)
+	if len(d) != 2 || d[1].Summary != "SO_REUSEPORT group owned by another uid" {
+		t.Fatalf("unexpected diagnostics: %+v", d)
+	}
+	if !strings.Contains(d[1].Action, "root") {
+		t.Fatalf("action should name the owning user: %q", d[1].Action)
+	}
+}
+
+func TestDiagnoseBindInvisibleHolder(t *testing.T) {
+	bc := model.BindCheck{Addr: "127.0.0.1", Port: 8080, Proto: "tcp", Errno: "EADDRINUSE"}
+	d := diagnoseBind(bc, nil, "me", nil)
+	if len(d) != 2 || d[1].Severity != "warn" {
+		t.Fatalf("expected a warning about an unseen holder: %+v", d)
+	}
+}
+
+func TestTryBindAddrInUse(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("bind test unsupported")
+	}
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	bc := TryBind(port, "tcp", BindRequest{Addr: "127.0.0.1"})
+	if bc.OK || bc.Errno != "EADDRINUSE" {
+		t.Fatalf("bind on a held port: ok=%v errno=%q err=%q", bc.OK, bc.Errno, bc.Error)
+	}
+	// Go's listener sets SO_REUSEADDR, which does not help against LISTEN.
+	bc = TryBind(port, "tcp", BindRequest{Addr: "127.0.0.1", ReuseAddr: true})
+	if bc.OK {
+		t.Fatalf("SO_REUSEADDR should not share a LISTEN port")
+	}
+}
+
+func TestParseBindAddr(t *testing.T) {
+	for in, want := range map[string]string{"127.0.0.1": "127.0.0.1", "[::1]": "::1", "::": "::", "*": "0.0.0.0"} {
+		got, err := ParseBindAddr(in)
+		if err != nil || got != want {
+			t.Errorf("ParseBindAddr(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseBindAddr("localhost"); err == nil {
+		t.Errorf("hostnames should be rejected")
+	}
+}
+
+func TestDiagnoseBindPrivilegedRefersToPermission(t *testing.T) {
+	bc := model.BindCheck{Addr: "0.0.0.0", Port: 80, Proto: "tcp", Errno: "EACCES"}
+	if d := diagnoseBind(bc, nil, "me", nil); len(d) != 2 || d[1].Summary != "Port 80 is privileged" {
+		t.Fatalf("without a privilege check: %+v", d)
+	}
+	pc := &model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 1000, CapsKnown: true}
+	d := diagnoseBind(bc, nil, "me", pc)
+	if len(d) != 1 || !strings.Contains(d[0].Details, "permission hint") {
+		t.Fatalf("with a privilege check: %+v", d)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build linux || darwin
+
+package inspect
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// tryBind returns the errno name and error text of the bind, both empty on
+// success. Go enables SO_REUSEADDR on listeners by default, so every option
+// is set explicitly to match what the application would do.
+func tryBind(ip net.IP, port int, proto string, bc model.BindCheck) (string, string) {
+	v6 := ip.To4() == nil
+	network := proto + "4"
+	if v6 {
+		network = proto + "6"
+	}
+	lc := net.ListenConfig{Control: func(_, _ string, c syscall.RawConn) error {
+		var serr error
+		err := c.Control(func(fd uintptr) {
+			serr = setBindOpts(int(fd), bc, v6)
+		})
+		if err != nil {
+			return err
+		}
+		return serr
+	}}
+	addr := net.JoinHostPort(ip.String(), strconv.Itoa(port))
+
+	var err error
+	if proto == "udp" {
+		var pc net.PacketConn
+		if pc, err = lc.ListenPacket(context.Background(), network, addr); err == nil {
+			pc.Close()
+		}
+	} else {
+		var ln net.Listener
+		if ln, err = lc.Listen(context.Background(), network, addr); err == nil {
+			ln.Close()
+		}
+	}
+	if err == nil {
+		return "", ""
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return unix.ErrnoName(errno), errno.Error()
+	}
+	return "", err.Error()
+}
+
+func setBindOpts(fd int, bc model.BindCheck, v6 bool) error {
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, boolInt(bc.ReuseAddr)); err != nil {
+		return err
+	}
+	if bc.ReusePort {
+		if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEPORT, 1); err != nil {
+			return err
+		}
+	}
+	if v6 {
+		return unix.SetsockoptInt(fd, unix.IPPROTO_IPV6, unix.IPV6_V6ONLY, boolInt(bc.V6Only))
+	}
+	return nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,222 @@
+package inspect
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+)
+
+// Thresholds for connection-state diagnostics.
+const (
+	timeWaitStorm = 500 // TIME_WAIT sockets on one port worth a warning
+	closeWaitLeak = 10  // CLOSE_WAIT sockets in one process that suggest a leak
+
+	ephemeralWarn  = 0.5 // share of the local port range in use toward one destination
+	ephemeralError = 0.9
+)
+
+// connState normalizes backend state names: ss and the kernel backends say
+// "TIME-WAIT", lsof says "TIME_WAIT".
+func connState(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "ESTAB" {
+		return "ESTABLISHED"
+	}
+	return strings.ReplaceAll(s, "-", "_")
+}
+
+func timeWaitDiagnostic(rep model.Report) (model.Diagnostic, bool) {
+	local, outbound := 0, 0
+	for _, c := range rep.Connections {
+		if connState(c.State) != "TIME_WAIT" {
+			continue
+		}
+		if c.LocalPort == rep.Port {
+			local++
+		} else if c.RemotePort == rep.Port {
+			outbound++
+		}
+	}
+	total := local + outbound
+	if total == 0 {
+		return model.Diagnostic{}, false
+	}
+
+	d := model.Diagnostic{
+		Kind:     "time-wait",
+		Severity: "info",
+		Summary:  "TIME_WAIT sockets present",
+		Action:   "Wait a few seconds and retry, or ensure the service uses SO_REUSEADDR where appropriate.",
+	}
+	var parts []string
+	if local > 0 {
+		parts = append(parts, fmt.Sprintf("%d on local port %d (this side closed first)", local, rep.Port))
+	}
+	if outbound > 0 {
+		parts = append(parts, fmt.Sprintf("%d outbound to remote port %d", outbound, rep.Port))
+	}
+	d.Details = fmt.Sprintf("Found %d TIME_WAIT connections: %s. Rapid restarts can cause transient address-in-use errors.", total, strings.Join(parts, ", "))
+
+	if total >= timeWaitStorm {
+		d.Severity = "warn"
+		d.Summary = fmt.Sprintf("TIME_WAIT pile-up (%d sockets)", total)
+		d.Details += " Connections are opened and closed faster than TIME_WAIT (60s on Linux) expires; short-lived connections without keep-alive are the usual cause."
+		d.Action = "Reuse connections (HTTP keep-alive, connection pooling) instead of one connection per request."
+		if outbound > 0 {
+			d.Action += " " + twReuseAdvice()
+		}
+	}
+	return d, true
+}
+
+func closeWaitDiagnostics(rep model.Report) []model.Diagnostic {
+	type leak struct {
+		pid   int32
+		name  string
+		count int
+	}
+	byPID := map[int32]*leak{}
+	for _, c := range rep.Connections {
+		if connState(c.State) != "CLOSE_WAIT" {
+			continue
+		}
+		lk := byPID[c.PID]
+		if lk == nil {
+			lk = &leak{pid: c.PID, name: c.ProcName}
+			byPID[c.PID] = lk
+		}
+		lk.count++
+	}
+	leaks := make([]*leak, 0, len(byPID))
+	for _, lk := range byPID {
+		if lk.count >= closeWaitLeak {
+			leaks = append(leaks, lk)
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].count != leaks[j].count {
+			return leaks[i].count > leaks[j].count
+		}
+		return leaks[i].pid < leaks[j].pid
+	})
+
+	var out []model.Diagnostic
+	for _, lk := range leaks {
+		owner := "an unknown process"
+		action := "Run with sudo to find the owning process, then fix the code path that drops connections without close()."
+		if lk.pid > 0 {
+			owner = fmt.Sprintf("pid %d (%s)", lk.pid, dash(lk.name))
+			action = fmt.Sprintf("Fix the code path in %s that drops connections without close(); restarting it releases them meanwhile.", dash(lk.name))
+		}
+		out = append(out, model.Diagnostic{
+			Kind:     "close-wait",
+			Severity: "warn",
+			Summary:  fmt.Sprintf("CLOSE_WAIT leak in %s: %d sockets", owner, lk.count),
+			Details:  "The peers closed these connections but the application never called close(). CLOSE_WAIT does not time out, so this is an application bug that leaks file descriptors.",
+			Action:   action,
+		})
+	}
+	return out
+}
+
+// ephemeralDiagnostics flags destinations that use up a large share of the
+// local port range. Each outbound connection to the same remote ip:port
+// from the same local address needs its own local port, TIME_WAIT included.
+func ephemeralDiagnostics(rep model.Report) []model.Diagnostic {
+	rng, ok := platform.SysctlInts("net.ipv4.ip_local_port_range")
+	if !ok || len(rng) != 2 || rng[1] < rng[0] {
+		return nil
+	}
+	return ephemeralInRange(rep, rng[0], rng[1])
+}
+
+func ephemeralInRange(rep model.Report, lo, hi int) []model.Diagnostic {
+	size := hi - lo + 1
+
+	type dest struct {
+		local, remote string
+		count, tw     int
+	}
+	byDest := map[string]*dest{}
+	for _, c := range rep.Connections {
+		if c.RemotePort != rep.Port || c.LocalPort < lo || c.LocalPort > hi {
+			continue
+		}
+		key := c.LocalIP + "|" + c.RemoteIP
+		d := byDest[key]
+		if d == nil {
+			d = &dest{local: c.LocalIP, remote: net.JoinHostPort(c.RemoteIP, fmt.Sprint(c.RemotePort))}
+			byDest[key] = d
+		}
+		d.count++
+		if connState(c.State) == "TIME_WAIT" {
+			d.tw++
+		}
+	}
+	keys := make([]string, 0, len(byDest))
+	for k := range byDest {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out []model.Diagnostic
+	for _, k := range keys {
+		d := byDest[k]
+		share := float64(d.count) / float64(size)
+		if share < ephemeralWarn {
+			continue
+		}
+		sev := "warn"
+		summary := fmt.Sprintf("Ephemeral ports toward %s are running low", d.remote)
+		if share >= ephemeralError {
+			sev = "error"
+			summary = fmt.Sprintf("Ephemeral ports toward %s are nearly exhausted", d.remote)
+		}
+		details := fmt.Sprintf("%d of %d local ports (%d-%d, net.ipv4.ip_local_port_range) are used by connections from %s to %s, %d of them in TIME_WAIT. New connects fail with EADDRNOTAVAIL once the range is used up.",
+			d.count, size, lo, hi, d.local, d.remote, d.tw)
+		action := "Reuse connections (keep-alive, pooling)"
+		if wlo, whi := widerRange(lo, hi); wlo != lo || whi != hi {
+			action += fmt.Sprintf(" or widen the range: sysctl -w net.ipv4.ip_local_port_range=\"%d %d\".", wlo, whi)
+		} else {
+			action += ", or spread them over more local addresses; the range is already as wide as it goes."
+		}
+		if d.tw > 0 {
+			action += " " + twReuseAdvice()
+		}
+		out = append(out, model.Diagnostic{
+			Kind:     "ephemeral-ports",
+			Severity: sev,
+			Summary:  summary,
+			Details:  details,
+			Action:   action,
+		})
+	}
+	return out
+}
+
+// widerRange is the local port range to suggest instead of lo-hi: up to
+// 65535, and down to 1024 but never below a floor that is already lower.
+func widerRange(lo, hi int) (int, int) {
+	return min(lo, 1024), 65535
+}
+
+// twReuseAdvice suggests net.ipv4.tcp_tw_reuse for outbound TIME_WAIT,
+// based on its current value (0 off, 1 on, 2 loopback only).
+func twReuseAdvice() string {
+	v, ok := platform.SysctlInts("net.ipv4.tcp_tw_reuse")
+	if !ok {
+		return "On Linux, net.ipv4.tcp_tw_reuse=1 lets new outbound connections reuse TIME_WAIT ports."
+	}
+	switch v[0] {
+	case 1:
+		return "net.ipv4.tcp_tw_reuse is already 1; reducing connection churn is what remains."
+	case 2:
+		return "net.ipv4.tcp_tw_reuse=2 only covers loopback; set it to 1 (sysctl -w net.ipv4.tcp_tw_reuse=1) for other destinations."
+	default:
+		return fmt.Sprintf("Enable reuse of TIME_WAIT ports for outbound connections: sysctl -w net.ipv4.tcp_tw_reuse=1 (currently %d).", v[0])
+	}
+}
@@ -2,76 +2,228 @@ package inspect
 
 import (
 	"fmt"
-	"os/user"
+	"slices"
+	"strings"
 
+	"github.com/pratik-anurag/portik/internal/firewall"
+	"github.com/pratik-anurag/portik/internal/forwarder"
 	"github.com/pratik-anurag/portik/internal/model"
 	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/probe"
 )
 
+// Diagnose runs the rules of the default registry over rep.
 func Diagnose(rep model.Report) []model.Diagnostic {
+	return Default.Diagnose(rep)
+}
+
+// builtinRules are portik's own checks, in the order their hints are shown.
+func builtinRules() []Diagnoser {
+	return []Diagnoser{
+		Rule("bind-test", diagBindTest), // first: it is what the user asked about
+		Rule("privileged-port", diagPrivilegedPort),
+		Rule("in-use", diagInUse),
+		Rule("tunnel", diagTunnel),
+		Rule("protocol", diagProtocol),
+		Rule("pid-missing", diagPIDMissing),
+		Rule("multi-listener", diagMultiListener),
+		Rule("backlog", diagBacklog),
+		Rule("ipv6-only", diagIPv6Only),
+		Rule("loopback-only", diagLoopbackOnly),
+		Rule("firewall", diagFirewall),
+		Rule("time-wait", diagTimeWait),
+		Rule("close-wait", closeWaitDiagnostics),
+		Rule("ephemeral-ports", ephemeralDiagnostics),
+		Rule("zombie", diagZombie),
+		Rule("docker", diagDocker),
+		Rule("container", diagContainer),
+		Rule("wsl", diagWSL),
+		Rule("vm", diagVM),
+	}
+}
+
+func diagBindTest(rep model.Report) []model.Diagnostic {
+	if rep.Bind == nil {
+		return nil
+	}
+	return diagnoseBind(*rep.Bind, rep.Listeners, rep.User.Username, rep.Privilege)
+}
+
+func diagPrivilegedPort(rep model.Report) []model.Diagnostic {
+	if rep.Privilege == nil {
+		return nil
+	}
+	return privilegeDiagnostics(*rep.Privilege, rep, rep.Host.OS)
+}
+
+func diagInUse(rep model.Report) []model.Diagnostic {
+	l, ok := rep.PrimaryListener()
+	if !ok || l.PID <= 0 || l.State != "LISTEN" {
+		return nil
+	}
+	return []model.Diagnostic{{
+		Kind:     "in-use",
+		Severity: "info",
+		Summary:  "Port is in use",
+		Details:  fmt.Sprintf("pid %d (%s) is listening on %d/%s", l.PID, l.ProcName, rep.Port, rep.Proto),
+		Action:   "Stop it: portik kill <port>  |  Restart it: portik restart <port>",
+	}}
+}
+
+// diagTunnel explains listeners held by a forwarder: the service behind the
+// port runs elsewhere, and closing the tunnel may need more than a kill.
+func diagTunnel(rep model.Report) []model.Diagnostic {
 	var out []model.Diagnostic
+	seen := map[int32]bool{}
+	for _, l := range rep.Listeners {
+		if l.Tunnel == nil || seen[l.PID] {
+			continue
+		}
+		seen[l.PID] = true
+		t := *l.Tunnel
+		var details []string
+		if t.Target != "" {
+			details = append(details, fmt.Sprintf("pid %d (%s) relays connections to %s; the service itself runs there", l.PID, dash(l.ProcName), t.Target))
+		} else {
+			details = append(details, fmt.Sprintf("pid %d (%s) forwards this port, but its target is not on the command line (e.g. LocalForward in ~/.ssh/config)", l.PID, dash(l.ProcName)))
+		}
+		if t.Spec != "" {
+			details = append(details, "forward: "+t.Spec)
+		}
+		if t.Peer != "" {
+			details = append(details, "upstream connection: "+t.Peer)
+		}
+		action := "Close the tunnel: portik kill <port>"
+		if c := forwarder.HowToClose(t, l.Cmdline); c.Refuse != "" {
+			action = c.Refuse
+		}
+		out = append(out, model.Diagnostic{
+			Kind:     "tunnel",
+			Severity: "info",
+			Summary:  "Port is a " + t.String(),
+			Details:  strings.Join(details, "; "),
+			Action:   action,
+		})
+	}
+	return out
+}
 
-	// privileged port
-	if rep.Port < 1024 {
-		if u, err := user.Current(); err == nil && u.Uid != "0" {
+// diagProtocol reports what a probed listener serves, and warns when it is
+// not what the port is known for (an HTTP dev server on 5432).
+func diagProtocol(rep model.Report) []model.Diagnostic {
+	var out []model.Diagnostic
+	seen := map[*model.Fingerprint]bool{}
+	for _, l := range rep.Listeners {
+		fp := l.Probe
+		if fp == nil || seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		owner := fmt.Sprintf("pid %d (%s)", l.PID, dash(l.ProcName))
+		if fp.Protocol == "" {
+			out = append(out, model.Diagnostic{
+				Kind:     "protocol",
+				Severity: "warn",
+				Summary:  fmt.Sprintf("Probe could not connect to %s", fp.Addr),
+				Details:  fmt.Sprintf("%s listens, but connecting failed: %s.", owner, dash(fp.Error)),
+				Action:   "Check local firewall rules and whether the process is stuck (accept queue full?)",
+			})
+			continue
+		}
+		want := probe.Expected(rep.Port)
+		if len(want) == 0 || fp.Protocol == "unknown" || slices.Contains(want, fp.Protocol) {
 			out = append(out, model.Diagnostic{
-				Kind:     "permission",
+				Kind:     "protocol",
 				Severity: "info",
-				Summary:  "Privileged port may require admin/root",
-				Details:  fmt.Sprintf("Port %d is < 1024. On many systems binding requires root/admin privileges.", rep.Port),
-				Action:   "Try running with sudo or choose a higher port.",
+				Summary:  "Port serves " + fp.String(),
+				Details:  probeDetails(*fp, owner),
 			})
+			continue
+		}
+		names := make([]string, len(want))
+		for i, p := range want {
+			names[i] = protocolName(p)
 		}
-	}
-
-	// in-use
-	if l, ok := rep.PrimaryListener(); ok && l.PID > 0 && l.State == "LISTEN" {
 		out = append(out, model.Diagnostic{
-			Kind:     "in-use",
-			Severity: "info",
-			Summary:  "Port is in use",
-			Details:  fmt.Sprintf("pid %d (%s) is listening on %d/%s", l.PID, l.ProcName, rep.Port, rep.Proto),
-			Action:   "Stop it: portik kill <port>  |  Restart it: portik restart <port>",
+			Kind:     "protocol",
+			Severity: "warn",
+			Summary:  fmt.Sprintf("Port %d serves %s, not %s", rep.Port, protocolName(fp.Protocol), strings.Join(names, " or ")),
+			Details:  probeDetails(*fp, owner),
+			Action:   "Clients expecting " + names[0] + " will fail. If this is a stray process, stop it: portik kill <port>",
 		})
 	}
+	return out
+}
+
+func probeDetails(fp model.Fingerprint, owner string) string {
+	d := fmt.Sprintf("%s answered a probe of %s as %s", owner, fp.Addr, fp.String())
+	if c := fp.Cert; c != nil {
+		d += fmt.Sprintf("; certificate %s, expires %s", c.Subject, c.NotAfter.Format("2006-01-02"))
+	}
+	return d
+}
+
+var protocolNames = map[string]string{
+	"http": "HTTP", "https": "HTTPS", "http2": "HTTP/2", "grpc": "gRPC", "tls": "TLS",
+	"ssh": "SSH", "redis": "Redis", "postgres": "PostgreSQL", "mysql": "MySQL",
+}
+
+func protocolName(p string) string {
+	if n, ok := protocolNames[p]; ok {
+		return n
+	}
+	return p
+}
 
-	// missing pid
+func diagPIDMissing(rep model.Report) []model.Diagnostic {
 	pidCount := 0
 	for _, l := range rep.Listeners {
 		if l.PID > 0 {
 			pidCount++
 		}
 	}
-	if len(rep.Listeners) > 0 && pidCount == 0 {
-		out = append(out, model.Diagnostic{
-			Kind:     "pid-missing",
-			Severity: "warn",
-			Summary:  "Process details unavailable",
-			Details:  "Port has listeners but no owning PID/cmdline was found. This can happen without elevated privileges.",
-			Action:   "Re-run with sudo/admin, or check OS-specific permissions.",
-		})
+	if len(rep.Listeners) == 0 || pidCount > 0 {
+		return nil
 	}
+	return []model.Diagnostic{{
+		Kind:     "pid-missing",
+		Severity: "warn",
+		Summary:  "Process details unavailable",
+		Details:  "Port has listeners but no owning PID/cmdline was found. This can happen without elevated privileges.",
+		Action:   "Re-run with sudo/admin, or check OS-specific permissions.",
+	}}
+}
 
-	// multiple listeners
-	if len(rep.Listeners) > 1 {
-		pids := map[int32]bool{}
-		for _, l := range rep.Listeners {
-			if l.PID > 0 {
-				pids[l.PID] = true
-			}
+func diagMultiListener(rep model.Report) []model.Diagnostic {
+	pids := map[int32]bool{}
+	for _, l := range rep.Listeners {
+		if l.PID > 0 {
+			pids[l.PID] = true
 		}
-		if len(pids) > 1 {
-			out = append(out, model.Diagnostic{
-				Kind:     "multi-listener",
-				Severity: "info",
-				Summary:  "Multiple processes are listening on the same port",
-				Details:  "More than one PID owns listeners for this port. This is common with SO_REUSEPORT or multiple instances.",
-				Action:   "Check if multiple instances were started, or if the service is configured to share the port.",
-			})
+	}
+	if len(pids) < 2 {
+		return nil
+	}
+	return []model.Diagnostic{{
+		Kind:     "multi-listener",
+		Severity: "info",
+		Summary:  "Multiple processes are listening on the same port",
+		Details:  "More than one PID owns listeners for this port. This is common with SO_REUSEPORT or multiple instances.",
+		Action:   "Check if multiple instances were started, or if the service is configured to share the port.",
+	}}
+}
+
+// diagBacklog reports the first listener whose accept queue is saturated.
+func diagBacklog(rep model.Report) []model.Diagnostic {
+	for _, l := range rep.Listeners {
+		if d, ok := backlogDiagnostic(l); ok {
+			return []model.Diagnostic{d}
 		}
 	}
+	return nil
+}
 
-	// ipv6-only
+func diagIPv6Only(rep model.Report) []model.Diagnostic {
 	hasV4 := false
 	hasV6 := false
 	for _, l := range rep.Listeners {
@@ -82,119 +234,260 @@ func Diagnose(rep model.Report) []model.Diagnostic {
 			hasV6 = true
 		}
 	}
-	if hasV6 && !hasV4 && rep.Proto == "tcp" && len(rep.Listeners) > 0 {
-		out = append(out, model.Diagnostic{
-			Kind:     "ipv6-only",
-			Severity: "warn",
-			Summary:  "Only IPv6 listener detected (IPv4 bind confusion)",
-			Details:  "A process is listening on IPv6 only. Binding on 0.0.0.0:<port> may fail or appear unreachable from IPv4.",
-			Action:   "Bind to [::] or enable dual-stack, or ensure the app listens on IPv4 too.",
-		})
+	if !hasV6 || hasV4 || rep.Proto != "tcp" {
+		return nil
 	}
+	return []model.Diagnostic{{
+		Kind:     "ipv6-only",
+		Severity: "warn",
+		Summary:  "Only IPv6 listener detected (IPv4 bind confusion)",
+		Details:  "A process is listening on IPv6 only. Binding on 0.0.0.0:<port> may fail or appear unreachable from IPv4.",
+		Action:   "Bind to [::] or enable dual-stack, or ensure the app listens on IPv4 too.",
+	}}
+}
 
-	// loopback-only
-	loopbackOnly := listenersLoopbackOnly(rep.Listeners)
-	if loopbackOnly {
-		out = append(out, model.Diagnostic{
-			Kind:     "loopback-only",
-			Severity: "info",
-			Summary:  "Service is bound to loopback only",
-			Details:  "The listener is bound to 127.0.0.1 or ::1. It will not accept connections from other hosts.",
-			Action:   "Bind to 0.0.0.0 or [::] if you need external access.",
-		})
+func diagLoopbackOnly(rep model.Report) []model.Diagnostic {
+	if !listenersLoopbackOnly(rep.Listeners) {
+		return nil
 	}
+	return []model.Diagnostic{{
+		Kind:     "loopback-only",
+		Severity: "info",
+		Summary:  "Service is bound to loopback only",
+		Details:  "The listener is bound to 127.0.0.1 or ::1. It will not accept connections from other hosts.",
+		Action:   "Bind to 0.0.0.0 or [::] if you need external access.",
+	}}
+}
 
-	// firewall
-	if len(rep.Listeners) > 0 && !loopbackOnly {
-		fw := platform.FirewallStatus()
-		if fw.Active {
-			summary := "Host firewall appears to be active"
-			if fw.Name != "" {
-				summary = fmt.Sprintf("Host firewall appears to be active (%s)", fw.Name)
+// diagFirewall evaluates the host's ruleset for inbound traffic to each
+// non-loopback listener. When no ruleset is readable (portik usually needs
+// root for that) it falls back to saying a firewall is running.
+func diagFirewall(rep model.Report) []model.Diagnostic {
+	if len(rep.Listeners) == 0 || listenersLoopbackOnly(rep.Listeners) {
+		return nil
+	}
+	fw := platform.FirewallStatus()
+	dumps := firewall.Dumps(platform.FirewallRules())
+	var out []model.Diagnostic
+	evaluated := false
+	seen := map[string]bool{}
+	for _, l := range rep.Listeners {
+		if isLoopbackAddr(l.LocalIP) {
+			continue
+		}
+		for _, p := range firewallPackets(rep, l) {
+			v, ok := firewall.Evaluate(dumps, p)
+			if !ok {
+				continue
 			}
-			out = append(out, model.Diagnostic{
-				Kind:     "firewall",
-				Severity: "info",
-				Summary:  summary,
-				Details:  "A local firewall is running; inbound connections to this port may be blocked even though the service is listening.",
-				Action:   "Check firewall rules and allow the port if external access is required.",
-			})
+			evaluated = true
+			key := p.String() + "|" + v.Action + "|" + v.Rule
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if v.Action == "accept" && !fw.Active && v.Policy {
+				continue // nothing is filtering this port
+			}
+			out = append(out, firewallDiagnostic(p, v, fw))
 		}
 	}
+	if evaluated || !fw.Active {
+		return out
+	}
+	summary := "Host firewall appears to be active"
+	if fw.Name != "" {
+		summary = fmt.Sprintf("Host firewall appears to be active (%s)", fw.Name)
+	}
+	return []model.Diagnostic{{
+		Kind:     "firewall",
+		Severity: "info",
+		Summary:  summary,
+		Details:  "A local firewall is running; inbound connections to this port may be blocked even though the service is listening.",
+		Action:   "Check firewall rules and allow the port if external access is required (run portik as root to let it read the ruleset).",
+	}}
+}
+
+// firewallPackets is the inbound traffic a listener accepts: one packet per
+// address family, with no destination address for wildcard binds.
+func firewallPackets(rep model.Report, l model.Listener) []firewall.Packet {
+	p := firewall.Packet{Proto: rep.Proto, Family: "ipv4", DstPort: rep.Port, Ifaces: platform.Interfaces()}
+	switch strings.Trim(l.LocalIP, "[]") {
+	case "", "*", "0.0.0.0":
+		return []firewall.Packet{p}
+	case "::":
+		// dual-stack unless IPV6_V6ONLY, which the socket table does not show
+		p6 := p
+		p6.Family = "ipv6"
+		return []firewall.Packet{p6, p}
+	}
+	ip := parseListenerIP(l.LocalIP)
+	if ip == nil {
+		return nil
+	}
+	p.DstIP = ip
+	if ip.To4() == nil {
+		p.Family = "ipv6"
+	}
+	return []firewall.Packet{p}
+}
 
-	// TIME_WAIT
-	timeWait := 0
-	for _, c := range rep.Connections {
-		if c.State == "TIME_WAIT" && c.LocalPort == rep.Port {
-			timeWait++
+func firewallDiagnostic(p firewall.Packet, v firewall.Verdict, fw platform.FirewallInfo) model.Diagnostic {
+	d := model.Diagnostic{Kind: "firewall"}
+	details := []string{fmt.Sprintf("%s rule: %s", v.Source, v.Rule)}
+	if len(v.Notes) > 0 {
+		details = append(details, "Skipped rules limited to some traffic or not resolved: "+strings.Join(v.Notes, "; "))
+	}
+	d.Details = strings.Join(details, ". ")
+	fam := "IPv4"
+	if p.Family == "ipv6" {
+		fam = "IPv6"
+	}
+	switch v.Action {
+	case "accept":
+		d.Severity = "info"
+		d.Summary = fmt.Sprintf("Firewall accepts inbound %s (%s, %s)", p, fam, v.Source)
+	case "unknown":
+		d.Severity = "info"
+		d.Summary = fmt.Sprintf("Firewall verdict for inbound %s is unknown (%s, %s)", p, fam, v.Source)
+		d.Action = "Check whether the rules in the notes cover this port (ufw app info <profile>)."
+	default:
+		d.Severity = "warn"
+		verb := "drops"
+		if v.Action == "reject" {
+			verb = "rejects"
 		}
+		d.Summary = fmt.Sprintf("Firewall %s inbound %s (%s, %s)", verb, p, fam, v.Source)
+		d.Action = firewallAllowCommand(p, v, fw)
 	}
-	if timeWait > 0 {
-		out = append(out, model.Diagnostic{
-			Kind:     "time-wait",
-			Severity: "info",
-			Summary:  "TIME_WAIT sockets present",
-			Details:  fmt.Sprintf("Found %d TIME_WAIT connections involving local port %d. Rapid restarts can cause transient address-in-use errors.", timeWait, rep.Port),
-			Action:   "Wait a few seconds and retry, or ensure the service uses SO_REUSEADDR where appropriate.",
-		})
+	return d
+}
+
+// firewallAllowCommand suggests how to open the port with the tool that
+// manages the ruleset.
+func firewallAllowCommand(p firewall.Packet, v firewall.Verdict, fw platform.FirewallInfo) string {
+	switch {
+	case v.Source == "ufw":
+		return fmt.Sprintf("Allow it: sudo ufw allow %d/%s", p.DstPort, p.Proto)
+	case fw.Name == "firewalld" || strings.Contains(v.Chain, "firewalld"):
+		return fmt.Sprintf("Allow it: sudo firewall-cmd --permanent --add-port=%d/%s && sudo firewall-cmd --reload", p.DstPort, p.Proto)
+	case v.Source == "nftables":
+		return fmt.Sprintf("Allow it: sudo nft insert rule %s %s dport %d accept", v.Chain, p.Proto, p.DstPort)
 	}
+	tool := "iptables"
+	if p.Family == "ipv6" {
+		tool = "ip6tables"
+	}
+	return fmt.Sprintf("Allow it: sudo %s -I INPUT -p %s --dport %d -j ACCEPT", tool, p.Proto, p.DstPort)
+}
+
+func diagTimeWait(rep model.Report) []model.Diagnostic {
+	if d, ok := timeWaitDiagnostic(rep); ok {
+		return []model.Diagnostic{d}
+	}
+	return nil
+}
 
-	// zombie
+func diagZombie(rep model.Report) []model.Diagnostic {
 	for _, l := range rep.Listeners {
 		if l.IsZombie {
-			out = append(out, model.Diagnostic{
+			return []model.Diagnostic{{
 				Kind:     "zombie",
 				Severity: "warn",
 				Summary:  "Zombie process detected owning the port",
 				Details:  fmt.Sprintf("pid %d (%s) appears to be a zombie. Parent process must reap it.", l.PID, l.ProcName),
 				Action:   "Restart the parent process, or reboot if the zombie cannot be reaped.",
-			})
-			break
+			}}
 		}
 	}
+	return nil
+}
 
-	// docker
-	if rep.Docker.Mapped {
-		out = append(out, model.Diagnostic{
-			Kind:     "docker",
-			Severity: "info",
-			Summary:  "Port is mapped from a Docker container",
-			Details: fmt.Sprintf("Host port %d/%s is mapped to %s (%s) service=%s containerPort=%s",
-				rep.Port, rep.Proto, rep.Docker.ContainerID, rep.Docker.ContainerName, rep.Docker.ComposeService, rep.Docker.ContainerPort),
-			Action: "Use: portik restart <port> --docker --container",
-		})
+func diagDocker(rep model.Report) []model.Diagnostic {
+	if !rep.Docker.Mapped {
+		return nil
 	}
+	return []model.Diagnostic{{
+		Kind:     "docker",
+		Severity: "info",
+		Summary:  "Port is mapped from a Docker container",
+		Details: fmt.Sprintf("Host port %d/%s is mapped to %s (%s) service=%s containerPort=%s",
+			rep.Port, rep.Proto, rep.Docker.ContainerID, rep.Docker.ContainerName, rep.Docker.ComposeService, rep.Docker.ContainerPort),
+		Action: "Use: portik restart <port> --docker --container",
+	}}
+}
 
-	// env hints
-	if platform.InContainer() {
-		out = append(out, model.Diagnostic{
-			Kind:     "env",
-			Severity: "info",
-			Summary:  "Running inside a container",
-			Details:  "Socket-to-process mapping can be limited across container/host boundaries.",
-			Action:   "Run portik on the host; use --docker if relevant.",
-		})
+func diagContainer(model.Report) []model.Diagnostic {
+	if !platform.InContainer() {
+		return nil
 	}
-	if platform.InWSL() {
-		out = append(out, model.Diagnostic{
-			Kind:     "env",
-			Severity: "info",
-			Summary:  "Running in WSL",
-			Details:  "WSL networking can differ from native Linux across Windows/WSL boundary.",
-			Action:   "Check if the port is bound in Windows or inside WSL; run portik in both contexts if needed.",
-		})
+	return []model.Diagnostic{{
+		Kind:     "env",
+		Severity: "info",
+		Summary:  "Running inside a container",
+		Details:  "Socket-to-process mapping can be limited across container/host boundaries.",
+		Action:   "Run portik on the host; use --docker if relevant.",
+	}}
+}
+
+func diagWSL(model.Report) []model.Diagnostic {
+	if !platform.InWSL() {
+		return nil
 	}
-	if platform.InVM() {
-		out = append(out, model.Diagnostic{
-			Kind:     "vm",
-			Severity: "info",
-			Summary:  "Running inside a VM",
-			Details:  "Mapping host ports to services across VM boundaries is limited without hypervisor integration.",
-			Action:   "Run portik in the same OS context where the service is running (host vs guest).",
-		})
+	return []model.Diagnostic{{
+		Kind:     "env",
+		Severity: "info",
+		Summary:  "Running in WSL",
+		Details:  "WSL networking can differ from native Linux across Windows/WSL boundary.",
+		Action:   "Check if the port is bound in Windows or inside WSL; run portik in both contexts if needed.",
+	}}
+}
+
+func diagVM(model.Report) []model.Diagnostic {
+	if !platform.InVM() {
+		return nil
 	}
+	return []model.Diagnostic{{
+		Kind:     "vm",
+		Severity: "info",
+		Summary:  "Running inside a VM",
+		Details:  "Mapping host ports to services across VM boundaries is limited without hypervisor integration.",
+		Action:   "Run portik in the same OS context where the service is running (host vs guest).",
+	}}
+}
 
-	return model.DedupeDiagnostics(out)
+// backlogDiagnostic flags a LISTEN socket whose accept queue (RecvQ) is at
+// or near its backlog (SendQ). New connections are dropped or delayed until
+// the application calls accept().
+func backlogDiagnostic(l model.Listener) (model.Diagnostic, bool) {
+	if l.State != "LISTEN" || l.SendQ <= 0 || l.RecvQ <= 0 {
+		return model.Diagnostic{}, false
+	}
+	if l.RecvQ*10 < l.SendQ*9 {
+		return model.Diagnostic{}, false
+	}
+	sev := "warn"
+	summary := "Accept queue is nearly full (backlog saturated)"
+	if l.RecvQ >= l.SendQ {
+		sev = "error"
+		summary = "Accept queue is full (backlog saturated)"
+	}
+	where := fmt.Sprintf("%s:%d", l.LocalIP, l.LocalPort)
+	if l.Path != "" {
+		where = l.Path
+	}
+	details := fmt.Sprintf("%d of %d pending connections are waiting for pid %d (%s) to accept() on %s. Clients will hang or time out while the queue is full.",
+		l.RecvQ, l.SendQ, l.PID, l.ProcName, where)
+	if max, ok := platform.SysctlInts("net.core.somaxconn"); ok && max[0] == l.SendQ {
+		details += fmt.Sprintf(" The backlog is capped by net.core.somaxconn=%d.", max[0])
+	}
+	return model.Diagnostic{
+		Kind:     "backlog",
+		Severity: sev,
+		Summary:  summary,
+		Details:  details,
+		Action:   "Check whether the process is blocked or short of workers; if load is legitimate, raise the listen() backlog (and net.core.somaxconn).",
+	}, true
 }
 
 func isLoopbackAddr(ip string) bool {
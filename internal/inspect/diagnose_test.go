@@ -1,9 +1,14 @@
 package inspect
 
 import (
+	"net"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/pratik-anurag/portik/internal/firewall"
 	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
 )
 
 func TestDiagnoseIPv6Only(t *testing.T) {
@@ -86,3 +91,173 @@ func TestDiagnoseMultiListener(t *testing.T) {
 		t.Fatalf("expected multi-listener diagnostic")
 	}
 }
+
+func TestDiagnoseBacklogSaturated(t *testing.T) {
+	rep := model.Report{
+		Port:  8080,
+		Proto: "tcp",
+		Listeners: []model.Listener{
+			{Family: "ipv4", LocalIP: "0.0.0.0", LocalPort: 8080, State: "LISTEN", PID: 10, ProcName: "x", RecvQ: 129, SendQ: 128},
+		},
+	}
+	var got *model.Diagnostic
+	for _, x := range Diagnose(rep) {
+		if x.Kind == "backlog" {
+			got = &x
+		}
+	}
+	if got == nil || got.Severity != "error" {
+		t.Fatalf("expected backlog error diagnostic, got %+v", got)
+	}
+
+	rep.Listeners[0].RecvQ = 3
+	for _, x := range Diagnose(rep) {
+		if x.Kind == "backlog" {
+			t.Fatalf("unexpected backlog diagnostic for a mostly empty queue")
+		}
+	}
+}
+
+func TestDiagnoseUnixStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	// keep the file behind after closing, like a crashed server
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	d := DiagnoseUnix(model.Report{Proto: "unix", Path: path})
+	if len(d) != 1 || d[0].Kind != "stale-socket" {
+		t.Fatalf("expected stale-socket diagnostic, got %+v", d)
+	}
+}
+
+func TestDiagnoseTimeWaitDashedState(t *testing.T) {
+	rep := model.Report{
+		Port:  5432,
+		Proto: "tcp",
+		Connections: []model.Conn{
+			{LocalIP: "127.0.0.1", LocalPort: 5432, RemoteIP: "127.0.0.1", RemotePort: 54321, State: "TIME-WAIT"},
+		},
+	}
+	found := false
+	for _, d := range Diagnose(rep) {
+		if d.Kind == "time-wait" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected time-wait diagnostic for ss-style state names")
+	}
+}
+
+func TestDiagnoseCloseWaitLeak(t *testing.T) {
+	rep := model.Report{Port: 8080, Proto: "tcp"}
+	for i := 0; i < closeWaitLeak; i++ {
+		rep.Connections = append(rep.Connections, model.Conn{
+			LocalIP: "10.0.0.5", LocalPort: 8080, RemoteIP: "10.0.0.9", RemotePort: 40000 + i,
+			State: "CLOSE-WAIT", PID: 77, ProcName: "api",
+		})
+	}
+	rep.Connections = append(rep.Connections, model.Conn{LocalPort: 8080, RemotePort: 1, State: "CLOSE-WAIT", PID: 78, ProcName: "other"})
+
+	var leaks []model.Diagnostic
+	for _, d := range Diagnose(rep) {
+		if d.Kind == "close-wait" {
+			leaks = append(leaks, d)
+		}
+	}
+	if len(leaks) != 1 || !strings.Contains(leaks[0].Summary, "pid 77 (api)") {
+		t.Fatalf("expected one leak attributed to pid 77: %+v", leaks)
+	}
+}
+
+func TestEphemeralExhaustion(t *testing.T) {
+	rep := model.Report{Port: 5432, Proto: "tcp"}
+	for p := 40000; p < 40095; p++ {
+		rep.Connections = append(rep.Connections, model.Conn{
+			LocalIP: "10.0.0.5", LocalPort: p, RemoteIP: "10.0.0.9", RemotePort: 5432, State: "TIME-WAIT",
+		})
+	}
+	// another destination well below the threshold
+	rep.Connections = append(rep.Connections, model.Conn{LocalIP: "10.0.0.5", LocalPort: 40200, RemoteIP: "10.0.0.10", RemotePort: 5432, State: "ESTAB"})
+
+	d := ephemeralInRange(rep, 40000, 40099)
+	if len(d) != 1 || d[0].Severity != "error" || !strings.Contains(d[0].Summary, "10.0.0.9:5432") {
+		t.Fatalf("expected exhaustion toward 10.0.0.9:5432: %+v", d)
+	}
+	if !strings.Contains(d[0].Details, "95 of 100") || !strings.Contains(d[0].Action, "ip_local_port_range") {
+		t.Fatalf("details/action should quote the range: %+v", d[0])
+	}
+	if !strings.Contains(d[0].Action, `ip_local_port_range="1024 65535"`) {
+		t.Fatalf("action should widen from the current range: %q", d[0].Action)
+	}
+	if lo, hi := widerRange(1000, 1099); lo != 1000 || hi != 65535 {
+		t.Fatalf("a floor below 1024 should be kept: %d-%d", lo, hi)
+	}
+	if d := ephemeralInRange(rep, 32768, 60999); len(d) != 0 {
+		t.Fatalf("a wide range should not be flagged: %+v", d)
+	}
+}
+
+func TestFirewallDiagnostic(t *testing.T) {
+	rep := model.Report{Port: 5432, Proto: "tcp"}
+	ps := firewallPackets(rep, model.Listener{LocalIP: "::"})
+	if len(ps) != 2 || ps[0].Family != "ipv6" || ps[1].Family != "ipv4" || ps[0].DstIP != nil {
+		t.Fatalf("a :: listener should be evaluated for both families: %+v", ps)
+	}
+	if ps := firewallPackets(rep, model.Listener{LocalIP: "[::]"}); len(ps) != 2 || ps[0].DstIP != nil {
+		t.Fatalf("a bracketed [::] listener is the wildcard too: %+v", ps)
+	}
+	ps = firewallPackets(rep, model.Listener{LocalIP: "10.0.0.5"})
+	if len(ps) != 1 || !ps[0].DstIP.Equal(net.ParseIP("10.0.0.5")) {
+		t.Fatalf("specific address: %+v", ps)
+	}
+
+	v := firewall.Verdict{Action: "drop", Source: "nftables", Rule: "chain inet filter input policy drop", Chain: "inet filter input", Policy: true,
+		Notes: []string{"inet filter input: ip saddr 10.0.0.0/8 tcp dport 5432 accept (handle 10) (only from 10.0.0.0/8)"}}
+	d := firewallDiagnostic(ps[0], v, platform.FirewallInfo{})
+	if d.Severity != "warn" || !strings.Contains(d.Summary, "drops") || !strings.Contains(d.Details, "only from 10.0.0.0/8") {
+		t.Fatalf("drop: %+v", d)
+	}
+	if d.Action != "Allow it: sudo nft insert rule inet filter input tcp dport 5432 accept" {
+		t.Fatalf("nft action: %q", d.Action)
+	}
+	v.Chain = "inet firewalld filter_INPUT"
+	if d := firewallDiagnostic(ps[0], v, platform.FirewallInfo{}); !strings.Contains(d.Action, "firewall-cmd --permanent --add-port=5432/tcp") {
+		t.Fatalf("firewalld action: %q", d.Action)
+	}
+	v = firewall.Verdict{Action: "accept", Source: "ufw", Rule: "[4] 5432/tcp  ALLOW IN  Anywhere"}
+	if d := firewallDiagnostic(ps[0], v, platform.FirewallInfo{}); d.Severity != "info" || d.Action != "" {
+		t.Fatalf("accept: %+v", d)
+	}
+	v = firewall.Verdict{Action: "unknown", Source: "ufw", Rule: "default deny (incoming)", Policy: true}
+	if d := firewallDiagnostic(ps[0], v, platform.FirewallInfo{}); d.Severity != "info" || !strings.Contains(d.Summary, "unknown") {
+		t.Fatalf("unknown: %+v", d)
+	}
+}
+
+func TestDiagnoseProtocol(t *testing.T) {
+	web := &model.Fingerprint{Addr: "127.0.0.1:5432", Protocol: "http", Status: 200, Server: "vite"}
+	rep := model.Report{Port: 5432, Proto: "tcp", Listeners: []model.Listener{
+		{LocalIP: "0.0.0.0", LocalPort: 5432, State: "LISTEN", PID: 41, ProcName: "node", Probe: web},
+		{LocalIP: "::", LocalPort: 5432, State: "LISTEN", PID: 41, ProcName: "node", Probe: web},
+	}}
+	ds := diagProtocol(rep)
+	if len(ds) != 1 || ds[0].Severity != "warn" || ds[0].Summary != "Port 5432 serves HTTP, not PostgreSQL" {
+		t.Fatalf("mismatch: %+v", ds)
+	}
+
+	rep.Listeners = rep.Listeners[:1]
+	rep.Listeners[0].Probe = &model.Fingerprint{Addr: "127.0.0.1:5432", Protocol: "postgres", Detail: "TLS available"}
+	if ds := diagProtocol(rep); len(ds) != 1 || ds[0].Severity != "info" || ds[0].Summary != "Port serves postgres (TLS available)" {
+		t.Fatalf("expected protocol: %+v", ds)
+	}
+
+	rep.Listeners[0].Probe = &model.Fingerprint{Addr: "127.0.0.1:5432", Error: "connection refused"}
+	if ds := diagProtocol(rep); len(ds) != 1 || ds[0].Severity != "warn" || !strings.Contains(ds[0].Details, "connection refused") {
+		t.Fatalf("probe failure: %+v", ds)
+	}
+}
@@ -0,0 +1,146 @@
+package inspect
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+)
+
+// DiagnoseUnix produces hints for a Unix socket report. Port-oriented checks
+// (privileged ports, address families, firewalls) do not apply.
+func DiagnoseUnix(rep model.Report) []model.Diagnostic {
+	var out []model.Diagnostic
+
+	if l, ok := rep.PrimaryListener(); ok && l.PID > 0 && l.State == "LISTEN" {
+		out = append(out, model.Diagnostic{
+			Kind:     "in-use",
+			Severity: "info",
+			Summary:  "Socket is in use",
+			Details:  fmt.Sprintf("pid %d (%s) is listening on %s", l.PID, l.ProcName, rep.Path),
+			Action:   "Stop it: portik kill --proto unix <path>",
+		})
+	}
+
+	pidCount := 0
+	for _, l := range rep.Listeners {
+		if l.PID > 0 {
+			pidCount++
+		}
+	}
+	if len(rep.Listeners) > 0 && pidCount == 0 {
+		out = append(out, model.Diagnostic{
+			Kind:     "pid-missing",
+			Severity: "warn",
+			Summary:  "Process details unavailable",
+			Details:  "The socket has a listener but no owning PID was found. This can happen without elevated privileges.",
+			Action:   "Re-run with sudo/admin, or check OS-specific permissions.",
+		})
+	}
+
+	for _, l := range rep.Listeners {
+		if d, ok := backlogDiagnostic(l); ok {
+			out = append(out, d)
+			break
+		}
+	}
+
+	for _, l := range rep.Listeners {
+		if l.IsZombie {
+			out = append(out, model.Diagnostic{
+				Kind:     "zombie",
+				Severity: "warn",
+				Summary:  "Zombie process detected owning the socket",
+				Details:  fmt.Sprintf("pid %d (%s) appears to be a zombie. Parent process must reap it.", l.PID, l.ProcName),
+				Action:   "Restart the parent process, or reboot if the zombie cannot be reaped.",
+			})
+			break
+		}
+	}
+
+	// abstract sockets have no file
+	if strings.HasPrefix(rep.Path, "@") {
+		return model.DedupeDiagnostics(out)
+	}
+	fi, err := platform.Stat(rep.Path)
+	switch {
+	case err != nil && len(rep.Listeners) == 0:
+		out = append(out, model.Diagnostic{
+			Kind:     "socket-file",
+			Severity: "info",
+			Summary:  "Socket file does not exist",
+			Details:  fmt.Sprintf("Nothing is bound to %s.", rep.Path),
+			Action:   "Check that the service is running and configured with this socket path.",
+		})
+	case err != nil:
+		out = append(out, model.Diagnostic{
+			Kind:     "socket-file",
+			Severity: "warn",
+			Summary:  "Socket file was removed while the listener is still running",
+			Details:  fmt.Sprintf("%s no longer exists, so new clients cannot connect even though the socket is open.", rep.Path),
+			Action:   "Restart the service to recreate the socket file.",
+		})
+	case fi.Mode()&fs.ModeSocket == 0:
+		out = append(out, model.Diagnostic{
+			Kind:     "socket-file",
+			Severity: "warn",
+			Summary:  "Path exists but is not a socket",
+			Details:  fmt.Sprintf("%s is a %s; connect() will fail.", rep.Path, fileKind(fi)),
+			Action:   "Check the configured socket path.",
+		})
+	case len(rep.Listeners) == 0:
+		out = append(out, model.Diagnostic{
+			Kind:     "stale-socket",
+			Severity: "warn",
+			Summary:  "Stale socket file (no process is listening)",
+			Details:  "Clients get ECONNREFUSED, and a server binding this path gets EADDRINUSE until the file is removed.",
+			Action:   fmt.Sprintf("If the service is stopped, remove it: rm %s", rep.Path),
+		})
+	case !platform.Replaying() && !canWrite(fi):
+		out = append(out, model.Diagnostic{
+			Kind:     "permission",
+			Severity: "warn",
+			Summary:  "Current user cannot connect to the socket",
+			Details:  fmt.Sprintf("connect() needs write permission on %s (mode %s).", rep.Path, fi.Mode().Perm()),
+			Action:   "Run as the socket's owner, join its group, or adjust the socket permissions.",
+		})
+	}
+
+	return model.DedupeDiagnostics(out)
+}
+
+// canWrite applies the owner/group/other write bits for the current user.
+func canWrite(fi os.FileInfo) bool {
+	uid, gid, ok := platform.FileOwner(fi)
+	if !ok {
+		return true
+	}
+	me := os.Geteuid()
+	perm := fi.Mode().Perm()
+	switch {
+	case me == 0:
+		return true
+	case me == uid:
+		return perm&0o200 != 0
+	}
+	groups, _ := os.Getgroups()
+	if os.Getegid() == gid || slices.Contains(groups, gid) {
+		return perm&0o020 != 0
+	}
+	return perm&0o002 != 0
+}
+
+func fileKind(fi os.FileInfo) string {
+	switch {
+	case fi.Mode().IsRegular():
+		return "regular file"
+	case fi.IsDir():
+		return "directory"
+	default:
+		return "file of type " + fi.Mode().Type().String()
+	}
+}
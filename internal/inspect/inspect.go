@@ -3,11 +3,13 @@ package inspect
 import (
 	"fmt"
 	"os/user"
-	"time"
+	"strings"
 
 	"github.com/pratik-anurag/portik/internal/docker"
+	"github.com/pratik-anurag/portik/internal/forwarder"
 	"github.com/pratik-anurag/portik/internal/model"
 	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/probe"
 	"github.com/pratik-anurag/portik/internal/proc"
 	"github.com/pratik-anurag/portik/internal/sockets"
 )
@@ -15,20 +17,56 @@ import (
 type Options struct {
 	EnableDocker       bool
 	IncludeConnections bool
+
+	// Bind, when set, tries that bind on the port after listing its
+	// sockets (explain --bind). It always touches the local host.
+	Bind *BindRequest
+	// Exe is the program that will bind the port (explain --exe); its file
+	// capabilities count in the privileged-port check instead of those of
+	// the process holding the port.
+	Exe string
+	// Probe connects to each TCP listener and fingerprints the protocol it
+	// serves (--probe).
+	Probe bool
+
+	// Where data comes from; nil means the live system.
+	Sockets   sockets.Source
+	Processes proc.Source
+	Docker    docker.Source
+}
+
+func (o Options) sources() (sockets.Source, proc.Source, docker.Source) {
+	s, p, d := o.Sockets, o.Processes, o.Docker
+	if s == nil {
+		s = sockets.Live
+	}
+	if p == nil {
+		p = proc.Live
+	}
+	if d == nil {
+		d = docker.Live
+	}
+	return s, p, d
 }
 
 func InspectPort(port int, proto string, opt Options) (model.Report, error) {
 	if proto != "tcp" && proto != "udp" {
 		return model.Report{}, fmt.Errorf("unsupported proto: %s", proto)
 	}
+	if opt.Bind != nil && platform.Replaying() {
+		return model.Report{}, fmt.Errorf("bind test needs the live host, not a snapshot")
+	}
+	if opt.Probe && platform.Replaying() {
+		return model.Report{}, fmt.Errorf("probing needs the live host, not a snapshot")
+	}
 
-	u, _ := user.Current()
+	u, _ := platform.CurrentUser()
 	hs := platform.HostSummary()
 
 	rep := model.Report{
 		Port:      port,
 		Proto:     proto,
-		Generated: time.Now(),
+		Generated: platform.Now(),
 		Host: model.HostSummary{
 			OS:       hs.OS,
 			Arch:     hs.Arch,
@@ -38,31 +76,121 @@ func InspectPort(port int, proto string, opt Options) (model.Report, error) {
 		User: model.UserSummary{Username: safeUsername(u)},
 	}
 
-	listeners, conns, err := sockets.Inspect(port, proto, opt.IncludeConnections)
+	socks, procs, dock := opt.sources()
+	listeners, conns, err := sockets.InspectFrom(socks, port, proto, opt.IncludeConnections)
 	if err != nil {
 		return model.Report{}, err
 	}
 	for i := range listeners {
-		proc.Enrich(&listeners[i])
+		proc.EnrichFrom(procs, &listeners[i])
 	}
 	for i := range conns {
-		proc.EnrichConn(&conns[i])
+		proc.EnrichConnFrom(procs, &conns[i])
+	}
+	detectTunnels(socks, listeners)
+	if opt.Probe && proto == "tcp" {
+		probeListeners(listeners)
 	}
 
 	rep.Listeners = listeners
 	rep.Connections = conns
 
 	if opt.EnableDocker {
-		rep.Docker = docker.MapPort(port, proto)
+		rep.Docker = dock.MapPort(port, proto)
+	}
+	var holder int32
+	if l, ok := rep.PrimaryListener(); ok {
+		holder = l.PID
+	}
+	if pc, ok := CheckPrivilege(port, holder, opt.Exe); ok {
+		rep.Privilege = &pc
+	}
+	if opt.Bind != nil {
+		bc := TryBind(port, proto, *opt.Bind)
+		rep.Bind = &bc
 	}
 
 	rep.Diagnostics = Diagnose(rep)
 	return rep, nil
 }
 
+// InspectUnix builds a report for the Unix socket bound to path.
+func InspectUnix(path string, opt Options) (model.Report, error) {
+	u, _ := platform.CurrentUser()
+	hs := platform.HostSummary()
+
+	rep := model.Report{
+		Proto:     "unix",
+		Path:      path,
+		Generated: platform.Now(),
+		Host: model.HostSummary{
+			OS:       hs.OS,
+			Arch:     hs.Arch,
+			Hostname: hs.Hostname,
+			Kernel:   hs.Kernel,
+		},
+		User: model.UserSummary{Username: safeUsername(u)},
+	}
+
+	socks, procs, _ := opt.sources()
+	listeners, conns, err := sockets.InspectUnixFrom(socks, path, opt.IncludeConnections)
+	if err != nil {
+		return model.Report{}, err
+	}
+	for i := range listeners {
+		proc.EnrichFrom(procs, &listeners[i])
+	}
+	for i := range conns {
+		proc.EnrichConnFrom(procs, &conns[i])
+	}
+	rep.Listeners = listeners
+	rep.Connections = conns
+
+	rep.Diagnostics = DiagnoseUnix(rep)
+	return rep, nil
+}
+
 func safeUsername(u *user.User) string {
 	if u == nil {
 		return ""
 	}
 	return u.Username
 }
+
+// detectTunnels marks listeners held by forwarders such as ssh -L and
+// kubectl port-forward, with the upstream connection they hold.
+func detectTunnels(socks sockets.Source, listeners []model.Listener) {
+	var all []model.Conn
+	listed := false
+	for i := range listeners {
+		t, ok := forwarder.Detect(listeners[i])
+		if !ok {
+			continue
+		}
+		if !listed {
+			all, _ = socks.ListConnections("tcp")
+			listed = true
+		}
+		t.Peer = forwarder.Peer(listeners[i], all)
+		listeners[i].Tunnel = &t
+	}
+}
+
+// probeListeners fingerprints each listening socket. Sockets of one process
+// (0.0.0.0 and :: of the same server) are probed once.
+func probeListeners(listeners []model.Listener) {
+	done := map[int32]*model.Fingerprint{}
+	for i := range listeners {
+		l := &listeners[i]
+		if !strings.EqualFold(l.State, "LISTEN") {
+			continue
+		}
+		if fp, ok := done[l.PID]; ok && l.PID > 0 {
+			l.Probe = fp
+			continue
+		}
+		fp := probe.Listener(*l, probe.DefaultTimeout)
+		l.Probe = &fp
+		done[l.PID] = &fp
+	}
+}
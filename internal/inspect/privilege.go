@@ -0,0 +1,170 @@
+package inspect
+
+import (
+	"fmt"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/proc"
+)
+
+// CheckPrivilege decides whether port may be bound. With a listener pid and
+// no exe, it reads that process's uid and capabilities and the file
+// capabilities of the binary it runs. Otherwise it asks whether a program
+// started by the current user could bind it; exe, when set, is that program
+// and its file capabilities count. ok is false for ports that need no
+// privilege anywhere (>= 1024 and at or above the host's unprivileged start).
+func CheckPrivilege(port int, pid int32, exe string) (model.PrivilegeCheck, bool) {
+	hostOS := platform.HostSummary().OS
+	start := 1024
+	if hostOS == "linux" {
+		if v, ok := platform.SysctlInts("net.ipv4.ip_unprivileged_port_start"); ok {
+			start = v[0]
+		}
+	}
+	if port >= 1024 && port >= start {
+		return model.PrivilegeCheck{}, false
+	}
+
+	pc := model.PrivilegeCheck{
+		UnprivilegedStart: start,
+		Privileged:        port < start,
+		Exe:               exe,
+	}
+	var priv platform.Privileges
+	var fc platform.FileCaps
+	var ok, fcOK bool
+	if exe == "" {
+		priv, ok = platform.ProcessPrivileges(pid)
+	}
+	if ok {
+		pc.PID = pid
+		if info, found := proc.Lookup(pid); found {
+			pc.Exe, _ = info.ExePath()
+		}
+		fc, fcOK = platform.ProcessExecutableCaps(pid)
+	} else {
+		priv = platform.Self()
+		if exe != "" {
+			fc, fcOK = platform.ExecutableCaps(exe)
+		}
+	}
+	pc.UID = priv.UID
+	pc.CapNetBindService = priv.Has(platform.CapNetBindService)
+	pc.CapsKnown = priv.Known
+	pc.UserNS = priv.UserNS
+	if fcOK && fc.Has(platform.CapNetBindService) {
+		pc.ExeCaps = "cap_net_bind_service=p"
+		if fc.Effective {
+			pc.ExeCaps = "cap_net_bind_service=ep"
+		}
+	}
+	decidePrivilege(&pc, hostOS, port)
+	return pc, true
+}
+
+// decidePrivilege fills Allowed and Reason. Linux lets a process bind below
+// ip_unprivileged_port_start only with CAP_NET_BIND_SERVICE, which root has
+// and other users get from file capabilities (setcap) or ambient sets.
+func decidePrivilege(pc *model.PrivilegeCheck, hostOS string, port int) {
+	switch {
+	case !pc.Privileged:
+		pc.Allowed = true
+		pc.Reason = fmt.Sprintf("port %d is at or above net.ipv4.ip_unprivileged_port_start=%d", port, pc.UnprivilegedStart)
+	case hostOS != "linux":
+		pc.Allowed = pc.UID == 0
+		pc.Reason = fmt.Sprintf("port %d is below 1024, which usually needs root on %s", port, hostOS)
+	case pc.CapNetBindService, !pc.CapsKnown && pc.UID == 0:
+		pc.Allowed = true
+		pc.Reason = "the process has CAP_NET_BIND_SERVICE"
+		switch {
+		case pc.PID > 0 && pc.UID == 0:
+			pc.Reason = fmt.Sprintf("pid %d runs as root", pc.PID)
+		case pc.PID > 0:
+			pc.Reason = fmt.Sprintf("pid %d has CAP_NET_BIND_SERVICE", pc.PID)
+		case pc.UID == 0:
+			pc.Reason = "running as root"
+		}
+		if pc.UserNS {
+			pc.Reason += " inside a user namespace, which covers this container's network only"
+		}
+	case pc.ExeCaps == "cap_net_bind_service=ep":
+		pc.Allowed = true
+		pc.Reason = fmt.Sprintf("%s carries cap_net_bind_service=ep", pc.Exe)
+	default:
+		pc.Reason = fmt.Sprintf("ports below %d need CAP_NET_BIND_SERVICE and uid %d does not have it", pc.UnprivilegedStart, pc.UID)
+		if pc.PID > 0 {
+			pc.Reason = fmt.Sprintf("ports below %d need CAP_NET_BIND_SERVICE and pid %d (uid %d) does not have it; it got the socket from a privileged parent (systemd, a root master process) or dropped the capability after binding, so it cannot bind the port again on its own",
+				pc.UnprivilegedStart, pc.PID, pc.UID)
+		}
+		if pc.ExeCaps != "" {
+			pc.Reason += fmt.Sprintf("; %s has it permitted but not effective, so the program must raise it itself", pc.Exe)
+		}
+	}
+}
+
+// privilegeDiagnostics turns a PrivilegeCheck into hints. Root binding a
+// privileged port on the host is the normal case and stays quiet.
+func privilegeDiagnostics(pc model.PrivilegeCheck, rep model.Report, hostOS string) []model.Diagnostic {
+	var out []model.Diagnostic
+	switch {
+	case !pc.Privileged:
+		out = append(out, model.Diagnostic{
+			Kind:     "permission",
+			Severity: "info",
+			Summary:  fmt.Sprintf("Port %d needs no privilege on this host", rep.Port),
+			Details:  capitalize(pc.Reason) + ".",
+		})
+	case pc.Allowed && pc.UID == 0 && !pc.UserNS:
+	case pc.Allowed:
+		out = append(out, model.Diagnostic{
+			Kind:     "permission",
+			Severity: "info",
+			Summary:  fmt.Sprintf("Binding port %d is allowed", rep.Port),
+			Details:  capitalize(pc.Reason) + ".",
+		})
+	case hostOS != "linux":
+		out = append(out, model.Diagnostic{
+			Kind:     "permission",
+			Severity: "info",
+			Summary:  "Privileged port may require admin/root",
+			Details:  fmt.Sprintf("Port %d is < 1024. On many systems binding requires root/admin privileges.", rep.Port),
+			Action:   "Try running with sudo or choose a higher port.",
+		})
+	default:
+		sev := "info"
+		if len(rep.Listeners) == 0 {
+			sev = "warn"
+		}
+		exe := pc.Exe
+		if exe == "" {
+			exe = "<binary>"
+		}
+		out = append(out, model.Diagnostic{
+			Kind:     "permission",
+			Severity: sev,
+			Summary:  fmt.Sprintf("Binding port %d needs CAP_NET_BIND_SERVICE", rep.Port),
+			Details:  capitalize(pc.Reason) + ".",
+			Action: fmt.Sprintf("Grant it: sudo setcap 'cap_net_bind_service=+ep' %s  |  Lower the limit: sudo sysctl -w net.ipv4.ip_unprivileged_port_start=%d  |  Or wrap the command: authbind --deep <cmd>",
+				exe, rep.Port),
+		})
+	}
+
+	if pc.UserNS && rep.Port < 1024 {
+		out = append(out, model.Diagnostic{
+			Kind:     "permission",
+			Severity: "info",
+			Summary:  "Rootless container: host ports below 1024 need the host's sysctl",
+			Details:  fmt.Sprintf("portik runs in a user namespace, so root here is not root on the host. Port %d can be bound inside the container, but publishing it on the host is done by the unprivileged host user (rootlesskit, pasta or slirp4netns).", rep.Port),
+			Action:   fmt.Sprintf("On the host: sudo sysctl -w net.ipv4.ip_unprivileged_port_start=%d, or publish a port >= 1024.", rep.Port),
+		})
+	}
+	return out
+}
+
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
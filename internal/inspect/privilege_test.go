@@ -0,0 +1,58 @@
+package inspect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func TestDecidePrivilege(t *testing.T) {
+	cases := []struct {
+		name    string
+		pc      model.PrivilegeCheck
+		allowed bool
+		reason  string
+	}{
+		{"sysctl lowered", model.PrivilegeCheck{UnprivilegedStart: 80, UID: 1000, CapsKnown: true}, true, "ip_unprivileged_port_start=80"},
+		{"root", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 0, CapsKnown: true, CapNetBindService: true}, true, "root"},
+		{"ambient cap", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 1000, CapsKnown: true, CapNetBindService: true}, true, "has CAP_NET_BIND_SERVICE"},
+		{"setcap ep", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 1000, CapsKnown: true, Exe: "/usr/bin/app", ExeCaps: "cap_net_bind_service=ep"}, true, "/usr/bin/app carries"},
+		{"setcap p", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 1000, CapsKnown: true, Exe: "/usr/bin/app", ExeCaps: "cap_net_bind_service=p"}, false, "must raise it itself"},
+		{"plain user", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 1000, CapsKnown: true}, false, "uid 1000 does not have it"},
+		{"listener as root", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, PID: 42, UID: 0, CapsKnown: true, CapNetBindService: true}, true, "pid 42 runs as root"},
+		{"listener dropped caps", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, PID: 42, UID: 33, CapsKnown: true}, false, "pid 42 (uid 33) does not have it"},
+		{"root without caps", model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 0, CapsKnown: true}, false, "uid 0"},
+	}
+	for _, c := range cases {
+		pc := c.pc
+		decidePrivilege(&pc, "linux", 80)
+		if pc.Allowed != c.allowed || !strings.Contains(pc.Reason, c.reason) {
+			t.Errorf("%s: allowed=%v reason=%q, want allowed=%v reason containing %q", c.name, pc.Allowed, pc.Reason, c.allowed, c.reason)
+		}
+	}
+}
+
+func TestPrivilegeDiagnosticsFixes(t *testing.T) {
+	pc := model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 1000, CapsKnown: true, Exe: "/usr/bin/app"}
+	decidePrivilege(&pc, "linux", 80)
+	d := privilegeDiagnostics(pc, model.Report{Port: 80}, "linux")
+	if len(d) != 1 || d[0].Severity != "warn" {
+		t.Fatalf("unexpected diagnostics: %+v", d)
+	}
+	for _, fix := range []string{"setcap 'cap_net_bind_service=+ep' /usr/bin/app", "ip_unprivileged_port_start=80", "authbind"} {
+		if !strings.Contains(d[0].Action, fix) {
+			t.Errorf("action %q lacks %q", d[0].Action, fix)
+		}
+	}
+
+	root := model.PrivilegeCheck{UnprivilegedStart: 1024, Privileged: true, UID: 0, CapsKnown: true, CapNetBindService: true}
+	decidePrivilege(&root, "linux", 80)
+	if d := privilegeDiagnostics(root, model.Report{Port: 80}, "linux"); len(d) != 0 {
+		t.Fatalf("root on the host should stay quiet: %+v", d)
+	}
+	root.UserNS = true
+	if d := privilegeDiagnostics(root, model.Report{Port: 80}, "linux"); len(d) != 2 || !strings.Contains(d[1].Summary, "Rootless") {
+		t.Fatalf("expected rootless container hint: %+v", d)
+	}
+}
@@ -0,0 +1,178 @@
+package inspect
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// Diagnoser is one diagnostic rule: it looks at a report and returns zero or
+// more hints. IDs name rules in config files and in JSON output.
+type Diagnoser interface {
+	ID() string
+	Diagnose(rep model.Report) []model.Diagnostic
+}
+
+type ruleFunc struct {
+	id string
+	fn func(model.Report) []model.Diagnostic
+}
+
+func (r ruleFunc) ID() string                                   { return r.id }
+func (r ruleFunc) Diagnose(rep model.Report) []model.Diagnostic { return r.fn(rep) }
+
+// Rule turns a function into a Diagnoser.
+func Rule(id string, fn func(model.Report) []model.Diagnostic) Diagnoser {
+	return ruleFunc{id: id, fn: fn}
+}
+
+// RuleInfo describes a registered rule.
+type RuleInfo struct {
+	ID       string `json:"id"`
+	Enabled  bool   `json:"enabled"`
+	Severity string `json:"severity,omitempty"` // override, if any
+	Source   string `json:"source"`             // "builtin" or the rules file
+}
+
+// Registry runs an ordered set of rules with per-rule enable/disable and
+// severity overrides. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	rules    []Diagnoser
+	sources  map[string]string
+	disabled map[string]bool
+	severity map[string]string
+}
+
+// Default holds the built-in rules; Diagnose uses it.
+var Default = NewRegistry()
+
+// NewRegistry returns a registry with the built-in rules.
+func NewRegistry() *Registry {
+	r := &Registry{
+		sources:  map[string]string{},
+		disabled: map[string]bool{},
+		severity: map[string]string{},
+	}
+	for _, d := range builtinRules() {
+		_ = r.add(d, "builtin")
+	}
+	return r
+}
+
+// Register appends a rule. IDs must be unique.
+func (r *Registry) Register(d Diagnoser) error {
+	return r.add(d, "api")
+}
+
+func (r *Registry) add(d Diagnoser, source string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := d.ID()
+	if id == "" {
+		return fmt.Errorf("rule has no id")
+	}
+	if _, dup := r.sources[id]; dup {
+		return fmt.Errorf("rule %q is already registered", id)
+	}
+	r.rules = append(r.rules, d)
+	r.sources[id] = source
+	return nil
+}
+
+// clone copies r's rules and settings; the caller holds r.mu.
+func (r *Registry) clone() *Registry {
+	n := &Registry{
+		rules:    append([]Diagnoser(nil), r.rules...),
+		sources:  make(map[string]string, len(r.sources)),
+		disabled: make(map[string]bool, len(r.disabled)),
+		severity: make(map[string]string, len(r.severity)),
+	}
+	for id, s := range r.sources {
+		n.sources[id] = s
+	}
+	for id, off := range r.disabled {
+		n.disabled[id] = off
+	}
+	for id, sev := range r.severity {
+		n.severity[id] = sev
+	}
+	return n
+}
+
+// SetEnabled turns a rule on or off.
+func (r *Registry) SetEnabled(id string, on bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sources[id]; !ok {
+		return fmt.Errorf("unknown rule %q", id)
+	}
+	r.disabled[id] = !on
+	return nil
+}
+
+// SetSeverity overrides the severity of every hint a rule emits. An empty
+// severity removes the override.
+func (r *Registry) SetSeverity(id, sev string) error {
+	if sev != "" && !validSeverity(sev) {
+		return fmt.Errorf("rule %q: invalid severity %q (want info, warn or error)", id, sev)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sources[id]; !ok {
+		return fmt.Errorf("unknown rule %q", id)
+	}
+	if sev == "" {
+		delete(r.severity, id)
+	} else {
+		r.severity[id] = sev
+	}
+	return nil
+}
+
+// Rules lists the registered rules in run order.
+func (r *Registry) Rules() []RuleInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]RuleInfo, 0, len(r.rules))
+	for _, d := range r.rules {
+		id := d.ID()
+		out = append(out, RuleInfo{ID: id, Enabled: !r.disabled[id], Severity: r.severity[id], Source: r.sources[id]})
+	}
+	return out
+}
+
+// Diagnose runs every enabled rule over rep. Hints are stamped with their
+// rule ID and severity overrides are applied.
+func (r *Registry) Diagnose(rep model.Report) []model.Diagnostic {
+	r.mu.RLock()
+	rules := make([]Diagnoser, 0, len(r.rules))
+	for _, d := range r.rules {
+		if !r.disabled[d.ID()] {
+			rules = append(rules, d)
+		}
+	}
+	severity := make(map[string]string, len(r.severity))
+	for id, sev := range r.severity {
+		severity[id] = sev
+	}
+	r.mu.RUnlock()
+
+	var out []model.Diagnostic
+	for _, d := range rules {
+		id := d.ID()
+		for _, diag := range d.Diagnose(rep) {
+			diag.Rule = id
+			if sev := severity[id]; sev != "" {
+				diag.Severity = sev
+			}
+			out = append(out, diag)
+		}
+	}
+	return model.DedupeDiagnostics(out)
+}
+
+func validSeverity(s string) bool {
+	return s == "info" || s == "warn" || s == "error"
+}
@@ -0,0 +1,124 @@
+package inspect
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func loopbackReport(port int, proc string) model.Report {
+	return model.Report{
+		Port:  port,
+		Proto: "tcp",
+		Listeners: []model.Listener{
+			{Family: "ipv4", LocalIP: "127.0.0.1", LocalPort: port, State: "LISTEN", PID: 10, ProcName: proc},
+		},
+	}
+}
+
+func findRule(ds []model.Diagnostic, id string) (model.Diagnostic, bool) {
+	for _, d := range ds {
+		if d.Rule == id {
+			return d, true
+		}
+	}
+	return model.Diagnostic{}, false
+}
+
+func TestRegistryOverrides(t *testing.T) {
+	r := NewRegistry()
+	rep := loopbackReport(8080, "x")
+	if d, ok := findRule(r.Diagnose(rep), "loopback-only"); !ok || d.Severity != "info" {
+		t.Fatalf("built-in loopback-only: %+v ok=%v", d, ok)
+	}
+	if err := r.SetSeverity("loopback-only", "error"); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := findRule(r.Diagnose(rep), "loopback-only"); d.Severity != "error" {
+		t.Fatalf("severity override not applied: %+v", d)
+	}
+	if err := r.SetEnabled("loopback-only", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findRule(r.Diagnose(rep), "loopback-only"); ok {
+		t.Fatalf("disabled rule still ran")
+	}
+	if err := r.SetEnabled("no-such-rule", false); err == nil {
+		t.Fatalf("unknown rule accepted")
+	}
+	if err := r.Register(Rule("in-use", diagInUse)); err == nil {
+		t.Fatalf("duplicate id accepted")
+	}
+}
+
+func TestRulesFile(t *testing.T) {
+	f, err := LoadRules(filepath.Join("testdata", "rules.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry()
+	if err := r.Apply(f, "rules.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	ds := r.Diagnose(loopbackReport(8443, "gateway"))
+	d, ok := findRule(ds, "gateway-not-loopback")
+	if !ok {
+		t.Fatalf("user rule did not fire: %+v", ds)
+	}
+	if d.Kind != "custom" || d.Severity != "error" || d.Summary != "Our gateway must never be loopback-only (gateway pid 10)" {
+		t.Fatalf("unexpected diagnostic: %+v", d)
+	}
+	if d, _ := findRule(ds, "loopback-only"); d.Severity != "error" {
+		t.Fatalf("file severity override not applied: %+v", d)
+	}
+	if _, ok := findRule(ds, "vm"); ok {
+		t.Fatalf("vm rule should be disabled")
+	}
+
+	for _, rep := range []model.Report{loopbackReport(8443, "nginx"), loopbackReport(9000, "gateway")} {
+		if _, ok := findRule(r.Diagnose(rep), "gateway-not-loopback"); ok {
+			t.Fatalf("user rule fired on %d/%s", rep.Port, rep.Listeners[0].ProcName)
+		}
+	}
+}
+
+func TestRulesFileAllOrNothing(t *testing.T) {
+	r := NewRegistry()
+	before := r.Rules()
+	f := RulesFile{
+		Disable: []string{"no-such-rule"},
+		Rules:   []UserRule{{ID: "gateway-not-loopback", Diagnostic: model.Diagnostic{Summary: "s"}}},
+	}
+	if err := r.Apply(f, "rules.json"); err == nil {
+		t.Fatal("unknown rule in disable accepted")
+	}
+	f = RulesFile{
+		Severity: map[string]string{"loopback-only": "fatal"},
+		Disable:  []string{"vm"},
+	}
+	if err := r.Apply(f, "rules.json"); err == nil {
+		t.Fatal("invalid severity accepted")
+	}
+	if after := r.Rules(); !reflect.DeepEqual(after, before) {
+		t.Fatalf("failed Apply changed the registry:\n%+v\nwant %+v", after, before)
+	}
+}
+
+func TestCompileRuleRejectsBadInput(t *testing.T) {
+	bad := []UserRule{
+		{Diagnostic: model.Diagnostic{Summary: "no id"}},
+		{ID: "x"},
+		{ID: "x", Match: RuleMatch{Ports: "abc"}, Diagnostic: model.Diagnostic{Summary: "s"}},
+		{ID: "x", Match: RuleMatch{Address: "nope"}, Diagnostic: model.Diagnostic{Summary: "s"}},
+		{ID: "x", Match: RuleMatch{Process: "["}, Diagnostic: model.Diagnostic{Summary: "s"}},
+		{ID: "x", Diagnostic: model.Diagnostic{Summary: "s", Severity: "fatal"}},
+	}
+	for _, ur := range bad {
+		if _, err := compileRule(ur); err == nil {
+			t.Errorf("compileRule(%+v) accepted", ur)
+		}
+	}
+}
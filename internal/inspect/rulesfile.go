@@ -0,0 +1,237 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/ports"
+)
+
+// RulesFile is the declarative rules config, e.g. ~/.portik/rules.json:
+//
+//	{
+//	  "disable": ["vm"],
+//	  "severity": {"loopback-only": "error"},
+//	  "rules": [{
+//	    "id": "gateway-not-loopback",
+//	    "match": {"process": "gateway", "address": "loopback"},
+//	    "diagnostic": {"severity": "error", "summary": "Our gateway must never be loopback-only",
+//	                   "action": "Set LISTEN_ADDR=0.0.0.0 in the gateway config"}
+//	  }]
+//	}
+type RulesFile struct {
+	Disable  []string          `json:"disable,omitempty"`
+	Severity map[string]string `json:"severity,omitempty"`
+	Rules    []UserRule        `json:"rules,omitempty"`
+}
+
+// UserRule emits Diagnostic when Match holds. Summary, details and action
+// may use {port}, {proto}, {pid}, {process}, {user}, {address} and
+// {service}, filled from the matching listener.
+type UserRule struct {
+	ID         string           `json:"id"`
+	Match      RuleMatch        `json:"match"`
+	Diagnostic model.Diagnostic `json:"diagnostic"`
+}
+
+// RuleMatch holds the conditions of a user rule; every field that is set
+// must hold. Process and Address must hold for the same listener.
+type RuleMatch struct {
+	Ports         string `json:"ports,omitempty"`          // "8443" or "8000-8099,9000"
+	Proto         string `json:"proto,omitempty"`          // tcp|udp
+	Process       string `json:"process,omitempty"`        // glob on the process name
+	Address       string `json:"address,omitempty"`        // IP, CIDR, "loopback" or "wildcard"
+	DockerService string `json:"docker_service,omitempty"` // glob on the compose service (needs --docker)
+	Listening     *bool  `json:"listening,omitempty"`      // whether the port has a listener at all
+}
+
+// LoadRules reads a rules file. Unknown fields are rejected so typos do not
+// silently disable a rule.
+func LoadRules(file string) (RulesFile, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return RulesFile{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var f RulesFile
+	if err := dec.Decode(&f); err != nil {
+		return RulesFile{}, fmt.Errorf("%s: %w", file, err)
+	}
+	return f, nil
+}
+
+// Apply registers the file's rules, then applies its disable list and
+// severity overrides, which may name built-in or file rules. The file is
+// applied to a copy of r that replaces it only if every entry is valid, so
+// a bad file leaves r as it was.
+func (r *Registry) Apply(f RulesFile, source string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.clone()
+	for _, ur := range f.Rules {
+		d, err := compileRule(ur)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if err := n.add(d, source); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+	}
+	for _, id := range f.Disable {
+		if err := n.SetEnabled(id, false); err != nil {
+			return fmt.Errorf("%s: disable: %w", source, err)
+		}
+	}
+	for id, sev := range f.Severity {
+		if err := n.SetSeverity(id, sev); err != nil {
+			return fmt.Errorf("%s: severity: %w", source, err)
+		}
+	}
+	r.rules, r.sources, r.disabled, r.severity = n.rules, n.sources, n.disabled, n.severity
+	return nil
+}
+
+type userRule struct {
+	UserRule
+	ports map[int]bool
+	ip    net.IP
+	cidr  *net.IPNet
+}
+
+func compileRule(ur UserRule) (*userRule, error) {
+	if ur.ID == "" {
+		return nil, fmt.Errorf("rule without id")
+	}
+	if ur.Diagnostic.Summary == "" {
+		return nil, fmt.Errorf("rule %q: diagnostic needs a summary", ur.ID)
+	}
+	if ur.Diagnostic.Kind == "" {
+		ur.Diagnostic.Kind = "custom"
+	}
+	if ur.Diagnostic.Severity == "" {
+		ur.Diagnostic.Severity = "warn"
+	}
+	if !validSeverity(ur.Diagnostic.Severity) {
+		return nil, fmt.Errorf("rule %q: invalid severity %q", ur.ID, ur.Diagnostic.Severity)
+	}
+	u := &userRule{UserRule: ur}
+	m := ur.Match
+	if m.Ports != "" {
+		ps, err := ports.ParseSpec(m.Ports)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: ports: %w", ur.ID, err)
+		}
+		u.ports = map[int]bool{}
+		for _, p := range ps {
+			u.ports[p] = true
+		}
+	}
+	if m.Proto != "" && m.Proto != "tcp" && m.Proto != "udp" {
+		return nil, fmt.Errorf("rule %q: invalid proto %q", ur.ID, m.Proto)
+	}
+	for _, g := range []string{m.Process, m.DockerService} {
+		if _, err := path.Match(g, ""); err != nil {
+			return nil, fmt.Errorf("rule %q: bad pattern %q", ur.ID, g)
+		}
+	}
+	switch a := m.Address; {
+	case a == "", a == "loopback", a == "wildcard":
+	case strings.Contains(a, "/"):
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: address: %w", ur.ID, err)
+		}
+		u.cidr = n
+	default:
+		if u.ip = net.ParseIP(strings.Trim(a, "[]")); u.ip == nil {
+			return nil, fmt.Errorf("rule %q: invalid address %q (want an IP, CIDR, loopback or wildcard)", ur.ID, a)
+		}
+	}
+	return u, nil
+}
+
+func (u *userRule) ID() string { return u.UserRule.ID }
+
+func (u *userRule) Diagnose(rep model.Report) []model.Diagnostic {
+	m := u.Match
+	if rep.Path != "" {
+		return nil
+	}
+	if u.ports != nil && !u.ports[rep.Port] {
+		return nil
+	}
+	if m.Proto != "" && m.Proto != rep.Proto {
+		return nil
+	}
+	if m.Listening != nil && *m.Listening != (len(rep.Listeners) > 0) {
+		return nil
+	}
+	if m.DockerService != "" {
+		if ok, _ := path.Match(m.DockerService, rep.Docker.ComposeService); !ok || rep.Docker.ComposeService == "" {
+			return nil
+		}
+	}
+
+	var hit model.Listener
+	if m.Process != "" || m.Address != "" {
+		found := false
+		for _, l := range rep.Listeners {
+			if u.matchListener(l) {
+				hit, found = l, true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	} else if l, ok := rep.PrimaryListener(); ok {
+		hit = l
+	}
+
+	repl := strings.NewReplacer(
+		"{port}", strconv.Itoa(rep.Port),
+		"{proto}", rep.Proto,
+		"{pid}", strconv.Itoa(int(hit.PID)),
+		"{process}", hit.ProcName,
+		"{user}", hit.User,
+		"{address}", hit.LocalIP,
+		"{service}", rep.Docker.ComposeService,
+	)
+	d := u.Diagnostic
+	d.Summary = repl.Replace(d.Summary)
+	d.Details = repl.Replace(d.Details)
+	d.Action = repl.Replace(d.Action)
+	return []model.Diagnostic{d}
+}
+
+func (u *userRule) matchListener(l model.Listener) bool {
+	if u.Match.Process != "" {
+		if ok, _ := path.Match(u.Match.Process, l.ProcName); !ok {
+			return false
+		}
+	}
+	switch {
+	case u.Match.Address == "":
+		return true
+	case u.Match.Address == "loopback":
+		return isLoopbackAddr(l.LocalIP)
+	case u.Match.Address == "wildcard":
+		return isAnyAddr(l.LocalIP)
+	}
+	ip := parseListenerIP(l.LocalIP)
+	if ip == nil {
+		return false
+	}
+	if u.cidr != nil {
+		return u.cidr.Contains(ip)
+	}
+	return u.ip.Equal(ip)
+}
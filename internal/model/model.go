@@ -4,20 +4,58 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Report struct {
-	Port        int          `json:"port"`
-	Proto       string       `json:"proto"`
-	Generated   time.Time    `json:"generated"`
-	Host        HostSummary  `json:"host"`
-	User        UserSummary  `json:"user"`
-	Listeners   []Listener   `json:"listeners"`
-	Connections []Conn       `json:"connections,omitempty"`
-	Docker      DockerMap    `json:"docker"`
-	Diagnostics []Diagnostic `json:"diagnostics"`
+	Port        int             `json:"port"`
+	Proto       string          `json:"proto"`
+	Path        string          `json:"path,omitempty"` // proto unix: socket path instead of a port
+	Generated   time.Time       `json:"generated"`
+	Host        HostSummary     `json:"host"`
+	User        UserSummary     `json:"user"`
+	Listeners   []Listener      `json:"listeners"`
+	Connections []Conn          `json:"connections,omitempty"`
+	Docker      DockerMap       `json:"docker"`
+	Bind        *BindCheck      `json:"bind,omitempty"` // explain --bind
+	Privilege   *PrivilegeCheck `json:"privilege,omitempty"`
+	Diagnostics []Diagnostic    `json:"diagnostics"`
+}
+
+// BindCheck is the outcome of actually binding the port with the socket
+// options an application would use.
+type BindCheck struct {
+	Addr      string `json:"addr"`
+	Port      int    `json:"port"`
+	Proto     string `json:"proto"`
+	ReuseAddr bool   `json:"reuseaddr,omitempty"`
+	ReusePort bool   `json:"reuseport,omitempty"`
+	V6Only    bool   `json:"v6only,omitempty"`
+	OK        bool   `json:"ok"`
+	Errno     string `json:"errno,omitempty"` // EADDRINUSE, EACCES, EADDRNOTAVAIL, ...
+	Error     string `json:"error,omitempty"`
+}
+
+// PrivilegeCheck says whether the process holding a port (PID), or else a
+// program started like portik (same user, same capabilities), may bind a
+// port below the unprivileged range. On Linux that range starts at
+// net.ipv4.ip_unprivileged_port_start and binding below it needs
+// CAP_NET_BIND_SERVICE.
+type PrivilegeCheck struct {
+	UnprivilegedStart int    `json:"unprivileged_port_start"` // 1024 when the sysctl is unavailable
+	Privileged        bool   `json:"privileged"`              // port < UnprivilegedStart
+	PID               int32  `json:"pid,omitempty"`           // the listener checked; 0 for portik itself
+	UID               int    `json:"uid"`
+	CapNetBindService bool   `json:"cap_net_bind_service"` // in the effective set
+	CapsKnown         bool   `json:"caps_known"`
+	UserNS            bool   `json:"user_ns,omitempty"`  // rootless container
+	Exe               string `json:"exe,omitempty"`      // explain --exe, or the listener's binary
+	ExeCaps           string `json:"exe_caps,omitempty"` // "cap_net_bind_service=ep" or "=p"
+	Allowed           bool   `json:"allowed"`
+	Reason            string `json:"reason"`
 }
 
 type HostSummary struct {
@@ -34,8 +72,9 @@ type UserSummary struct {
 type Listener struct {
 	LocalIP   string `json:"local_ip"`
 	LocalPort int    `json:"local_port"`
-	Family    string `json:"family"` // ipv4|ipv6|unknown
-	State     string `json:"state"`  // LISTEN|BOUND
+	Path      string `json:"path,omitempty"` // unix sockets
+	Family    string `json:"family"`         // ipv4|ipv6|unix|unknown
+	State     string `json:"state"`          // LISTEN|BOUND
 	PID       int32  `json:"pid,omitempty"`
 
 	ProcName   string `json:"proc_name,omitempty"`
@@ -43,6 +82,171 @@ type Listener struct {
 	WorkingDir string `json:"working_dir,omitempty"`
 	User       string `json:"user,omitempty"`
 	IsZombie   bool   `json:"is_zombie,omitempty"`
+
+	// Socket queues (Linux). For a LISTEN socket RecvQ is the number of
+	// connections waiting in the accept queue and SendQ is the configured
+	// backlog (0 when the backend cannot see it).
+	RecvQ int `json:"recv_q,omitempty"`
+	SendQ int `json:"send_q,omitempty"`
+
+	// Holders lists every process with a descriptor for this socket
+	// (pre-fork servers share one listening socket across workers).
+	Holders []SocketHolder `json:"holders,omitempty"`
+
+	// Netns names the network namespace the socket lives in ("host" for
+	// portik's own); set only when namespaces were selected explicitly.
+	Netns     string `json:"netns,omitempty"`
+	Container string `json:"container,omitempty"` // e.g. "docker:3f2a9c1b7d4e"
+
+	// Tunnel is set when the process is a userspace forwarder (ssh -L,
+	// kubectl port-forward, socat, ...) rather than the service itself.
+	Tunnel *Tunnel `json:"tunnel,omitempty"`
+
+	// Probe is what the listener answered when portik connected to it
+	// (--probe); nil unless probing was asked for.
+	Probe *Fingerprint `json:"probe,omitempty"`
+}
+
+// Fingerprint identifies the protocol a listener serves from a lightweight
+// handshake.
+type Fingerprint struct {
+	Addr     string `json:"addr"`               // address probed, e.g. "127.0.0.1:5432"
+	Protocol string `json:"protocol,omitempty"` // http|https|tls|ssh|redis|postgres|mysql|grpc|http2|unknown; empty if the probe failed
+	TLS      bool   `json:"tls,omitempty"`
+	ALPN     string `json:"alpn,omitempty"`   // protocol negotiated over TLS
+	Status   int    `json:"status,omitempty"` // HTTP status code
+	Server   string `json:"server,omitempty"` // HTTP Server header, SSH or MySQL version
+	Detail   string `json:"detail,omitempty"` // e.g. "redis requires AUTH", or the raw banner of an unknown protocol
+	Cert     *Cert  `json:"cert,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Cert summarises a certificate a TLS listener presented.
+type Cert struct {
+	Subject    string    `json:"subject"`
+	Issuer     string    `json:"issuer"`
+	DNSNames   []string  `json:"dns_names,omitempty"`
+	IPs        []string  `json:"ip_addresses,omitempty"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	SelfSigned bool      `json:"self_signed,omitempty"`
+	IsCA       bool      `json:"is_ca,omitempty"`
+	Key        string    `json:"key,omitempty"`       // RSA|ECDSA|Ed25519
+	KeyBits    int       `json:"key_bits,omitempty"`  // modulus or curve size
+	Signature  string    `json:"signature,omitempty"` // e.g. SHA256-RSA
+}
+
+// Covers reports whether the certificate is valid for host, a DNS name or
+// an IP literal, the way TLS clients check it: against the SANs only, with
+// a wildcard matching one leftmost label.
+func (c Cert) Covers(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		for _, s := range c.IPs {
+			if other := net.ParseIP(s); other != nil && other.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, n := range c.DNSNames {
+		n = strings.TrimSuffix(strings.ToLower(n), ".")
+		if n == host {
+			return true
+		}
+		if rest, ok := strings.CutPrefix(n, "*."); ok {
+			if _, hostRest, ok := strings.Cut(host, "."); ok && hostRest == rest {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TLSInfo is the outcome of TLS handshakes with a listener (portik tls,
+// lint --tls).
+type TLSInfo struct {
+	Addr       string   `json:"addr"`
+	ServerName string   `json:"server_name,omitempty"` // SNI sent, if any
+	StartTLS   string   `json:"starttls,omitempty"`    // postgres|smtp when TLS was negotiated in-protocol
+	Version    string   `json:"version"`               // negotiated, e.g. "TLS 1.3"
+	Cipher     string   `json:"cipher"`
+	ALPN       string   `json:"alpn,omitempty"`
+	Versions   []string `json:"versions"` // every protocol version the server accepts
+	Chain      []Cert   `json:"chain"`    // as presented, leaf first
+}
+
+// String reads like "https 200 nginx/1.25.3" or "postgres (TLS available)".
+func (f Fingerprint) String() string {
+	if f.Protocol == "" {
+		if f.Error != "" {
+			return "no answer (" + f.Error + ")"
+		}
+		return "no answer"
+	}
+	parts := []string{f.Protocol}
+	if f.Status != 0 {
+		parts = append(parts, fmt.Sprint(f.Status))
+	}
+	if f.Server != "" {
+		parts = append(parts, f.Server)
+	}
+	if f.Detail != "" {
+		parts = append(parts, "("+f.Detail+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Health is the outcome of a readiness check (wait --http/--tls/...,
+// scan's HEALTH column).
+type Health struct {
+	Check     string  `json:"check"` // tcp|tls|http|https|grpc
+	Addr      string  `json:"addr"`
+	OK        bool    `json:"ok"`
+	Status    int     `json:"status,omitempty"` // HTTP status code
+	Detail    string  `json:"detail,omitempty"` // e.g. "HTTP 503", "NOT_SERVING", "connection refused"
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Tunnel describes a forwarder holding a port for an upstream service.
+type Tunnel struct {
+	Tool   string `json:"tool"`             // ssh|kubectl|socat|docker-proxy|gvproxy|rootlessport|vscode
+	Target string `json:"target,omitempty"` // e.g. "db.internal:5432", "svc/api:80", "container 172.17.0.2:80"
+	Via    string `json:"via,omitempty"`    // ssh server, kube namespace/context, ...
+	Spec   string `json:"spec,omitempty"`   // forwarding argument, e.g. "-L 5432:db.internal:5432"
+	Peer   string `json:"peer,omitempty"`   // upstream connection held by the forwarder, "10.0.0.5:22"
+	Shared bool   `json:"shared,omitempty"` // ssh ControlMaster: other sessions use the same process
+}
+
+// String reads like "tunnel to db.internal:5432 (ssh via bastion)".
+func (t Tunnel) String() string {
+	how := t.Tool
+	if t.Via != "" {
+		how += " via " + t.Via
+	}
+	if t.Target == "" {
+		return "tunnel (" + how + ")"
+	}
+	return "tunnel to " + t.Target + " (" + how + ")"
+}
+
+type SocketHolder struct {
+	PID      int32  `json:"pid"`
+	PPID     int32  `json:"ppid,omitempty"`
+	ProcName string `json:"proc_name,omitempty"`
+	FD       int    `json:"fd,omitempty"`
+	Role     string `json:"role,omitempty"` // master|worker
+}
+
+// Workers returns the holders marked as workers of the listener's master.
+func (l Listener) Workers() []SocketHolder {
+	var out []SocketHolder
+	for _, h := range l.Holders {
+		if h.Role == "worker" {
+			out = append(out, h)
+		}
+	}
+	return out
 }
 
 type Conn struct {
@@ -50,11 +254,47 @@ type Conn struct {
 	LocalPort  int    `json:"local_port"`
 	RemoteIP   string `json:"remote_ip"`
 	RemotePort int    `json:"remote_port"`
+	Path       string `json:"path,omitempty"` // unix: the listener's path; PID is the client
 	Family     string `json:"family"`
 	State      string `json:"state"`
+	RecvQ      int    `json:"recv_q,omitempty"` // bytes not yet read by the app
+	SendQ      int    `json:"send_q,omitempty"` // bytes not yet acked by the peer
 
 	PID      int32  `json:"pid,omitempty"`
 	ProcName string `json:"proc_name,omitempty"`
+
+	TCP *TCPInfo `json:"tcp,omitempty"`
+}
+
+// UnixSocket is one AF_UNIX socket. Sockets accepted by a listener carry the
+// listener's path; client ends are usually unnamed. Abstract names start
+// with "@".
+type UnixSocket struct {
+	Path      string `json:"path,omitempty"`
+	Type      string `json:"type"` // stream|dgram|seqpacket
+	State     string `json:"state"`
+	Inode     uint64 `json:"inode"`
+	PeerInode uint64 `json:"peer_inode,omitempty"` // 0 when the backend cannot see peers
+	RecvQ     int    `json:"recv_q,omitempty"`
+	SendQ     int    `json:"send_q,omitempty"`
+
+	PID      int32          `json:"pid,omitempty"`
+	ProcName string         `json:"proc_name,omitempty"`
+	Holders  []SocketHolder `json:"holders,omitempty"`
+}
+
+// TCPInfo holds kernel tcp_info metrics for one connection (Linux only;
+// from sock_diag or ss -i).
+type TCPInfo struct {
+	RTTMs          float64 `json:"rtt_ms"`
+	RTTVarMs       float64 `json:"rttvar_ms"`
+	Retransmits    int     `json:"retransmits"` // unrecovered retransmits of the current segment
+	TotalRetrans   int     `json:"total_retrans"`
+	Unacked        int     `json:"unacked"`
+	Cwnd           int     `json:"cwnd,omitempty"`
+	BytesSent      uint64  `json:"bytes_sent"`
+	BytesReceived  uint64  `json:"bytes_received"`
+	LastActivityMs int64   `json:"last_activity_ms"` // since data was last sent or received
 }
 
 type DockerMap struct {
@@ -67,6 +307,7 @@ type DockerMap struct {
 }
 
 type Diagnostic struct {
+	Rule     string `json:"rule,omitempty"` // ID of the rule that emitted it
 	Kind     string `json:"kind"`
 	Severity string `json:"severity"` // info|warn|error
 	Summary  string `json:"summary"`
@@ -89,7 +330,14 @@ func (r Report) PrimaryListener() (Listener, bool) {
 func (r Report) Signature() string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "%d/%s|", r.Port, r.Proto)
+	if r.Path != "" {
+		fmt.Fprintf(&b, "P:%s|", r.Path)
+	}
 	for _, l := range r.Listeners {
+		if l.Path != "" {
+			fmt.Fprintf(&b, "L:%s:%s:%d|", l.Path, l.ProcName, l.PID)
+			continue
+		}
 		fmt.Fprintf(&b, "L:%s:%d:%s:%d|", l.LocalIP, l.LocalPort, l.ProcName, l.PID)
 	}
 	if r.Docker.Mapped {
@@ -123,7 +371,49 @@ type LintFinding struct {
 	Proto   string `json:"proto"`
 	Port    int    `json:"port"`
 	LocalIP string `json:"local_ip,omitempty"`
+	Path    string `json:"path,omitempty"` // unix sockets
+
+	PID      int32  `json:"pid,omitempty"`
+	ProcName string `json:"proc_name,omitempty"`
+	User     string `json:"user,omitempty"`
+}
+
+// Fingerprint identifies a finding across runs (lint baselines, SARIF):
+// code, proto, port (or socket path) and process name. PIDs and addresses
+// change on restart and are left out.
+func (f LintFinding) Fingerprint() string {
+	where := strconv.Itoa(f.Port)
+	if f.Path != "" {
+		where = f.Path
+	}
+	proc := f.ProcName
+	if proc == "" {
+		proc = "-"
+	}
+	return f.Code + " " + f.Proto + "/" + where + " " + proc
+}
+
+// LintRule describes a lint finding code for reports that list rules
+// (SARIF, JUnit, Markdown).
+type LintRule struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"` // default severity: info|warn|error
+	Summary  string `json:"summary"`
+	Help     string `json:"help"` // what it means and how to fix it
+}
+
+// PortCheck is one line of a manifest check (portik check): a declared
+// service as found, or one way the live listeners differ from the manifest.
+type PortCheck struct {
+	Service  string `json:"service,omitempty"` // empty for unexpected listeners
+	Proto    string `json:"proto"`
+	Port     int    `json:"port"`
+	Status   string `json:"status"`             // ok|missing|wrong_bind|wrong_owner|unexpected
+	Severity string `json:"severity,omitempty"` // warn|error, unless ok
+	Want     string `json:"want,omitempty"`
+	Got      string `json:"got,omitempty"`
 
+	LocalIP  string `json:"local_ip,omitempty"` // comma-separated when bound twice
 	PID      int32  `json:"pid,omitempty"`
 	ProcName string `json:"proc_name,omitempty"`
 	User     string `json:"user,omitempty"`
@@ -10,3 +10,22 @@ func TestSignatureStable(t *testing.T) {
 		t.Fatalf("signature should be stable; got %q vs %q", s1, s2)
 	}
 }
+
+func TestCertCovers(t *testing.T) {
+	c := Cert{DNSNames: []string{"localhost", "*.dev.example.com"}, IPs: []string{"127.0.0.1", "::1"}}
+	for host, want := range map[string]bool{
+		"localhost":           true,
+		"LOCALHOST.":          true,
+		"api.dev.example.com": true,
+		"dev.example.com":     false,
+		"a.b.dev.example.com": false,
+		"127.0.0.1":           true,
+		"[::1]":               true,
+		"10.0.0.1":            false,
+		"devbox":              false,
+	} {
+		if got := c.Covers(host); got != want {
+			t.Errorf("Covers(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
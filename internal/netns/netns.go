@@ -0,0 +1,39 @@
+// Package netns finds network namespaces and runs socket inspection inside
+// them, so listeners in containers and pods are visible from the host.
+package netns
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Namespace identifies one network namespace.
+type Namespace struct {
+	Name  string // ip-netns name, "pid:<pid>", or the path given
+	Path  string // file to setns(2) into: /run/netns/<name> or /proc/<pid>/ns/net
+	Inode uint64
+	PID   int  // a process inside the namespace, 0 if none is known
+	Host  bool // the namespace portik itself runs in
+}
+
+// Label is the short name shown in output.
+func (n Namespace) Label() string {
+	if n.Host {
+		return "host"
+	}
+	return n.Name
+}
+
+// parseNsLink parses the target of /proc/<pid>/ns/net, e.g. "net:[4026531840]".
+func parseNsLink(s string) (uint64, bool) {
+	s, ok := strings.CutPrefix(s, "net:[")
+	if !ok {
+		return 0, false
+	}
+	s, ok = strings.CutSuffix(s, "]")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	return n, err == nil
+}
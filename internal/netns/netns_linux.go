@@ -0,0 +1,188 @@
+//go:build linux
+
+package netns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const namedDir = "/run/netns" // ip netns add <name>
+
+// Current returns the namespace portik runs in.
+func Current() (Namespace, error) {
+	ino, err := nsInode("/proc/self/ns/net")
+	if err != nil {
+		return Namespace{}, err
+	}
+	return Namespace{Name: "host", Path: "/proc/self/ns/net", Inode: ino, PID: os.Getpid(), Host: true}, nil
+}
+
+// Resolve accepts an ip-netns name, a pid, or a path to a namespace file.
+func Resolve(spec string) (Namespace, error) {
+	spec = strings.TrimSpace(spec)
+	var ns Namespace
+	switch {
+	case spec == "":
+		return Namespace{}, errors.New("empty netns")
+	case strings.Contains(spec, "/"):
+		ns = Namespace{Name: spec, Path: spec}
+	case isDigits(spec):
+		pid, _ := strconv.Atoi(spec)
+		ns = Namespace{Name: "pid:" + spec, Path: "/proc/" + spec + "/ns/net", PID: pid}
+	default:
+		ns = Namespace{Name: spec, Path: filepath.Join(namedDir, spec)}
+	}
+	ino, err := nsInode(ns.Path)
+	if err != nil {
+		return Namespace{}, fmt.Errorf("netns %s: %w", spec, err)
+	}
+	ns.Inode = ino
+	if cur, err := Current(); err == nil && cur.Inode == ino {
+		ns.Host = true
+	}
+	// prefer the ip-netns name when a pid or path refers to a named namespace
+	for _, named := range listNamed() {
+		if named.Inode == ino && ns.Name != named.Name {
+			ns.Name = named.Name
+			break
+		}
+	}
+	return ns, nil
+}
+
+// All returns the host namespace first, then named namespaces, then every
+// other namespace some process is in (containers, pods), ordered by pid.
+func All() ([]Namespace, error) {
+	cur, err := Current()
+	if err != nil {
+		return nil, err
+	}
+	out := []Namespace{cur}
+	seen := map[uint64]int{cur.Inode: 0}
+	for _, ns := range listNamed() {
+		if _, ok := seen[ns.Inode]; ok {
+			continue
+		}
+		seen[ns.Inode] = len(out)
+		out = append(out, ns)
+	}
+
+	ents, err := os.ReadDir("/proc")
+	if err != nil {
+		return out, nil
+	}
+	var pids []int
+	for _, e := range ents {
+		if pid, err := strconv.Atoi(e.Name()); err == nil && pid > 0 {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	for _, pid := range pids {
+		path := "/proc/" + strconv.Itoa(pid) + "/ns/net"
+		link, err := os.Readlink(path)
+		if err != nil {
+			continue
+		}
+		ino, ok := parseNsLink(link)
+		if !ok {
+			continue
+		}
+		if i, ok := seen[ino]; ok {
+			if out[i].PID == 0 {
+				out[i].PID = pid
+			}
+			continue
+		}
+		seen[ino] = len(out)
+		out = append(out, Namespace{Name: "pid:" + strconv.Itoa(pid), Path: path, Inode: ino, PID: pid})
+	}
+	return out, nil
+}
+
+// Do runs fn with the calling goroutine's OS thread switched into ns.
+// Sockets created inside fn (netlink) and child processes it starts (ss)
+// belong to ns. Goroutines started by fn do NOT inherit the namespace.
+func Do(ns Namespace, fn func() error) error {
+	if ns.Host {
+		return fn()
+	}
+	target, err := os.Open(ns.Path)
+	if err != nil {
+		return fmt.Errorf("netns %s: %w", ns.Label(), err)
+	}
+	defer target.Close()
+
+	runtime.LockOSThread()
+	orig, err := os.Open("/proc/thread-self/ns/net")
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer orig.Close()
+	if err := setns(int(target.Fd())); err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("enter netns %s: %w", ns.Label(), err)
+	}
+
+	ferr := fn()
+	if err := setns(int(orig.Fd())); err != nil {
+		// keep the thread locked: the runtime discards it rather than reuse
+		// a thread stuck in the wrong namespace
+		return errors.Join(ferr, fmt.Errorf("restore netns: %w", err))
+	}
+	runtime.UnlockOSThread()
+	return ferr
+}
+
+func setns(fd int) error {
+	return os.NewSyscallError("setns", unix.Setns(fd, unix.CLONE_NEWNET))
+}
+
+func listNamed() []Namespace {
+	ents, err := os.ReadDir(namedDir)
+	if err != nil {
+		return nil
+	}
+	var out []Namespace
+	for _, e := range ents {
+		path := filepath.Join(namedDir, e.Name())
+		ino, err := nsInode(path)
+		if err != nil {
+			continue
+		}
+		out = append(out, Namespace{Name: e.Name(), Path: path, Inode: ino})
+	}
+	return out
+}
+
+func nsInode(path string) (uint64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("%s: no inode", path)
+	}
+	return st.Ino, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
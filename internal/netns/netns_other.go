@@ -0,0 +1,33 @@
+//go:build !linux
+
+package netns
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errUnsupported = fmt.Errorf("network namespaces: %w on this OS", errors.ErrUnsupported)
+
+// Current returns the namespace portik runs in.
+func Current() (Namespace, error) {
+	return Namespace{Name: "host", Host: true}, nil
+}
+
+// Resolve is only supported on Linux.
+func Resolve(spec string) (Namespace, error) {
+	return Namespace{}, errUnsupported
+}
+
+// All is only supported on Linux.
+func All() ([]Namespace, error) {
+	return nil, errUnsupported
+}
+
+// Do runs fn; only the host namespace is available.
+func Do(ns Namespace, fn func() error) error {
+	if !ns.Host {
+		return errUnsupported
+	}
+	return fn()
+}
@@ -0,0 +1,24 @@
+package netns
+
+import "testing"
+
+func TestParseNsLink(t *testing.T) {
+	ino, ok := parseNsLink("net:[4026531840]")
+	if !ok || ino != 4026531840 {
+		t.Fatalf("unexpected parse: %d ok=%v", ino, ok)
+	}
+	for _, bad := range []string{"", "mnt:[4026531840]", "net:[abc]", "net:4026531840"} {
+		if _, ok := parseNsLink(bad); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	if got := (Namespace{Name: "pid:42", Host: true}).Label(); got != "host" {
+		t.Fatalf("expected host label, got %q", got)
+	}
+	if got := (Namespace{Name: "blue"}).Label(); got != "blue" {
+		t.Fatalf("expected named label, got %q", got)
+	}
+}
@@ -7,17 +7,11 @@ import (
 	"strings"
 )
 
-type FirewallInfo struct {
-	Active  bool
-	Name    string
-	Details string
-}
-
-func FirewallStatus() FirewallInfo {
+func firewallStatus() FirewallInfo {
 	if _, err := exec.LookPath("pfctl"); err != nil {
 		return FirewallInfo{}
 	}
-	out, err := exec.Command("pfctl", "-s", "info").Output()
+	out, err := probeOutput("pfctl", "-s", "info")
 	if err != nil {
 		return FirewallInfo{}
 	}
@@ -27,3 +21,12 @@ func FirewallStatus() FirewallInfo {
 	}
 	return FirewallInfo{}
 }
+
+func netnsID() string {
+	return ""
+}
+
+// firewallRules is empty on macOS: pf rules are not evaluated.
+func firewallRules() map[string]string {
+	return nil
+}
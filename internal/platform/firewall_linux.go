@@ -3,28 +3,78 @@
 package platform
 
 import (
+	"bytes"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
-type FirewallInfo struct {
-	Active  bool
-	Name    string
-	Details string
-}
-
-func FirewallStatus() FirewallInfo {
+func firewallStatus() FirewallInfo {
 	if _, err := exec.LookPath("ufw"); err == nil {
-		out, err := exec.Command("ufw", "status").Output()
+		out, err := probeOutput("ufw", "status")
 		if err == nil && strings.Contains(strings.ToLower(string(out)), "status: active") {
 			return FirewallInfo{Active: true, Name: "ufw"}
 		}
 	}
 	if _, err := exec.LookPath("firewall-cmd"); err == nil {
-		out, err := exec.Command("firewall-cmd", "--state").Output()
+		out, err := probeOutput("firewall-cmd", "--state")
 		if err == nil && strings.Contains(strings.ToLower(string(out)), "running") {
 			return FirewallInfo{Active: true, Name: "firewalld"}
 		}
 	}
 	return FirewallInfo{}
 }
+
+// netnsID names the network namespace of the calling thread, which is
+// the one netns.Do switched to.
+func netnsID() string {
+	id, _ := os.Readlink("/proc/thread-self/ns/net")
+	return id
+}
+
+func firewallRules() map[string]string {
+	out := map[string]string{}
+	run := func(key string, name string, args ...string) {
+		if _, err := exec.LookPath(name); err != nil {
+			return
+		}
+		b, err := probeOutput(name, args...)
+		if err == nil && len(bytes.TrimSpace(b)) > 0 {
+			out[key] += string(b)
+		}
+	}
+	run("ufw", "ufw", "status", "verbose")
+	run("ufw", "ufw", "status", "numbered")
+	if out["ufw"] != "" {
+		if apps := ufwApps("/etc/ufw/applications.d"); apps != "" {
+			out["ufw-apps"] = apps
+		}
+	}
+	run("nft", "nft", "-j", "list", "ruleset")
+	run("iptables", "iptables-save")
+	run("ip6tables", "ip6tables-save")
+	return out
+}
+
+// ufwApps concatenates the application profiles ufw rules such as
+// "OpenSSH" refer to; each file is an INI list of [name] sections.
+func ufwApps(dir string) string {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, e := range ents {
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
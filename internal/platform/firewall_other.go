@@ -2,12 +2,14 @@
 
 package platform
 
-type FirewallInfo struct {
-	Active  bool
-	Name    string
-	Details string
+func firewallStatus() FirewallInfo {
+	return FirewallInfo{}
 }
 
-func FirewallStatus() FirewallInfo {
-	return FirewallInfo{}
+func netnsID() string {
+	return ""
+}
+
+func firewallRules() map[string]string {
+	return nil
 }
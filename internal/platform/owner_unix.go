@@ -0,0 +1,20 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os"
+	"syscall"
+)
+
+// FileOwner returns the uid and gid owning a file.
+func FileOwner(fi os.FileInfo) (uid, gid int, ok bool) {
+	if uid, gid, ok := replayedOwner(fi); ok {
+		return uid, gid, true
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
@@ -2,20 +2,33 @@ package platform
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Summary struct {
-	OS       string
-	Arch     string
-	Hostname string
-	Kernel   string
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Hostname string `json:"hostname"`
+	Kernel   string `json:"kernel,omitempty"`
+}
+
+type FirewallInfo struct {
+	Active  bool   `json:"active"`
+	Name    string `json:"name,omitempty"`
+	Details string `json:"details,omitempty"`
 }
 
 func HostSummary() (s Summary) {
+	if replay != nil {
+		return replay.Host
+	}
 	s.OS = runtime.GOOS
 	s.Arch = runtime.GOARCH
 	if h, err := os.Hostname(); err == nil {
@@ -33,7 +46,64 @@ func unameR() string {
 	return strings.TrimSpace(string(bytes.TrimSpace(out)))
 }
 
+// probeTimeout bounds each external tool portik runs to look at the host,
+// so a hung firewall daemon (firewall-cmd waits on D-Bus) cannot stall it.
+const probeTimeout = 3 * time.Second
+
+// probeOutput runs name with args and returns its stdout, killing it after
+// probeTimeout.
+func probeOutput(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// FirewallStatus reports whether a host firewall (ufw, firewalld, pf) is on.
+func FirewallStatus() FirewallInfo {
+	if replay != nil {
+		return replay.Firewall
+	}
+	return firewallStatus()
+}
+
+// FirewallRules returns the raw rulesets the firewall package evaluates,
+// keyed by tool: "ufw", "ufw-apps" (its application profiles), "nft",
+// "iptables" and "ip6tables". Tools that are missing or need more
+// privileges than portik has are left out. Results are cached briefly per
+// network namespace, since scan and watch ask once per port and --netns
+// evaluates each namespace's own rules.
+func FirewallRules() map[string]string {
+	if replay != nil {
+		return replay.FirewallRules
+	}
+	ns := netnsID()
+	fwRules.Lock()
+	defer fwRules.Unlock()
+	c, ok := fwRules.byNetns[ns]
+	if !ok || time.Since(c.at) > 10*time.Second {
+		c = fwDumps{at: time.Now(), dumps: firewallRules()}
+		if fwRules.byNetns == nil {
+			fwRules.byNetns = map[string]fwDumps{}
+		}
+		fwRules.byNetns[ns] = c
+	}
+	return c.dumps
+}
+
+type fwDumps struct {
+	at    time.Time
+	dumps map[string]string
+}
+
+var fwRules struct {
+	sync.Mutex
+	byNetns map[string]fwDumps
+}
+
 func InContainer() bool {
+	if replay != nil {
+		return replay.InContainer
+	}
 	b, err := os.ReadFile("/proc/1/cgroup")
 	if err == nil {
 		txt := string(b)
@@ -48,6 +118,9 @@ func InContainer() bool {
 }
 
 func InWSL() bool {
+	if replay != nil {
+		return replay.InWSL
+	}
 	if b, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
 		return strings.Contains(strings.ToLower(string(b)), "microsoft")
 	}
@@ -55,6 +128,9 @@ func InWSL() bool {
 }
 
 func InVM() bool {
+	if replay != nil {
+		return replay.InVM
+	}
 	if b, err := os.ReadFile("/proc/cpuinfo"); err == nil {
 		txt := strings.ToLower(string(b))
 		if strings.Contains(txt, "hypervisor") {
@@ -63,3 +139,35 @@ func InVM() bool {
 	}
 	return false
 }
+
+// Sysctl reads a kernel parameter such as "net.core.somaxconn" from
+// /proc/sys. ok is false when it is unavailable (non-Linux, no permission).
+func Sysctl(name string) (string, bool) {
+	if replay != nil {
+		v, ok := replay.Sysctls[name]
+		return v, ok
+	}
+	b, err := os.ReadFile("/proc/sys/" + strings.ReplaceAll(name, ".", "/"))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(b)), true
+}
+
+// SysctlInts reads a sysctl holding one or more whitespace-separated integers
+// (e.g. "net.ipv4.ip_local_port_range" -> [32768 60999]).
+func SysctlInts(name string) ([]int, bool) {
+	v, ok := Sysctl(name)
+	if !ok {
+		return nil, false
+	}
+	var out []int
+	for _, f := range strings.Fields(v) {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, false
+		}
+		out = append(out, n)
+	}
+	return out, len(out) > 0
+}
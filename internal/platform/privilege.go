@@ -0,0 +1,152 @@
+package platform
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// CapNetBindService is the capability bit for CAP_NET_BIND_SERVICE.
+const CapNetBindService = 10
+
+// Privileges are what the running portik process may do. A program started
+// from the same shell gets the same uid and (ambient) capabilities, so they
+// answer "would my app be allowed to bind this?".
+type Privileges struct {
+	UID    int    `json:"uid"`
+	CapEff uint64 `json:"cap_eff"`
+	Known  bool   `json:"known"`             // CapEff was read (Linux)
+	UserNS bool   `json:"user_ns,omitempty"` // inside a non-initial user namespace (rootless containers)
+}
+
+// Has reports whether the effective set holds capability bit c.
+func (p Privileges) Has(c int) bool {
+	return p.Known && p.CapEff&(1<<uint(c)) != 0
+}
+
+// FileCaps are the capabilities attached to an executable (setcap).
+type FileCaps struct {
+	Permitted uint64
+	Effective bool // raised on exec ("+ep"); otherwise the program must raise them itself
+}
+
+// Has reports whether the file grants capability bit c.
+func (f FileCaps) Has(c int) bool {
+	return f.Permitted&(1<<uint(c)) != 0
+}
+
+// Self returns the privileges of the running process, or of the process
+// that captured the bundle.
+func Self() Privileges {
+	if replay != nil {
+		if replay.Self != nil {
+			return *replay.Self
+		}
+		uid, err := strconv.Atoi(replay.UID)
+		if err != nil {
+			uid = -1
+		}
+		return Privileges{UID: uid}
+	}
+	return self()
+}
+
+// ProcessPrivileges returns the privileges of process pid, e.g. the one
+// holding a port. ok is false when they cannot be read (non-Linux, replay,
+// the process is gone).
+func ProcessPrivileges(pid int32) (Privileges, bool) {
+	if replay != nil || pid <= 0 {
+		return Privileges{}, false
+	}
+	return processPrivileges(pid)
+}
+
+// ProcessExecutableCaps reads the file capabilities of the executable
+// process pid runs, like ExecutableCaps. Reading another user's process
+// needs root.
+func ProcessExecutableCaps(pid int32) (FileCaps, bool) {
+	if replay != nil || pid <= 0 {
+		return FileCaps{}, false
+	}
+	return processExecutableCaps(pid)
+}
+
+// ProcessGoBinary reports whether the executable process pid runs carries
+// Go build information. On Linux it reads /proc/<pid>/exe, which still
+// opens a binary that was deleted or replaced; elsewhere it reads exe. It
+// is false when that cannot be told (replay, the process is gone).
+func ProcessGoBinary(pid int32, exe string) bool {
+	if replay != nil || pid <= 0 {
+		return false
+	}
+	return processGoBinary(pid, exe)
+}
+
+// ExecutableCaps reads the file capabilities of path. ok is false when it
+// has none or they cannot be read (non-Linux, replay).
+func ExecutableCaps(path string) (FileCaps, bool) {
+	if replay != nil {
+		return FileCaps{}, false
+	}
+	return executableCaps(path)
+}
+
+// parseCapEff returns the effective capability set from /proc/<pid>/status
+// ("CapEff:	0000000000000400").
+func parseCapEff(status string) (uint64, bool) {
+	for _, line := range strings.Split(status, "\n") {
+		if !strings.HasPrefix(line, "CapEff:") {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+		return v, err == nil
+	}
+	return 0, false
+}
+
+// parseEffectiveUID returns the effective uid from /proc/<pid>/status
+// ("Uid:	1000	0	0	0": real, effective, saved, filesystem).
+func parseEffectiveUID(status string) (int, bool) {
+	for _, line := range strings.Split(status, "\n") {
+		f := strings.Fields(line)
+		if len(f) < 3 || f[0] != "Uid:" {
+			continue
+		}
+		uid, err := strconv.Atoi(f[2])
+		return uid, err == nil
+	}
+	return 0, false
+}
+
+// identityMap reports whether a /proc/<pid>/uid_map is the initial user
+// namespace's ("0 0 4294967295").
+func identityMap(uidMap string) bool {
+	f := strings.Fields(uidMap)
+	return len(f) == 3 && f[0] == "0" && f[1] == "0" && f[2] == "4294967295"
+}
+
+// parseVFSCap decodes a security.capability xattr (struct vfs_cap_data,
+// little endian): magic_etc, then permitted/inheritable pairs of 32-bit
+// words; revision 3 appends the namespace root uid.
+func parseVFSCap(b []byte) (FileCaps, bool) {
+	const (
+		revisionMask = 0xFF000000
+		revision1    = 0x01000000
+		flagEff      = 0x000001
+	)
+	if len(b) < 12 {
+		return FileCaps{}, false
+	}
+	magic := binary.LittleEndian.Uint32(b)
+	fc := FileCaps{
+		Permitted: uint64(binary.LittleEndian.Uint32(b[4:])),
+		Effective: magic&flagEff != 0,
+	}
+	if magic&revisionMask != revision1 {
+		if len(b) < 20 {
+			return FileCaps{}, false
+		}
+		fc.Permitted |= uint64(binary.LittleEndian.Uint32(b[12:])) << 32
+	}
+	return fc, true
+}
@@ -0,0 +1,55 @@
+//go:build linux
+
+package platform
+
+import (
+	"debug/buildinfo"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+func self() Privileges {
+	p, _ := readPrivileges("/proc/self")
+	p.UID = os.Geteuid()
+	return p
+}
+
+func processPrivileges(pid int32) (Privileges, bool) {
+	return readPrivileges("/proc/" + strconv.Itoa(int(pid)))
+}
+
+// readPrivileges reads the effective uid and capabilities and the user
+// namespace of the process at dir. ok is false when its uid is unknown.
+func readPrivileges(dir string) (p Privileges, ok bool) {
+	p.UID = -1
+	if b, err := os.ReadFile(dir + "/status"); err == nil {
+		if uid, found := parseEffectiveUID(string(b)); found {
+			p.UID, ok = uid, true
+		}
+		p.CapEff, p.Known = parseCapEff(string(b))
+	}
+	if b, err := os.ReadFile(dir + "/uid_map"); err == nil {
+		p.UserNS = !identityMap(string(b))
+	}
+	return p, ok
+}
+
+func processExecutableCaps(pid int32) (FileCaps, bool) {
+	return executableCaps("/proc/" + strconv.Itoa(int(pid)) + "/exe")
+}
+
+func processGoBinary(pid int32, _ string) bool {
+	_, err := buildinfo.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/exe")
+	return err == nil
+}
+
+func executableCaps(path string) (FileCaps, bool) {
+	buf := make([]byte, 64)
+	n, err := unix.Getxattr(path, "security.capability", buf)
+	if err != nil {
+		return FileCaps{}, false
+	}
+	return parseVFSCap(buf[:n])
+}
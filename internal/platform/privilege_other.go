@@ -0,0 +1,32 @@
+//go:build !linux
+
+package platform
+
+import (
+	"debug/buildinfo"
+	"os"
+)
+
+func self() Privileges {
+	return Privileges{UID: os.Geteuid()}
+}
+
+func processPrivileges(int32) (Privileges, bool) {
+	return Privileges{}, false
+}
+
+func processExecutableCaps(int32) (FileCaps, bool) {
+	return FileCaps{}, false
+}
+
+func processGoBinary(_ int32, exe string) bool {
+	if exe == "" {
+		return false
+	}
+	_, err := buildinfo.ReadFile(exe)
+	return err == nil
+}
+
+func executableCaps(string) (FileCaps, bool) {
+	return FileCaps{}, false
+}
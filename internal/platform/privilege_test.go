@@ -0,0 +1,51 @@
+package platform
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseVFSCap(t *testing.T) {
+	// setcap cap_net_bind_service=+ep (revision 2)
+	ep := []byte{0x01, 0x00, 0x00, 0x02, 0x00, 0x04, 0x00, 0x00, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	fc, ok := parseVFSCap(ep)
+	if !ok || !fc.Effective || !fc.Has(CapNetBindService) {
+		t.Fatalf("=ep: %+v ok=%v", fc, ok)
+	}
+	// cap_net_bind_service=+p, revision 1 (single 32-bit pair)
+	p := []byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x04, 0x00, 0x00, 0, 0, 0, 0}
+	fc, ok = parseVFSCap(p)
+	if !ok || fc.Effective || !fc.Has(CapNetBindService) {
+		t.Fatalf("=p: %+v ok=%v", fc, ok)
+	}
+	if _, ok := parseVFSCap([]byte{1, 2, 3}); ok {
+		t.Fatalf("short xattr should not parse")
+	}
+}
+
+func TestParseCapEff(t *testing.T) {
+	status := "Name:\tnginx\nCapInh:\t0000000000000000\nCapEff:\t0000000000000400\n"
+	v, ok := parseCapEff(status)
+	if !ok || !(Privileges{CapEff: v, Known: true}).Has(CapNetBindService) {
+		t.Fatalf("CapEff = %x, ok=%v", v, ok)
+	}
+	if uid, ok := parseEffectiveUID("Name:\tnginx\nUid:\t0\t33\t33\t33\n"); !ok || uid != 33 {
+		t.Fatalf("effective uid = %d, ok=%v", uid, ok)
+	}
+	if identityMap("         0       1000          1\n") || !identityMap("         0          0 4294967295\n") {
+		t.Fatalf("identityMap misreads uid_map")
+	}
+}
+
+func TestProcessGoBinary(t *testing.T) {
+	exe, err := os.Executable() // the test binary is a Go program
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ProcessGoBinary(int32(os.Getpid()), exe) {
+		t.Fatal("test binary not recognised as Go")
+	}
+	if ProcessGoBinary(0, exe) {
+		t.Fatal("pid 0 recognised as Go")
+	}
+}
@@ -0,0 +1,191 @@
+package platform
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// Env is the host state diagnostics read besides the socket and process
+// tables. CaptureEnv records it for a snapshot bundle; Replay serves it back
+// in place of the live system.
+type Env struct {
+	Captured      time.Time           `json:"captured"`
+	User          string              `json:"user,omitempty"`
+	UID           string              `json:"uid,omitempty"`
+	Self          *Privileges         `json:"self,omitempty"`
+	Host          Summary             `json:"host"`
+	InContainer   bool                `json:"in_container,omitempty"`
+	InWSL         bool                `json:"in_wsl,omitempty"`
+	InVM          bool                `json:"in_vm,omitempty"`
+	Firewall      FirewallInfo        `json:"firewall"`
+	FirewallRules map[string]string   `json:"firewall_rules,omitempty"`
+	Sysctls       map[string]string   `json:"sysctls,omitempty"`
+	LocalIPs      []string            `json:"local_ips,omitempty"`
+	Interfaces    []string            `json:"interfaces,omitempty"`
+	Files         map[string]FileStat `json:"files,omitempty"` // socket files and their parent dirs
+}
+
+// FileStat is the part of a file's metadata the Unix socket checks use.
+type FileStat struct {
+	Mode fs.FileMode `json:"mode"`
+	UID  int         `json:"uid"`
+	GID  int         `json:"gid"`
+}
+
+// capturedSysctls are the kernel parameters diagnostics consult.
+var capturedSysctls = []string{
+	"net.core.somaxconn",
+	"net.ipv4.ip_local_port_range",
+	"net.ipv4.tcp_tw_reuse",
+	"net.ipv4.ip_unprivileged_port_start",
+}
+
+var replay *Env
+
+// Replay makes this package answer from e instead of the live system.
+func Replay(e Env) {
+	replay = &e
+}
+
+// Replaying reports whether answers come from a captured bundle. Checks
+// that depend on who runs portik (rather than on the captured host) should
+// be skipped.
+func Replaying() bool {
+	return replay != nil
+}
+
+// CaptureEnv records the live host state. files lists the paths whose
+// metadata should be kept; their parent directories are recorded too.
+func CaptureEnv(files []string) Env {
+	e := Env{
+		Captured:      time.Now(),
+		Host:          HostSummary(),
+		InContainer:   InContainer(),
+		InWSL:         InWSL(),
+		InVM:          InVM(),
+		Firewall:      FirewallStatus(),
+		FirewallRules: FirewallRules(),
+		Sysctls:       map[string]string{},
+		LocalIPs:      LocalIPs(),
+		Interfaces:    Interfaces(),
+		Files:         map[string]FileStat{},
+	}
+	if u, err := user.Current(); err == nil {
+		e.User, e.UID = u.Username, u.Uid
+	}
+	self := Self()
+	e.Self = &self
+	for _, name := range capturedSysctls {
+		if v, ok := Sysctl(name); ok {
+			e.Sysctls[name] = v
+		}
+	}
+	for _, p := range files {
+		for {
+			if _, ok := e.Files[p]; ok {
+				break
+			}
+			if fi, err := os.Stat(p); err == nil {
+				uid, gid, _ := FileOwner(fi)
+				e.Files[p] = FileStat{Mode: fi.Mode(), UID: uid, GID: gid}
+			}
+			parent := filepath.Dir(p)
+			if parent == p {
+				break
+			}
+			p = parent
+		}
+	}
+	return e
+}
+
+// Now is the time reports are stamped with: the capture time when replaying.
+func Now() time.Time {
+	if replay != nil {
+		return replay.Captured
+	}
+	return time.Now()
+}
+
+// CurrentUser is the user portik runs as, or the one that captured the
+// bundle.
+func CurrentUser() (*user.User, error) {
+	if replay != nil {
+		return &user.User{Username: replay.User, Uid: replay.UID}, nil
+	}
+	return user.Current()
+}
+
+// LocalIPs returns the addresses assigned to local interfaces.
+func LocalIPs() []string {
+	if replay != nil {
+		return replay.LocalIPs
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		switch v := a.(type) {
+		case *net.IPNet:
+			out = append(out, v.IP.String())
+		case *net.IPAddr:
+			out = append(out, v.IP.String())
+		}
+	}
+	return out
+}
+
+// Interfaces returns the names of the network interfaces that are up.
+func Interfaces() []string {
+	if replay != nil {
+		return replay.Interfaces
+	}
+	ifs, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, i := range ifs {
+		if i.Flags&net.FlagUp != 0 {
+			out = append(out, i.Name)
+		}
+	}
+	return out
+}
+
+// Stat is os.Stat, answered from the bundle when replaying. FileOwner works
+// on the result either way.
+func Stat(path string) (os.FileInfo, error) {
+	if replay == nil {
+		return os.Stat(path)
+	}
+	st, ok := replay.Files[path]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+	return replayedFile{name: filepath.Base(path), st: st}, nil
+}
+
+type replayedFile struct {
+	name string
+	st   FileStat
+}
+
+func (f replayedFile) Name() string       { return f.name }
+func (f replayedFile) Size() int64        { return 0 }
+func (f replayedFile) Mode() fs.FileMode  { return f.st.Mode }
+func (f replayedFile) ModTime() time.Time { return time.Time{} }
+func (f replayedFile) IsDir() bool        { return f.st.Mode.IsDir() }
+func (f replayedFile) Sys() any           { return f.st }
+
+// replayedOwner handles FileInfo values returned by Stat while replaying.
+func replayedOwner(fi os.FileInfo) (uid, gid int, ok bool) {
+	st, ok := fi.Sys().(FileStat)
+	return st.UID, st.GID, ok
+}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// Baseline is a set of accepted findings (lint --write-baseline); with
+// --baseline only findings outside it count.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Created  time.Time       `json:"created"`
+	Findings []BaselineEntry `json:"findings"`
+
+	index map[string]bool
+}
+
+// BaselineEntry records one accepted finding. Only Fingerprint is compared;
+// the rest is for the people reviewing the file.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	Summary     string `json:"summary"`
+}
+
+// NewBaseline accepts findings as of now.
+func NewBaseline(findings []model.LintFinding, now time.Time) *Baseline {
+	b := &Baseline{Version: 1, Created: now.UTC().Truncate(time.Second), Findings: []BaselineEntry{}}
+	seen := map[string]bool{}
+	for _, f := range findings {
+		fp := f.Fingerprint()
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		b.Findings = append(b.Findings, BaselineEntry{Fingerprint: fp, Severity: f.Severity, Summary: f.Summary})
+	}
+	sort.Slice(b.Findings, func(i, j int) bool { return b.Findings[i].Fingerprint < b.Findings[j].Fingerprint })
+	return b
+}
+
+// Write saves the baseline as indented JSON, so it diffs well in review.
+func (b *Baseline) Write(file string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+// LoadBaseline reads a baseline file.
+func LoadBaseline(file string) (*Baseline, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if b.Version != 1 {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d", file, b.Version)
+	}
+	b.index = map[string]bool{}
+	for _, e := range b.Findings {
+		b.index[e.Fingerprint] = true
+	}
+	return &b, nil
+}
+
+// Split separates findings already in the baseline from new ones.
+func (b *Baseline) Split(findings []model.LintFinding) (fresh, known []model.LintFinding) {
+	for _, f := range findings {
+		if b.index[f.Fingerprint()] {
+			known = append(known, f)
+		} else {
+			fresh = append(fresh, f)
+		}
+	}
+	return fresh, known
+}
+
+// Stale returns the baseline entries matching none of present, i.e. issues
+// that were fixed since the baseline was written.
+func (b *Baseline) Stale(present []model.LintFinding) []BaselineEntry {
+	seen := map[string]bool{}
+	for _, f := range present {
+		seen[f.Fingerprint()] = true
+	}
+	var out []BaselineEntry
+	for _, e := range b.Findings {
+		if !seen[e.Fingerprint] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package policy
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func TestBaseline(t *testing.T) {
+	old := []model.LintFinding{
+		{Severity: "info", Code: "PUBLIC_DEV", Proto: "tcp", Port: 3000, PID: 100, ProcName: "node", LocalIP: "*"},
+		{Severity: "info", Code: "PUBLIC_DEV", Proto: "tcp", Port: 3000, PID: 100, ProcName: "node", LocalIP: "::"},
+		{Severity: "warn", Code: "UNIX_WORLD_WRITABLE", Proto: "unix", Path: "/run/app.sock"},
+		{Severity: "info", Code: "NO_PID", Proto: "tcp", Port: 22},
+	}
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	if err := NewBaseline(old, time.Now()).Write(file); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadBaseline(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Findings) != 3 || b.Findings[0].Fingerprint != "NO_PID tcp/22 -" {
+		t.Fatalf("entries = %+v", b.Findings)
+	}
+
+	// node restarted with a new pid; postgres is new; sshd's NO_PID is fixed
+	now := []model.LintFinding{
+		{Severity: "info", Code: "PUBLIC_DEV", Proto: "tcp", Port: 3000, PID: 2345, ProcName: "node", LocalIP: "0.0.0.0"},
+		{Severity: "warn", Code: "UNIX_WORLD_WRITABLE", Proto: "unix", Path: "/run/app.sock"},
+		{Severity: "warn", Code: "PUBLIC_SENSITIVE", Proto: "tcp", Port: 5432, ProcName: "postgres"},
+	}
+	fresh, known := b.Split(now)
+	if len(fresh) != 1 || fresh[0].Code != "PUBLIC_SENSITIVE" || len(known) != 2 {
+		t.Errorf("fresh %+v, known %+v", fresh, known)
+	}
+	if stale := b.Stale(now); len(stale) != 1 || stale[0].Fingerprint != "NO_PID tcp/22 -" {
+		t.Errorf("stale = %+v", stale)
+	}
+}
@@ -0,0 +1,391 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// Manifest declares the ports a host should have open (portik check
+// --manifest). Same YAML or JSON format as policies:
+//
+//	unexpected: error   # undeclared listeners: error, warn or off
+//	ignore:
+//	  - ports: 22
+//	services:
+//	  - name: postgres
+//	    ports: 5432
+//	    address: loopback
+//	    process: postgres
+//	    user: postgres
+//	  - name: web
+//	    ports: 80,443
+//	    process: nginx
+//	    hosts: [web-*]
+type Manifest struct {
+	Unexpected string     `json:"unexpected,omitempty"`
+	Ignore     []Selector `json:"ignore,omitempty"`
+	Services   []Service  `json:"services,omitempty"`
+}
+
+// Service is one expected listener. Every port in Ports is expected; the
+// other fields that are set must hold for whatever listens there.
+type Service struct {
+	Name string `json:"name"`
+	Selector
+	Cmdline       string   `json:"cmdline,omitempty"`        // glob on the command line; * also matches /
+	DockerService string   `json:"docker_service,omitempty"` // glob on the compose service publishing the port
+	Hosts         []string `json:"hosts,omitempty"`          // globs on the host name; default every host
+	Optional      flexBool `json:"optional,omitempty"`       // absent is fine, present must match
+
+	cmdline *regexp.Regexp
+}
+
+// flexBool is a bool that also accepts the strings YAML files use.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if x, ok := v.(bool); ok {
+		*b = flexBool(x)
+		return nil
+	}
+	s, _ := v.(string)
+	switch strings.ToLower(s) {
+	case "true", "yes", "on":
+		*b = true
+	case "false", "no", "off":
+		*b = false
+	default:
+		return fmt.Errorf("expected true or false, got %s", data)
+	}
+	return nil
+}
+
+var listenerKeys = []string{"ports", "proto", "process", "user", "address"}
+
+var manifestSchema = schema{
+	"unexpected": nil,
+	"ignore":     with(listenerKeys),
+	"services":   with(listenerKeys, "name", "cmdline", "docker_service", "hosts", "optional"),
+}
+
+// LoadManifest reads and validates a manifest file.
+func LoadManifest(file string) (*Manifest, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseManifest(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return m, nil
+}
+
+// ParseManifest decodes and validates a manifest in YAML or JSON.
+func ParseManifest(b []byte) (*Manifest, error) {
+	var m Manifest
+	if err := decode(b, "manifest", manifestSchema, &m); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *Manifest) validate() error {
+	switch m.Unexpected {
+	case "":
+		m.Unexpected = "error"
+	case "error", "warn", "off":
+	default:
+		return fmt.Errorf("unexpected: invalid %q (error|warn|off)", m.Unexpected)
+	}
+	for i := range m.Ignore {
+		s := &m.Ignore[i]
+		at := fmt.Sprintf("ignore[%d]", i)
+		if s.empty() {
+			return fmt.Errorf("%s: ignores every listener; set ports, proto, process, user or address", at)
+		}
+		if s.Proto == "unix" {
+			return fmt.Errorf("%s.proto: manifests cover tcp and udp", at)
+		}
+		if err := s.compile(at); err != nil {
+			return err
+		}
+	}
+	names := map[string]bool{}
+	for i := range m.Services {
+		s := &m.Services[i]
+		at := fmt.Sprintf("services[%d]", i)
+		switch {
+		case s.Name == "":
+			return fmt.Errorf("%s: name is required", at)
+		case names[s.Name]:
+			return fmt.Errorf("%s: duplicate name %q", at, s.Name)
+		case len(s.Ports) == 0:
+			return fmt.Errorf("%s (%s): ports is required", at, s.Name)
+		}
+		names[s.Name] = true
+		switch s.Proto {
+		case "":
+			s.Proto = "tcp"
+		case "tcp", "udp":
+		default:
+			return fmt.Errorf("%s.proto: invalid %q (tcp|udp)", at, s.Proto)
+		}
+		if err := s.compile(at); err != nil {
+			return err
+		}
+		for field, g := range map[string]string{"docker_service": s.DockerService, "cmdline": s.Cmdline} {
+			if _, err := path.Match(g, ""); err != nil {
+				return fmt.Errorf("%s.%s: bad pattern %q", at, field, g)
+			}
+		}
+		for _, h := range s.Hosts {
+			if _, err := path.Match(h, ""); err != nil {
+				return fmt.Errorf("%s.hosts: bad pattern %q", at, h)
+			}
+		}
+		if s.Cmdline != "" {
+			s.cmdline = globRegexp(s.Cmdline)
+		}
+	}
+	return nil
+}
+
+// globRegexp compiles a glob in which * and ? match any character,
+// including /.
+func globRegexp(g string) *regexp.Regexp {
+	q := regexp.QuoteMeta(g)
+	q = strings.NewReplacer(`\*`, `.*`, `\?`, `.`).Replace(q)
+	return regexp.MustCompile(`^` + q + `$`)
+}
+
+// services returns the services declared for host.
+func (m *Manifest) services(host string) []Service {
+	var out []Service
+	for _, s := range m.Services {
+		if len(s.Hosts) == 0 || slices.ContainsFunc(s.Hosts, func(g string) bool {
+			ok, _ := path.Match(g, host)
+			return ok
+		}) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Protos returns the protocols the manifest covers on host; only their
+// listeners can be unexpected.
+func (m *Manifest) Protos(host string) []string {
+	set := map[string]bool{}
+	for _, s := range m.services(host) {
+		set[s.Proto] = true
+	}
+	for _, s := range m.Ignore {
+		if s.Proto != "" {
+			set[s.Proto] = true
+		}
+	}
+	if len(set) == 0 {
+		set["tcp"] = true
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+// UsesDocker reports whether checking host needs the compose service
+// behind each port.
+func (m *Manifest) UsesDocker(host string) bool {
+	return slices.ContainsFunc(m.services(host), func(s Service) bool { return s.DockerService != "" })
+}
+
+// Check compares the listeners on host, by proto, with the manifest: one ok
+// line per declared port that matches, one line per difference otherwise,
+// then one per undeclared port. compose returns the compose service
+// publishing a port ("" for none); it may be nil if no service sets
+// docker_service.
+func (m *Manifest) Check(host string, listeners map[string][]model.Listener, compose func(proto string, port int) string) []model.PortCheck {
+	var out []model.PortCheck
+	declared := map[string]bool{}
+	for _, s := range m.services(host) {
+		for _, port := range slices.Sorted(maps.Keys(s.Ports)) {
+			declared[s.Proto+"/"+strconv.Itoa(port)] = true
+			var ls []model.Listener
+			for _, l := range listeners[s.Proto] {
+				if l.LocalPort == port {
+					ls = append(ls, l)
+				}
+			}
+			out = append(out, s.check(port, ls, compose)...)
+		}
+	}
+	if m.Unexpected == "off" {
+		return out
+	}
+	for _, proto := range slices.Sorted(maps.Keys(listeners)) {
+		byPort := map[int][]model.Listener{}
+		for _, l := range listeners[proto] {
+			if !declared[proto+"/"+strconv.Itoa(l.LocalPort)] && !m.ignored(proto, l) {
+				byPort[l.LocalPort] = append(byPort[l.LocalPort], l)
+			}
+		}
+		for _, port := range slices.Sorted(maps.Keys(byPort)) {
+			c := portCheck("", proto, port, byPort[port])
+			c.Status, c.Severity = "unexpected", m.Unexpected
+			c.Got = owner(c) + " on " + c.LocalIP
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (m *Manifest) ignored(proto string, l model.Listener) bool {
+	f := asFinding(proto, l)
+	for _, s := range m.Ignore {
+		if s.matches(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) check(port int, ls []model.Listener, compose func(proto string, port int) string) []model.PortCheck {
+	c := portCheck(s.Name, s.Proto, port, ls)
+	if len(ls) == 0 {
+		if s.Optional {
+			return nil
+		}
+		c.Status, c.Severity, c.Want, c.Got = "missing", "error", s.want(), "nothing listening"
+		return []model.PortCheck{c}
+	}
+
+	var out []model.PortCheck
+	if s.Address != "" {
+		bind := Selector{Address: s.Address, ip: s.ip, cidr: s.cidr}
+		for _, l := range ls {
+			if !bind.matches(asFinding(s.Proto, l)) {
+				w := c
+				w.Status, w.Severity, w.Want, w.Got = "wrong_bind", "error", "address "+s.Address, "bound to "+c.LocalIP
+				out = append(out, w)
+				break
+			}
+		}
+	}
+
+	l := primary(ls)
+	var want, got []string
+	mismatch := func(field, pattern, value string, ok bool) {
+		if pattern == "" || ok {
+			return
+		}
+		want = append(want, field+" "+pattern)
+		got = append(got, field+" "+nonEmptyOr(value, "-"))
+	}
+	if l.PID <= 0 && l.ProcName == "" && (s.Process != "" || s.User != "" || s.Cmdline != "") {
+		w := c
+		w.Status, w.Severity, w.Want, w.Got = "wrong_owner", "warn", s.want(), "owner not visible (try running with sudo)"
+		out = append(out, w)
+	} else {
+		f := asFinding(s.Proto, l)
+		mismatch("process", s.Process, l.ProcName, (&Selector{Process: s.Process}).matches(f))
+		mismatch("user", s.User, l.User, (&Selector{User: s.User}).matches(f))
+		mismatch("cmdline", s.Cmdline, l.Cmdline, s.cmdline == nil || s.cmdline.MatchString(l.Cmdline))
+	}
+	if s.DockerService != "" {
+		svc := ""
+		if compose != nil {
+			svc = compose(s.Proto, port)
+		}
+		ok, _ := path.Match(s.DockerService, svc)
+		mismatch("docker_service", s.DockerService, svc, ok && svc != "")
+	}
+	if len(want) > 0 {
+		w := c
+		w.Status, w.Severity = "wrong_owner", "error"
+		w.Want, w.Got = strings.Join(want, ", "), strings.Join(got, ", ")
+		out = append(out, w)
+	}
+
+	if len(out) == 0 {
+		c.Status, c.Got = "ok", owner(c)+" on "+c.LocalIP
+		out = append(out, c)
+	}
+	return out
+}
+
+// want describes what the manifest expects of the listener, e.g.
+// "process postgres, address loopback".
+func (s *Service) want() string {
+	var w []string
+	for _, f := range []struct{ field, v string }{
+		{"process", s.Process}, {"user", s.User}, {"cmdline", s.Cmdline}, {"address", s.Address},
+	} {
+		if f.v != "" {
+			w = append(w, f.field+" "+f.v)
+		}
+	}
+	return strings.Join(w, ", ")
+}
+
+// portCheck starts a line for the listeners on one port.
+func portCheck(service, proto string, port int, ls []model.Listener) model.PortCheck {
+	c := model.PortCheck{Service: service, Proto: proto, Port: port}
+	if len(ls) == 0 {
+		return c
+	}
+	var binds []string
+	for _, l := range ls {
+		b := nonEmptyOr(l.LocalIP, "*")
+		if !slices.Contains(binds, b) {
+			binds = append(binds, b)
+		}
+	}
+	l := primary(ls)
+	c.LocalIP = strings.Join(binds, ",")
+	c.PID, c.ProcName, c.User = l.PID, l.ProcName, l.User
+	return c
+}
+
+// primary is the listener whose owner is reported: the first one with a
+// visible process.
+func primary(ls []model.Listener) model.Listener {
+	for _, l := range ls {
+		if l.PID > 0 {
+			return l
+		}
+	}
+	return ls[0]
+}
+
+// owner is "postgres (pid 812)", or "-" when the process is not visible.
+func owner(c model.PortCheck) string {
+	if c.PID <= 0 {
+		return nonEmptyOr(c.ProcName, "-")
+	}
+	return fmt.Sprintf("%s (pid %d)", nonEmptyOr(c.ProcName, "?"), c.PID)
+}
+
+func asFinding(proto string, l model.Listener) model.LintFinding {
+	return model.LintFinding{Proto: proto, Port: l.LocalPort, LocalIP: l.LocalIP, PID: l.PID, ProcName: l.ProcName, User: l.User}
+}
+
+func nonEmptyOr(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
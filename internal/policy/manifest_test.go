@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func TestManifestCheck(t *testing.T) {
+	m, err := LoadManifest("testdata/ports.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Protos("web-1"); len(got) != 1 || got[0] != "tcp" {
+		t.Errorf("Protos = %v", got)
+	}
+	if !m.UsesDocker("db-1") {
+		t.Error("UsesDocker = false")
+	}
+	listeners := map[string][]model.Listener{"tcp": {
+		{LocalIP: "0.0.0.0", LocalPort: 5432, PID: 10, ProcName: "postgres", User: "postgres"},
+		{LocalIP: "127.0.0.1", LocalPort: 80, PID: 11, ProcName: "nginx", User: "root"},
+		{LocalIP: "::", LocalPort: 8080, PID: 12, ProcName: "api", Cmdline: "/srv/api/bin/api --config /etc/api/prod.yaml"},
+		{LocalIP: "0.0.0.0", LocalPort: 6379, PID: 13, ProcName: "docker-proxy"},
+		{LocalIP: "0.0.0.0", LocalPort: 22, PID: 1, ProcName: "sshd"},
+		{LocalIP: "0.0.0.0", LocalPort: 9229, PID: 14, ProcName: "node"},
+		{LocalIP: "::", LocalPort: 9229, PID: 14, ProcName: "node"},
+	}}
+	compose := func(proto string, port int) string {
+		if port == 6379 {
+			return "cache"
+		}
+		return ""
+	}
+	var got []string
+	for _, c := range m.Check("web-1", listeners, compose) {
+		line := fmt.Sprintf("%s %d %s", c.Service, c.Port, c.Status)
+		if c.Status != "ok" {
+			line += fmt.Sprintf(" %s want=%q got=%q", c.Severity, c.Want, c.Got)
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		`postgres 5432 wrong_bind error want="address loopback" got="bound to 0.0.0.0"`,
+		`web 80 ok`,
+		`web 443 missing error want="process nginx" got="nothing listening"`,
+		`api 8080 ok`,
+		`cache 6379 wrong_owner error want="docker_service redis" got="docker_service cache"`,
+		` 9229 unexpected warn want="" got="node (pid 14) on 0.0.0.0,::"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Check:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// web is not declared for db hosts, so port 80 is unexpected there
+	var db []string
+	for _, c := range m.Check("db-1", listeners, compose) {
+		if c.Status == "unexpected" {
+			db = append(db, fmt.Sprint(c.Port))
+		}
+	}
+	if strings.Join(db, ",") != "80,9229" {
+		t.Errorf("unexpected on db-1 = %v", db)
+	}
+
+	// an invisible owner cannot be verified
+	hidden := map[string][]model.Listener{"tcp": {{LocalIP: "127.0.0.1", LocalPort: 5432}}}
+	if c := m.Check("db-1", hidden, nil)[0]; c.Status != "wrong_owner" || c.Severity != "warn" {
+		t.Errorf("hidden owner: %+v", c)
+	}
+}
+
+func TestManifestErrors(t *testing.T) {
+	cases := []struct{ src, want string }{
+		{"services:\n  - ports: 80\n", "services[0]: name is required"},
+		{"services:\n  - name: web\n", "services[0] (web): ports is required"},
+		{"services:\n  - name: web\n    ports: 80\n    bind: 127.0.0.1\n", `services[0]: unknown key "bind"`},
+		{"services:\n  - name: web\n    ports: 80\n    proto: unix\n", `services[0].proto: invalid "unix" (tcp|udp)`},
+		{"services:\n  - name: web\n    ports: 80\n    optional: maybe\n", "expected true or false"},
+		{"unexpected: fail\n", `unexpected: invalid "fail"`},
+		{"ignore:\n  - {}\n", "ignore[0]: ignores every listener"},
+		{"# nothing\n", "manifest is empty"},
+	}
+	for _, c := range cases {
+		_, err := ParseManifest([]byte(c.src))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("ParseManifest(%q) = %v, want %q", c.src, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,606 @@
+// Package policy loads lint policy files (portik lint --policy): severity
+// overrides per finding code, the sensitive and dev port lists, allowlists
+// and custom rules. Files are YAML or JSON:
+//
+//	severity:
+//	  PUBLIC_DEV: warn
+//	  NO_PID: off
+//	sensitive_ports: 5432,6379,9200
+//	allow:
+//	  - codes: [PUBLIC_SENSITIVE]
+//	    process: postgres
+//	    address: 10.0.0.0/8
+//	    reason: db network is firewalled
+//	    expires: 2026-12-31
+//	rules:
+//	  - code: ONLY_NGINX_PUBLIC
+//	    severity: error
+//	    summary: Only nginx may listen publicly
+//	    match: {address: public}
+//	    unless: {process: nginx}
+//
+// Manifests of expected ports (portik check) use the same format; see
+// Manifest.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/ports"
+)
+
+// Policy is a loaded, validated policy file. A nil *Policy applies the
+// built-in defaults.
+type Policy struct {
+	Severity       map[string]string `json:"severity,omitempty"`        // code → info|warn|error|off
+	SensitivePorts PortSet           `json:"sensitive_ports,omitempty"` // replaces the built-in list
+	DevPorts       PortSet           `json:"dev_ports,omitempty"`       // replaces the built-in list
+	Allow          []Allow           `json:"allow,omitempty"`
+	Rules          []Rule            `json:"rules,omitempty"`
+}
+
+// Allow suppresses findings that match it. Codes limits it to some finding
+// codes; every other field set must match too. An entry with Expires stops
+// applying after that day.
+type Allow struct {
+	Codes []string `json:"codes,omitempty"`
+	Selector
+	Reason  string `json:"reason,omitempty"`
+	Expires string `json:"expires,omitempty"` // YYYY-MM-DD, inclusive
+
+	until time.Time
+}
+
+// Suppressed is a finding an allow entry dropped, reported so suppressions
+// stay visible.
+type Suppressed struct {
+	model.LintFinding
+	Reason  string `json:"reason,omitempty"`
+	Expires string `json:"expires,omitempty"`
+}
+
+// Rule reports a finding for every listener that matches Match and not
+// Unless. Summary, details and action may use {port}, {proto}, {pid},
+// {process}, {user} and {address}.
+type Rule struct {
+	Code     string    `json:"code"` // e.g. ONLY_NGINX_PUBLIC
+	Severity string    `json:"severity,omitempty"`
+	Summary  string    `json:"summary"`
+	Details  string    `json:"details,omitempty"`
+	Action   string    `json:"action,omitempty"`
+	Match    Selector  `json:"match"`
+	Unless   *Selector `json:"unless,omitempty"`
+}
+
+// Selector matches findings and listeners; every field that is set must
+// hold.
+type Selector struct {
+	Ports   PortSet `json:"ports,omitempty"`   // 9229, "8000-8099,9000" or a list
+	Proto   string  `json:"proto,omitempty"`   // tcp|udp|unix
+	Process string  `json:"process,omitempty"` // glob on the process name
+	User    string  `json:"user,omitempty"`    // glob on the user
+	Address string  `json:"address,omitempty"` // IP, CIDR, loopback, wildcard or public (not loopback)
+	Path    string  `json:"path,omitempty"`    // glob on a unix socket path
+
+	ip   net.IP
+	cidr *net.IPNet
+}
+
+// DefaultSensitivePorts are services that are risky to expose to the
+// network.
+var DefaultSensitivePorts = PortSet{
+	5432:  true, // postgres
+	3306:  true, // mysql
+	6379:  true, // redis
+	9200:  true, // elasticsearch
+	27017: true, // mongodb
+	11211: true, // memcached
+	15672: true, // rabbitmq mgmt
+	5672:  true, // rabbitmq
+	9092:  true, // kafka
+	2181:  true, // zookeeper
+}
+
+// DefaultDevPorts are ports dev servers usually pick.
+var DefaultDevPorts = PortSet{
+	3000: true,
+	3001: true,
+	5173: true,
+	8000: true,
+	8080: true,
+	8081: true,
+	5000: true,
+	4000: true,
+	9229: true, // node inspector
+}
+
+// Load reads and validates a policy file. builtin lists the codes lint
+// reports itself, which severity and allow entries may name besides the
+// policy's own rules.
+func Load(file string, builtin []string) (*Policy, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(b, builtin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return p, nil
+}
+
+// Parse decodes and validates a policy in YAML or JSON.
+func Parse(b []byte, builtin []string) (*Policy, error) {
+	var p Policy
+	if err := decode(b, "policy", policySchema, &p); err != nil {
+		return nil, err
+	}
+	if err := p.validate(builtin); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// decode reads YAML or JSON into v after checking its keys against s. what
+// names the document in errors.
+func decode(b []byte, what string, s schema, v any) error {
+	var tree any
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '{' {
+		if err := json.Unmarshal(t, &tree); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if tree, err = parseYAML(b); err != nil {
+			return err
+		}
+	}
+	if tree == nil {
+		return fmt.Errorf("%s is empty", what)
+	}
+	if err := checkKeys(tree, s, "", what); err != nil {
+		return err
+	}
+	raw, _ := json.Marshal(tree)
+	if err := json.Unmarshal(raw, v); err != nil {
+		var te *json.UnmarshalTypeError
+		if errors.As(err, &te) {
+			field := listIndex.ReplaceAllString(te.Field, "[$1]") // rules.0.summary → rules[0].summary
+			return fmt.Errorf("%s: expected %s, got %s", field, te.Type, te.Value)
+		}
+		return err
+	}
+	return nil
+}
+
+var (
+	codePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	listIndex   = regexp.MustCompile(`\.(\d+)`)
+)
+
+func (p *Policy) validate(builtin []string) error {
+	known := map[string]bool{}
+	for _, c := range builtin {
+		known[c] = true
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		at := fmt.Sprintf("rules[%d]", i)
+		switch {
+		case r.Code == "":
+			return fmt.Errorf("%s: code is required", at)
+		case !codePattern.MatchString(r.Code):
+			return fmt.Errorf("%s: code %q must be UPPER_SNAKE_CASE like the built-in codes", at, r.Code)
+		case known[r.Code]:
+			return fmt.Errorf("%s: code %s is already used", at, r.Code)
+		case r.Summary == "":
+			return fmt.Errorf("%s (%s): summary is required", at, r.Code)
+		}
+		known[r.Code] = true
+		if r.Severity == "" {
+			r.Severity = "warn"
+		}
+		if !validSeverity(r.Severity) {
+			return fmt.Errorf("%s (%s): invalid severity %q (info|warn|error)", at, r.Code, r.Severity)
+		}
+		if r.Match.empty() {
+			return fmt.Errorf("%s (%s): match must set at least one of ports, proto, process, user, address", at, r.Code)
+		}
+		if r.Match.Path != "" || (r.Unless != nil && r.Unless.Path != "") {
+			return fmt.Errorf("%s (%s): rules apply to tcp and udp listeners; path is only valid in allow", at, r.Code)
+		}
+		if err := r.Match.compile(at + ".match"); err != nil {
+			return err
+		}
+		if r.Unless != nil {
+			if err := r.Unless.compile(at + ".unless"); err != nil {
+				return err
+			}
+		}
+	}
+	for _, code := range slices.Sorted(maps.Keys(p.Severity)) {
+		sev := p.Severity[code]
+		if !known[code] {
+			return fmt.Errorf("severity: unknown code %s", code)
+		}
+		if sev != "off" && !validSeverity(sev) {
+			return fmt.Errorf("severity: %s: invalid severity %q (info|warn|error|off)", code, sev)
+		}
+	}
+	for i := range p.Allow {
+		a := &p.Allow[i]
+		at := fmt.Sprintf("allow[%d]", i)
+		for _, c := range a.Codes {
+			if !known[c] {
+				return fmt.Errorf("%s: unknown code %s", at, c)
+			}
+		}
+		if len(a.Codes) == 0 && a.Selector.empty() {
+			return fmt.Errorf("%s: allows every finding; set codes or a port, process, user, address or path", at)
+		}
+		if err := a.Selector.compile(at); err != nil {
+			return err
+		}
+		if a.Expires != "" {
+			d, err := time.ParseInLocation("2006-01-02", a.Expires, time.Local)
+			if err != nil {
+				return fmt.Errorf("%s.expires: want a date like 2026-12-31, got %q", at, a.Expires)
+			}
+			if a.Reason == "" {
+				return fmt.Errorf("%s: a suppression with expires needs a reason", at)
+			}
+			a.until = d.AddDate(0, 0, 1)
+		}
+	}
+	return nil
+}
+
+func (s *Selector) empty() bool {
+	return s.Ports == nil && s.Proto == "" && s.Process == "" && s.User == "" && s.Address == "" && s.Path == ""
+}
+
+func (s *Selector) compile(at string) error {
+	switch s.Proto {
+	case "", "tcp", "udp", "unix":
+	default:
+		return fmt.Errorf("%s.proto: invalid %q (tcp|udp|unix)", at, s.Proto)
+	}
+	for field, g := range map[string]string{"process": s.Process, "user": s.User, "path": s.Path} {
+		if _, err := path.Match(g, ""); err != nil {
+			return fmt.Errorf("%s.%s: bad pattern %q", at, field, g)
+		}
+	}
+	switch a := s.Address; {
+	case a == "", a == "loopback", a == "wildcard", a == "public":
+	case strings.Contains(a, "/"):
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return fmt.Errorf("%s.address: invalid CIDR %q", at, a)
+		}
+		s.cidr = n
+	default:
+		if s.ip = net.ParseIP(strings.Trim(a, "[]")); s.ip == nil {
+			return fmt.Errorf("%s.address: invalid %q (an IP, CIDR, loopback, wildcard or public)", at, a)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the selector holds for a finding, or for a
+// listener described as one.
+func (s *Selector) matches(f model.LintFinding) bool {
+	if s.Ports != nil && !s.Ports[f.Port] {
+		return false
+	}
+	if s.Proto != "" && s.Proto != f.Proto {
+		return false
+	}
+	for _, c := range []struct{ glob, v string }{{s.Process, f.ProcName}, {s.User, f.User}, {s.Path, f.Path}} {
+		if c.glob == "" {
+			continue
+		}
+		if ok, _ := path.Match(c.glob, c.v); !ok || c.v == "" {
+			return false
+		}
+	}
+	if s.Address == "" {
+		return true
+	}
+	if f.Path != "" {
+		return false
+	}
+	wildcard := isWildcard(f.LocalIP)
+	ip := net.ParseIP(strings.Trim(f.LocalIP, "[]"))
+	switch s.Address {
+	case "wildcard":
+		return wildcard
+	case "loopback":
+		return ip != nil && ip.IsLoopback()
+	case "public":
+		return wildcard || (ip != nil && !ip.IsLoopback())
+	}
+	if ip == nil {
+		return false
+	}
+	if s.cidr != nil {
+		return s.cidr.Contains(ip)
+	}
+	return s.ip.Equal(ip)
+}
+
+func isWildcard(ip string) bool {
+	switch strings.Trim(ip, "[]") {
+	case "", "*", "0.0.0.0", "::":
+		return true
+	}
+	return false
+}
+
+// Sensitive reports whether port is on the sensitive list.
+func (p *Policy) Sensitive(port int) bool {
+	if p == nil || p.SensitivePorts == nil {
+		return DefaultSensitivePorts[port]
+	}
+	return p.SensitivePorts[port]
+}
+
+// Dev reports whether port is on the dev port list.
+func (p *Policy) Dev(port int) bool {
+	if p == nil || p.DevPorts == nil {
+		return DefaultDevPorts[port]
+	}
+	return p.DevPorts[port]
+}
+
+// Check runs the custom rules against a listener. bind is its address as
+// lint reports it.
+func (p *Policy) Check(proto string, l model.Listener, bind string) []model.LintFinding {
+	if p == nil {
+		return nil
+	}
+	base := model.LintFinding{
+		Proto: proto, Port: l.LocalPort, LocalIP: bind,
+		PID: l.PID, ProcName: l.ProcName, User: l.User,
+	}
+	var out []model.LintFinding
+	for _, r := range p.Rules {
+		if !r.Match.matches(base) || (r.Unless != nil && r.Unless.matches(base)) {
+			continue
+		}
+		repl := strings.NewReplacer(
+			"{port}", strconv.Itoa(l.LocalPort),
+			"{proto}", proto,
+			"{pid}", strconv.Itoa(int(l.PID)),
+			"{process}", l.ProcName,
+			"{user}", l.User,
+			"{address}", bind,
+		)
+		f := base
+		f.Severity, f.Code = r.Severity, r.Code
+		f.Summary = repl.Replace(r.Summary)
+		f.Details = repl.Replace(r.Details)
+		f.Action = repl.Replace(r.Action)
+		out = append(out, f)
+	}
+	return out
+}
+
+// LintRules returns builtin, with the policy's severity overrides, followed
+// by the policy's own rules. Codes turned off are left out.
+func (p *Policy) LintRules(builtin []model.LintRule) []model.LintRule {
+	var out []model.LintRule
+	add := func(r model.LintRule) {
+		if p != nil {
+			if sev, ok := p.Severity[r.Code]; ok {
+				if sev == "off" {
+					return
+				}
+				r.Severity = sev
+			}
+		}
+		out = append(out, r)
+	}
+	for _, r := range builtin {
+		add(r)
+	}
+	if p != nil {
+		for _, r := range p.Rules {
+			help := r.Action
+			if r.Details != "" {
+				help = strings.TrimSpace(r.Details + " " + r.Action)
+			}
+			add(model.LintRule{Code: r.Code, Severity: r.Severity, Summary: r.Summary, Help: help})
+		}
+	}
+	return out
+}
+
+// Apply overrides severities and drops findings that are turned off or
+// allowed as of now. Allowed findings are returned as suppressed.
+func (p *Policy) Apply(findings []model.LintFinding, now time.Time) ([]model.LintFinding, []Suppressed) {
+	if p == nil {
+		return findings, nil
+	}
+	var suppressed []Suppressed
+	out := findings[:0]
+	for _, f := range findings {
+		if sev, ok := p.Severity[f.Code]; ok {
+			if sev == "off" {
+				continue
+			}
+			f.Severity = sev
+		}
+		if a, ok := p.allowed(f, now); ok {
+			suppressed = append(suppressed, Suppressed{LintFinding: f, Reason: a.Reason, Expires: a.Expires})
+			continue
+		}
+		out = append(out, f)
+	}
+	return out, suppressed
+}
+
+func (p *Policy) allowed(f model.LintFinding, now time.Time) (Allow, bool) {
+	for _, a := range p.Allow {
+		if !a.until.IsZero() && !now.Before(a.until) {
+			continue
+		}
+		if len(a.Codes) > 0 && !slices.Contains(a.Codes, f.Code) {
+			continue
+		}
+		if a.Selector.matches(f) {
+			return a, true
+		}
+	}
+	return Allow{}, false
+}
+
+// Expired returns the allow entries whose expiry date has passed.
+func (p *Policy) Expired(now time.Time) []Allow {
+	if p == nil {
+		return nil
+	}
+	var out []Allow
+	for _, a := range p.Allow {
+		if !a.until.IsZero() && !now.Before(a.until) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// PortSet is a set of ports, written as a number, a spec such as
+// "8000-8099,9000", or a list of those.
+type PortSet map[int]bool
+
+// MarshalJSON writes the set back as a spec, e.g. "9229,9230-9239".
+func (s PortSet) MarshalJSON() ([]byte, error) {
+	ps := slices.Sorted(maps.Keys(s))
+	var parts []string
+	for i := 0; i < len(ps); {
+		j := i
+		for j+1 < len(ps) && ps[j+1] == ps[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(ps[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", ps[i], ps[j]))
+		}
+		i = j + 1
+	}
+	return json.Marshal(strings.Join(parts, ","))
+}
+
+func (s *PortSet) UnmarshalJSON(b []byte) error {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	set := PortSet{}
+	for _, it := range items {
+		var spec string
+		switch x := it.(type) {
+		case string:
+			spec = x
+		case float64:
+			spec = strconv.FormatFloat(x, 'f', -1, 64)
+		default:
+			return fmt.Errorf("ports: expected a port, a range such as 8000-8099 or a list, got %v", it)
+		}
+		ps, err := ports.ParseSpec(spec)
+		if err != nil {
+			return fmt.Errorf("ports %q: %w", spec, err)
+		}
+		for _, port := range ps {
+			set[port] = true
+		}
+	}
+	*s = set
+	return nil
+}
+
+func validSeverity(s string) bool {
+	return s == "info" || s == "warn" || s == "error"
+}
+
+// schema lists the keys allowed at each level of a policy; nil values are
+// leaves.
+type schema map[string]schema
+
+var selectorKeys = []string{"ports", "proto", "process", "user", "address", "path"}
+
+var policySchema = schema{
+	"severity":        nil,
+	"sensitive_ports": nil,
+	"dev_ports":       nil,
+	"allow":           with(selectorKeys, "codes", "reason", "expires"),
+	"rules": schema{
+		"code": nil, "severity": nil, "summary": nil, "details": nil, "action": nil,
+		"match": with(selectorKeys), "unless": with(selectorKeys),
+	},
+}
+
+func with(keys []string, more ...string) schema {
+	s := schema{}
+	for _, k := range append(append([]string(nil), keys...), more...) {
+		s[k] = nil
+	}
+	return s
+}
+
+// checkKeys rejects unknown keys, so a typo does not silently widen an
+// allowlist or disable a rule. Lists are checked item by item; what names
+// the document.
+func checkKeys(v any, s schema, at, what string) error {
+	switch x := v.(type) {
+	case []any:
+		for i, it := range x {
+			if err := checkKeys(it, s, fmt.Sprintf("%s[%d]", at, i), what); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(x)) {
+			sub, ok := s[k]
+			if !ok {
+				where := at
+				if where == "" {
+					where = what
+				}
+				return fmt.Errorf("%s: unknown key %q (want %s)", where, k, strings.Join(slices.Sorted(maps.Keys(s)), ", "))
+			}
+			if sub != nil {
+				name := k
+				if at != "" {
+					name = at + "." + k
+				}
+				if err := checkKeys(x[k], sub, name, what); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		if at != "" {
+			return fmt.Errorf("%s: expected a mapping or list, got %v", at, v)
+		}
+		return fmt.Errorf("%s must be a mapping", what)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+var builtin = []string{"NO_PID", "PUBLIC_SENSITIVE", "PUBLIC_DEV", "UNIX_WORLD_WRITABLE"}
+
+func TestLoad(t *testing.T) {
+	p, err := Load("testdata/portik-lint.yaml", builtin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Severity["NO_PID"] != "off" || len(p.Allow) != 2 || len(p.Rules) != 2 {
+		t.Fatalf("policy: %+v", p)
+	}
+	if p.Allow[0].Reason != "db network is firewalled # not a comment" {
+		t.Errorf("reason = %q", p.Allow[0].Reason)
+	}
+	if !p.Sensitive(9250) || p.Sensitive(3306) || !p.Dev(3000) {
+		t.Errorf("port lists: sensitive %v, dev %v", p.SensitivePorts, p.DevPorts)
+	}
+	if r := p.Rules[1]; !r.Match.Ports[9229] || !r.Match.Ports[9235] || r.Severity != "error" {
+		t.Errorf("rule: %+v", r)
+	}
+	if r := p.Rules[0]; r.Unless == nil || r.Unless.Process != "nginx" || r.Match.Proto != "tcp" {
+		t.Errorf("rule: %+v", r)
+	}
+	var nilPolicy *Policy
+	if !nilPolicy.Sensitive(5432) || nilPolicy.Sensitive(9250) {
+		t.Error("nil policy does not use the default lists")
+	}
+}
+
+func TestCheckAndApply(t *testing.T) {
+	p, err := Load("testdata/portik-lint.yaml", builtin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := model.Listener{LocalPort: 9229, PID: 42, ProcName: "node", User: "dev"}
+	var codes []string
+	for _, f := range p.Check("tcp", node, "*") {
+		codes = append(codes, f.Code)
+		if f.Code == "ONLY_NGINX_PUBLIC" && f.Summary != "node listens publicly on 9229; only nginx may" {
+			t.Errorf("summary = %q", f.Summary)
+		}
+	}
+	if strings.Join(codes, ",") != "ONLY_NGINX_PUBLIC,NO_INSPECTOR" {
+		t.Errorf("node on *:9229 = %v", codes)
+	}
+	if got := p.Check("tcp", model.Listener{LocalPort: 443, ProcName: "nginx"}, "0.0.0.0"); len(got) != 0 {
+		t.Errorf("nginx: %+v", got)
+	}
+	if got := p.Check("tcp", model.Listener{LocalPort: 8080, ProcName: "node"}, "127.0.0.1"); len(got) != 0 {
+		t.Errorf("loopback listener: %+v", got)
+	}
+
+	in := []model.LintFinding{
+		{Severity: "info", Code: "NO_PID", Port: 22},
+		{Severity: "info", Code: "PUBLIC_DEV", Port: 3000, LocalIP: "*"},
+		{Severity: "warn", Code: "PUBLIC_SENSITIVE", Port: 5432, LocalIP: "10.1.2.3", ProcName: "postgres"},
+		{Severity: "warn", Code: "PUBLIC_SENSITIVE", Port: 5432, LocalIP: "*", ProcName: "postgres"},
+		{Severity: "warn", Code: "UNIX_WORLD_WRITABLE", Proto: "unix", Path: "/run/docker.sock"},
+	}
+	out, suppressed := p.Apply(in, time.Date(2026, 12, 31, 23, 0, 0, 0, time.Local))
+	if len(out) != 2 || out[0].Code != "PUBLIC_DEV" || out[0].Severity != "warn" || out[1].LocalIP != "*" {
+		t.Errorf("Apply = %+v", out)
+	}
+	if len(suppressed) != 2 || suppressed[0].Reason != "db network is firewalled # not a comment" || suppressed[0].Expires != "2026-12-31" {
+		t.Errorf("suppressed = %+v", suppressed)
+	}
+
+	// the day after expiry the finding is back and the entry is reported
+	later := time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local)
+	out, _ = p.Apply([]model.LintFinding{in[2]}, later)
+	if len(out) != 1 || len(p.Expired(later)) != 1 {
+		t.Errorf("after expiry: kept %+v, expired %+v", out, p.Expired(later))
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	p, err := Load("testdata/portik-lint.yaml", builtin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := p.LintRules([]model.LintRule{
+		{Code: "NO_PID", Severity: "info"},
+		{Code: "PUBLIC_DEV", Severity: "info", Help: "bind to loopback"},
+	})
+	var got []string
+	for _, r := range rules {
+		got = append(got, r.Code+":"+r.Severity)
+	}
+	if strings.Join(got, ",") != "PUBLIC_DEV:warn,ONLY_NGINX_PUBLIC:error,NO_INSPECTOR:error" {
+		t.Errorf("rules = %v", got)
+	}
+	if h := rules[2].Help; h != "Port {port} gives code execution to anyone who can reach it" {
+		t.Errorf("help = %q", h)
+	}
+	var nilPolicy *Policy
+	if len(nilPolicy.LintRules(rules)) != 3 {
+		t.Error("nil policy changed the rules")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, c := range []struct{ src, want string }{
+		{"severity:\n  PUBLIC_DEVV: warn\n", "severity: unknown code PUBLIC_DEVV"},
+		{"severity:\n  PUBLIC_DEV: loud\n", `invalid severity "loud"`},
+		{"allow:\n  - proces: nginx\n", `allow[0]: unknown key "proces"`},
+		{"alow: []\n", `policy: unknown key "alow"`},
+		{"allow:\n  - reason: everything\n", "allow[0]: allows every finding"},
+		{"allow:\n  - address: 10.0.0/8\n", `allow[0].address: invalid CIDR "10.0.0/8"`},
+		{"allow:\n  - ports: 70000\n", "ports"},
+		{"allow:\n  - ports: 1\n    reason: x\n    expires: 31/12/2026\n", "allow[0].expires: want a date"},
+		{"allow:\n  - ports: 1\n    expires: 2026-12-31\n", "needs a reason"},
+		{"rules:\n  - code: no-debug\n    summary: x\n    match: {ports: 1}\n", "UPPER_SNAKE_CASE"},
+		{"rules:\n  - code: PUBLIC_DEV\n    summary: x\n    match: {ports: 1}\n", "code PUBLIC_DEV is already used"},
+		{"rules:\n  - code: X\n    summary: x\n", "match must set at least one"},
+		{"rules:\n  - code: X\n    match: {ports: 1}\n", "summary is required"},
+		{"rules:\n  - code: X\n    summary: [a, b]\n    match: {ports: 1}\n", "rules[0].summary: expected string, got array"},
+		{"severity:\n\tNO_PID: off\n", "line 2:"},
+		{"severity:\n  NO_PID: off\n   PUBLIC_DEV: warn\n", "line 3:"},
+		{"severity:\n  NO_PID: off\n  NO_PID: warn\n", `line 3: key "NO_PID" is repeated`},
+		{"# nothing\n", "policy is empty"},
+		{`{"allow": [{"proces": "x"}]}`, `allow[0]: unknown key "proces"`},
+	} {
+		_, err := Parse([]byte(c.src), builtin)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Parse(%q) = %v, want %q", c.src, err, c.want)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	v, err := parseYAML([]byte(`
+defaults: &defaults
+  proto: tcp
+  optional: yes
+top:
+  list: [a, "b, c", 'it''s']
+  map: {k: v, n: ~}
+  items:
+  - x
+  - y: 1
+    z: "2"
+  quoted: "::1"   # trailing comment
+  url: http://x#y
+  date: 2026-12-31
+  text: |
+    two
+    lines
+  svc:
+    <<: *defaults
+    proto: udp
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := v.(map[string]any)["top"].(map[string]any)
+	if l := top["list"].([]any); len(l) != 3 || l[1] != "b, c" || l[2] != "it's" {
+		t.Errorf("list = %#v", l)
+	}
+	if m := top["map"].(map[string]any); m["k"] != "v" || m["n"] != nil {
+		t.Errorf("map = %#v", m)
+	}
+	if items := top["items"].([]any); len(items) != 2 || items[1].(map[string]any)["y"] != "1" || items[1].(map[string]any)["z"] != "2" {
+		t.Errorf("items = %#v", items)
+	}
+	if top["quoted"] != "::1" || top["url"] != "http://x#y" || top["date"] != "2026-12-31" || top["text"] != "two\nlines\n" {
+		t.Errorf("scalars = %#v %#v %#v %#v", top["quoted"], top["url"], top["date"], top["text"])
+	}
+	if svc := top["svc"].(map[string]any); svc["proto"] != "udp" || svc["optional"] != "yes" {
+		t.Errorf("merged = %#v", svc)
+	}
+}
@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML decodes a YAML document into the tree encoding/json produces
+// (map[string]any, []any, scalars), so both formats go through the same
+// schema check. Scalars stay strings whatever YAML would type them as
+// (yes, 2026-12-31, 010); null and ~ are nil. The schema decides what they
+// mean. An empty document is nil.
+func parseYAML(b []byte) (any, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return yamlTree(doc.Content[0])
+}
+
+func yamlTree(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlTree(n.Alias)
+	case yaml.SequenceNode:
+		out := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := yamlTree(c)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case yaml.MappingNode:
+		out := map[string]any{}
+		var merged []any
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, vn := n.Content[i], n.Content[i+1]
+			v, err := yamlTree(vn)
+			if err != nil {
+				return nil, err
+			}
+			if k.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: mapping keys must be plain values", k.Line)
+			}
+			if k.Tag == "!!merge" { // << : *defaults
+				if l, ok := v.([]any); ok {
+					merged = append(merged, l...)
+				} else {
+					merged = append(merged, v)
+				}
+				continue
+			}
+			if _, dup := out[k.Value]; dup {
+				return nil, fmt.Errorf("line %d: key %q is repeated", k.Line, k.Value)
+			}
+			out[k.Value] = v
+		}
+		// keys set in the mapping itself win over merged ones
+		for _, m := range merged {
+			mm, ok := m.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("line %d: << must merge a mapping", n.Line)
+			}
+			for k, v := range mm {
+				if _, set := out[k]; !set {
+					out[k] = v
+				}
+			}
+		}
+		return out, nil
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return nil, nil
+		}
+		return n.Value, nil
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", n.Line)
+}
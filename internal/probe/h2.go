@@ -0,0 +1,209 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// HTTP/2 frame types used by the probe.
+const (
+	frameData      = 0x0
+	frameHeaders   = 0x1
+	frameRSTStream = 0x3
+	frameSettings  = 0x4
+	frameGoAway    = 0x7
+)
+
+const h2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// probeH2C speaks cleartext HTTP/2 with prior knowledge, the way gRPC
+// clients do.
+func probeH2C(addr string, timeout time.Duration) (model.Fingerprint, bool) {
+	c, err := dial(addr, timeout)
+	if err != nil {
+		return model.Fingerprint{}, false
+	}
+	defer c.Close()
+	grpc, ok := h2Request(c, addr, "http")
+	switch {
+	case !ok:
+		return model.Fingerprint{}, false
+	case grpc:
+		return model.Fingerprint{Protocol: "grpc"}, true
+	}
+	return model.Fingerprint{Protocol: "http2"}, true
+}
+
+// h2Request sends a gRPC health check on stream 1 and reads the response
+// headers. Any HTTP/2 server answers; a gRPC server answers with an
+// application/grpc content type or a grpc-status.
+func h2Request(c net.Conn, addr, scheme string) (grpc, ok bool) {
+	r, ok := h2Exchange(c, addr, scheme, false)
+	return r.grpc, ok
+}
+
+// h2Response is what came back on stream 1.
+type h2Response struct {
+	grpc bool   // gRPC content type or grpc-status seen
+	data []byte // DATA payloads, when asked for
+}
+
+// h2Exchange sends the health check request. With wantData it reads stream
+// 1 until it ends, otherwise only up to the response headers. ok is false
+// if the peer does not speak HTTP/2.
+func h2Exchange(c net.Conn, addr, scheme string, wantData bool) (h2Response, bool) {
+	var resp h2Response
+	host, _, _ := net.SplitHostPort(addr)
+	var hdr []byte
+	hdr = append(hdr, 0x83) // :method POST
+	if scheme == "https" {
+		hdr = append(hdr, 0x87)
+	} else {
+		hdr = append(hdr, 0x86)
+	}
+	hdr = appendLiteral(hdr, 0x04, "/grpc.health.v1.Health/Check") // :path
+	hdr = appendLiteral(hdr, 0x01, host)                           // :authority
+	hdr = appendLiteral(hdr, 0x0f, "application/grpc", 0x10)       // content-type (static index 31)
+	hdr = append(hdr, 0x00)
+	hdr = appendString(hdr, "te")
+	hdr = appendString(hdr, "trailers")
+
+	var req bytes.Buffer
+	req.WriteString(h2Preface)
+	req.Write(frame(frameSettings, 0, 0, nil))
+	req.Write(frame(frameHeaders, 0x4, 1, hdr))                // END_HEADERS
+	req.Write(frame(frameData, 0x1, 1, []byte{0, 0, 0, 0, 0})) // END_STREAM, empty message
+	if _, err := c.Write(req.Bytes()); err != nil {
+		return resp, false
+	}
+
+	var head [9]byte
+	for first := true; ; first = false {
+		if _, err := io.ReadFull(c, head[:]); err != nil {
+			return resp, !first
+		}
+		n := int(head[0])<<16 | int(head[1])<<8 | int(head[2])
+		typ, flags := head[3], head[4]
+		stream := binary.BigEndian.Uint32(head[5:]) & 0x7fffffff
+		if first && (typ != frameSettings || stream != 0) {
+			return resp, false // not an HTTP/2 server
+		}
+		if n > 1<<16 {
+			return resp, true
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c, payload); err != nil {
+			return resp, true
+		}
+		if (typ == frameHeaders || typ == frameData) && flags&0x8 != 0 && len(payload) > 0 { // PADDED
+			pad := int(payload[0])
+			if 1+pad > len(payload) {
+				return resp, true
+			}
+			payload = payload[1 : len(payload)-pad]
+		}
+		switch typ {
+		case frameSettings:
+			if flags&0x1 == 0 {
+				_, _ = c.Write(frame(frameSettings, 0x1, 0, nil))
+			}
+		case frameHeaders:
+			if stream != 1 {
+				continue
+			}
+			if flags&0x20 != 0 && len(payload) >= 5 { // PRIORITY
+				payload = payload[5:]
+			}
+			resp.grpc = resp.grpc || isGRPC(payload)
+			if !wantData || flags&0x1 != 0 { // END_STREAM
+				return resp, true
+			}
+		case frameData:
+			if stream != 1 {
+				continue
+			}
+			resp.data = append(resp.data, payload...)
+			if flags&0x1 != 0 {
+				return resp, true
+			}
+		case frameRSTStream, frameGoAway:
+			return resp, true
+		}
+	}
+}
+
+func frame(typ, flags byte, stream uint32, payload []byte) []byte {
+	b := make([]byte, 9, 9+len(payload))
+	b[0], b[1], b[2] = byte(len(payload)>>16), byte(len(payload)>>8), byte(len(payload))
+	b[3], b[4] = typ, flags
+	binary.BigEndian.PutUint32(b[5:], stream)
+	return append(b, payload...)
+}
+
+// appendLiteral encodes a header field without indexing whose name is the
+// static table entry given by prefix (and continuation bytes), e.g. 0x04
+// for :path.
+func appendLiteral(b []byte, name byte, value string, more ...byte) []byte {
+	b = append(b, name)
+	b = append(b, more...)
+	return appendString(b, value)
+}
+
+// appendString encodes a string literal without Huffman coding.
+func appendString(b []byte, s string) []byte {
+	n := len(s)
+	if n < 0x7f {
+		return append(append(b, byte(n)), s...)
+	}
+	b = append(b, 0x7f)
+	for n -= 0x7f; n >= 0x80; n >>= 7 {
+		b = append(b, byte(n&0x7f|0x80))
+	}
+	return append(append(b, byte(n)), s...)
+}
+
+// isGRPC looks for the gRPC content type or a grpc-status header, plain or
+// Huffman-coded, in a header block.
+func isGRPC(block []byte) bool {
+	for _, s := range []string{"application/grpc", "grpc-status"} {
+		if bytes.Contains(block, []byte(s)) || bytes.Contains(block, huffman(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// huffmanCodes holds the HPACK Huffman codes for the characters isGRPC
+// looks for.
+var huffmanCodes = map[byte]struct {
+	code uint32
+	bits int
+}{
+	'a': {0x03, 5}, 'c': {0x04, 5}, 'e': {0x05, 5}, 'i': {0x06, 5}, 'o': {0x07, 5}, 's': {0x08, 5}, 't': {0x09, 5},
+	'-': {0x16, 6}, '/': {0x18, 6}, 'g': {0x26, 6}, 'l': {0x28, 6}, 'n': {0x2a, 6}, 'p': {0x2b, 6}, 'r': {0x2c, 6}, 'u': {0x2d, 6},
+}
+
+// huffman encodes s, padding the last byte with ones.
+func huffman(s string) []byte {
+	var out []byte
+	var acc uint64
+	bits := 0
+	for i := 0; i < len(s); i++ {
+		h := huffmanCodes[s[i]]
+		acc = acc<<h.bits | uint64(h.code)
+		bits += h.bits
+		for bits >= 8 {
+			out = append(out, byte(acc>>(bits-8)))
+			bits -= 8
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(8-bits))|byte(0xff>>bits))
+	}
+	return out
+}
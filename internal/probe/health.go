@@ -0,0 +1,239 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// Check is a readiness check. The zero value only connects.
+type Check struct {
+	Kind    string // tcp|tls|http|grpc; "" is tcp
+	TLS     bool   // http and grpc over TLS
+	Path    string // http: request path
+	Expect  Expect // http: status codes that count as ready; nil is 200-399
+	Timeout time.Duration
+}
+
+// Name is the check as shown to users: tcp, tls, http, https or grpc.
+func (c Check) Name() string {
+	switch {
+	case c.Kind == "":
+		return "tcp"
+	case c.Kind == "http" && c.TLS:
+		return "https"
+	}
+	return c.Kind
+}
+
+// Listener runs the check against a listener, trying each of its addresses
+// until one accepts a connection.
+func (c Check) Listener(l model.Listener) model.Health {
+	var h model.Health
+	for _, addr := range Addrs(l) {
+		h = c.Run(addr, l.LocalPort)
+		if h.OK || !strings.Contains(h.Detail, "refused") {
+			break
+		}
+	}
+	return h
+}
+
+// Run checks addr once. port picks STARTTLS for the tls check on postgres
+// and smtp ports.
+func (c Check) Run(addr string, port int) model.Health {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	h := model.Health{Check: c.Name(), Addr: addr}
+	start := time.Now()
+	var err error
+	switch c.Kind {
+	case "", "tcp":
+		var conn net.Conn
+		if conn, err = dial(addr, c.Timeout); err == nil {
+			conn.Close()
+			h.Detail = "connected"
+		}
+	case "tls":
+		cfg := &tls.Config{InsecureSkipVerify: true}
+		var st tls.ConnectionState
+		if st, err = handshake(addr, startTLSPorts[port], cfg, c.Timeout); err == nil {
+			h.Detail = tls.VersionName(st.Version)
+		}
+	case "http":
+		h.Status, err = c.http(addr)
+		if err == nil {
+			h.Detail = fmt.Sprintf("HTTP %d", h.Status)
+			if !c.Expect.Match(h.Status) {
+				err = fmt.Errorf("HTTP %d, want %s", h.Status, c.Expect)
+			}
+		}
+	case "grpc":
+		h.Detail, err = c.grpc(addr)
+	default:
+		err = fmt.Errorf("unknown check %q", c.Kind)
+	}
+	h.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		h.Detail = dialError(err).Error()
+		return h
+	}
+	h.OK = true
+	return h
+}
+
+// http requests the path without following redirects or verifying the
+// certificate.
+func (c Check) http(addr string) (int, error) {
+	scheme := "http"
+	if c.TLS {
+		scheme = "https"
+	}
+	path := c.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	d := net.Dialer{Timeout: c.Timeout}
+	client := &http.Client{
+		Timeout: c.Timeout,
+		Transport: &http.Transport{
+			DialContext:       func(ctx context.Context, _, _ string) (net.Conn, error) { return d.DialContext(ctx, "tcp", addr) },
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, // local dev certs are rarely trusted
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	req, err := http.NewRequest(http.MethodGet, scheme+"://"+addr+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "portik-health")
+	resp, err := client.Do(req)
+	if err != nil {
+		var ue interface{ Unwrap() error }
+		if errors.As(err, &ue) {
+			err = ue.Unwrap()
+		}
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// grpcServing names the grpc.health.v1 serving states.
+var grpcServing = []string{"UNKNOWN", "SERVING", "NOT_SERVING", "SERVICE_UNKNOWN"}
+
+// grpc calls grpc.health.v1.Health/Check for the server as a whole.
+func (c Check) grpc(addr string) (string, error) {
+	conn, err := dial(addr, c.Timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	scheme := "http"
+	if c.TLS {
+		tc := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h2"}})
+		if err := tc.Handshake(); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrNoTLS, err)
+		}
+		conn, scheme = tc, "https"
+	}
+	resp, ok := h2Exchange(conn, addr, scheme, true)
+	switch {
+	case !ok:
+		return "", errors.New("no HTTP/2 response")
+	case len(resp.data) < 5 && resp.grpc:
+		return "", errors.New("no grpc.health.v1 service")
+	case len(resp.data) < 5:
+		return "", errors.New("not a gRPC server")
+	}
+	// HealthCheckResponse: field 1 (status) is a varint; absent means UNKNOWN.
+	status := 0
+	if msg := resp.data[5:]; len(msg) >= 2 && msg[0] == 0x08 {
+		status = int(msg[1])
+	}
+	name := strconv.Itoa(status)
+	if status < len(grpcServing) {
+		name = grpcServing[status]
+	}
+	if status != 1 {
+		return "", errors.New(name)
+	}
+	return name, nil
+}
+
+// Expect is a set of HTTP status codes, parsed from "200", "2xx",
+// "200-299" or a comma-separated list of those.
+type Expect []struct{ lo, hi int }
+
+// ParseExpect parses an --expect value.
+func ParseExpect(s string) (Expect, error) {
+	var e Expect
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		var lo, hi int
+		var err error
+		switch {
+		case len(part) == 3 && strings.HasSuffix(part, "xx"):
+			lo, err = strconv.Atoi(part[:1])
+			lo *= 100
+			hi = lo + 99
+		case strings.Contains(part, "-"):
+			a, b, _ := strings.Cut(part, "-")
+			if lo, err = strconv.Atoi(a); err == nil {
+				hi, err = strconv.Atoi(b)
+			}
+		default:
+			lo, err = strconv.Atoi(part)
+			hi = lo
+		}
+		if err != nil || lo < 100 || hi > 599 || lo > hi {
+			return nil, fmt.Errorf("invalid status %q (e.g. 200, 2xx, 200-399)", part)
+		}
+		e = append(e, struct{ lo, hi int }{lo, hi})
+	}
+	if len(e) == 0 {
+		return nil, errors.New("empty status list")
+	}
+	return e, nil
+}
+
+// Match reports whether code is expected. A nil Expect accepts 200-399.
+func (e Expect) Match(code int) bool {
+	if e == nil {
+		return code >= 200 && code < 400
+	}
+	for _, r := range e {
+		if code >= r.lo && code <= r.hi {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Expect) String() string {
+	if e == nil {
+		return "200-399"
+	}
+	var parts []string
+	for _, r := range e {
+		if r.lo == r.hi {
+			parts = append(parts, strconv.Itoa(r.lo))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r.lo, r.hi))
+		}
+	}
+	return strings.Join(parts, ",")
+}
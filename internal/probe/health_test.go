@@ -0,0 +1,116 @@
+package probe
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthHTTP(t *testing.T) {
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer web.Close()
+	addr := web.Listener.Addr().String()
+
+	if h := (Check{Kind: "http", Path: "/"}).Run(addr, 0); !h.OK || h.Status != 302 {
+		t.Errorf("redirect with default expect: %+v", h)
+	}
+	h := (Check{Kind: "http", Path: "healthz"}).Run(addr, 0)
+	if h.OK || h.Status != 503 || h.Detail != "HTTP 503, want 200-399" {
+		t.Errorf("503: %+v", h)
+	}
+	e, _ := ParseExpect("5xx")
+	if h := (Check{Kind: "http", Path: "/healthz", Expect: e}).Run(addr, 0); !h.OK {
+		t.Errorf("503 with --expect 5xx: %+v", h)
+	}
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := ln.Addr().String()
+	ln.Close()
+	if h := (Check{}).Run(closed, 0); h.OK || h.Check != "tcp" || !strings.Contains(h.Detail, "refused") {
+		t.Errorf("closed port: %+v", h)
+	}
+}
+
+func TestHealthTLS(t *testing.T) {
+	web := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer web.Close()
+	addr := web.Listener.Addr().String()
+	if h := (Check{Kind: "tls"}).Run(addr, 0); !h.OK || h.Detail != "TLS 1.3" {
+		t.Errorf("tls: %+v", h)
+	}
+	if h := (Check{Kind: "http", TLS: true, Path: "/"}).Run(addr, 0); !h.OK || h.Check != "https" {
+		t.Errorf("https: %+v", h)
+	}
+	plain := serve(t, func(c net.Conn) { c.Read(make([]byte, 512)) })
+	if h := (Check{Kind: "tls"}).Run(plain, 0); h.OK {
+		t.Errorf("plain listener passed the tls check: %+v", h)
+	}
+}
+
+func TestHealthGRPC(t *testing.T) {
+	status := -1 // no health service
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var protos http.Protocols
+	protos.SetUnencryptedHTTP2(true)
+	srv := &http.Server{
+		Protocols: &protos,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/grpc")
+			if status < 0 || r.URL.Path != "/grpc.health.v1.Health/Check" {
+				w.Header().Set("Grpc-Status", "12") // UNIMPLEMENTED, trailers only
+				return
+			}
+			w.Header().Set("Trailer", "Grpc-Status")
+			w.Write([]byte{0, 0, 0, 0, 2, 0x08, byte(status)})
+			w.Header().Set("Grpc-Status", "0")
+		}),
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+	addr := ln.Addr().String()
+	check := Check{Kind: "grpc", Timeout: time.Second}
+
+	if h := check.Run(addr, 0); h.OK || h.Detail != "no grpc.health.v1 service" {
+		t.Errorf("no health service: %+v", h)
+	}
+	status = 2
+	if h := check.Run(addr, 0); h.OK || h.Detail != "NOT_SERVING" {
+		t.Errorf("not serving: %+v", h)
+	}
+	status = 1
+	if h := check.Run(addr, 0); !h.OK || h.Detail != "SERVING" {
+		t.Errorf("serving: %+v", h)
+	}
+}
+
+func TestParseExpect(t *testing.T) {
+	e, err := ParseExpect("200, 3xx,401-403")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for code, want := range map[int]bool{200: true, 204: false, 301: true, 402: true, 404: false} {
+		if e.Match(code) != want {
+			t.Errorf("Match(%d) = %v", code, !want)
+		}
+	}
+	if e.String() != "200,300-399,401-403" {
+		t.Errorf("String() = %s", e)
+	}
+	for _, bad := range []string{"", "abc", "700", "300-200", "9xx"} {
+		if _, err := ParseExpect(bad); err == nil {
+			t.Errorf("ParseExpect(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Package probe connects to a listening socket and identifies the protocol
+// it serves with lightweight handshakes: a server banner (SSH, MySQL), TLS,
+// HTTP/1, HTTP/2 and gRPC, the Postgres SSLRequest and Redis PING.
+//
+// Probes send a few bytes each on fresh connections and never authenticate,
+// but a server may still log them as malformed client requests.
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// DefaultTimeout bounds each connection a probe makes.
+const DefaultTimeout = time.Second
+
+// clientFirst are the probes for protocols where the client speaks first,
+// in the order they are tried unless the port suggests another.
+var clientFirst = []struct {
+	name string
+	run  func(addr string, timeout time.Duration) (model.Fingerprint, bool)
+}{
+	{"tls", probeTLS},
+	{"http", probeHTTP},
+	{"postgres", probePostgres},
+	{"redis", probeRedis},
+	{"http2", probeH2C},
+}
+
+// wellKnown maps ports to the protocols usually served there. It orders the
+// probes and flags listeners that serve something else.
+var wellKnown = map[int][]string{
+	22:    {"ssh"},
+	80:    {"http", "http2"},
+	443:   {"https", "tls", "grpc"},
+	3306:  {"mysql"},
+	5432:  {"postgres"},
+	6379:  {"redis"},
+	8443:  {"https", "tls", "grpc"},
+	50051: {"grpc"},
+}
+
+// Expected returns the protocols usually served on port, or nil.
+func Expected(port int) []string {
+	return wellKnown[port]
+}
+
+// Addrs returns the addresses to dial for a listener: its own address, or
+// loopback for a wildcard bind.
+func Addrs(l model.Listener) []string {
+	port := strconv.Itoa(l.LocalPort)
+	switch ip := strings.Trim(l.LocalIP, "[]"); ip {
+	case "", "*", "0.0.0.0":
+		return []string{net.JoinHostPort("127.0.0.1", port)}
+	case "::":
+		return []string{net.JoinHostPort("::1", port), net.JoinHostPort("127.0.0.1", port)}
+	default:
+		return []string{net.JoinHostPort(ip, port)}
+	}
+}
+
+// Listener fingerprints the service behind a TCP listener, trying each of
+// its addresses until one accepts a connection.
+func Listener(l model.Listener, timeout time.Duration) model.Fingerprint {
+	var fp model.Fingerprint
+	for _, addr := range Addrs(l) {
+		fp = Run(addr, l.LocalPort, timeout)
+		if fp.Protocol != "" || !strings.Contains(fp.Error, "refused") {
+			break
+		}
+	}
+	return fp
+}
+
+// Run fingerprints addr. port only orders the probes.
+func Run(addr string, port int, timeout time.Duration) model.Fingerprint {
+	fp := model.Fingerprint{Addr: addr}
+	banner, err := readBanner(addr, timeout)
+	if err != nil {
+		fp.Error = err.Error()
+		return fp
+	}
+	if len(banner) > 0 {
+		got := classifyBanner(banner)
+		got.Addr = addr
+		return got
+	}
+	for _, p := range ordered(port) {
+		if got, ok := p.run(addr, timeout); ok {
+			got.Addr = addr
+			return got
+		}
+	}
+	fp.Protocol = "unknown"
+	fp.Detail = "no response to tls, http, postgres, redis or http2 probes"
+	return fp
+}
+
+// ordered puts the probe for the port's usual protocol first.
+func ordered(port int) []struct {
+	name string
+	run  func(addr string, timeout time.Duration) (model.Fingerprint, bool)
+} {
+	out := append(clientFirst[:0:0], clientFirst...)
+	want := map[string]bool{}
+	for _, p := range wellKnown[port] {
+		switch p {
+		case "https", "grpc":
+			want["tls"] = true
+		}
+		want[p] = true
+	}
+	first := 0
+	for i, p := range out {
+		if want[p.name] {
+			out[first], out[i] = out[i], out[first]
+			first++
+		}
+	}
+	return out
+}
+
+func dial(addr string, timeout time.Duration) (net.Conn, error) {
+	c, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.SetDeadline(time.Now().Add(timeout))
+	return c, nil
+}
+
+// dialError strips the "dial tcp addr:" prefix, leaving e.g. "connection
+// refused".
+func dialError(err error) error {
+	var op *net.OpError
+	if errors.As(err, &op) && op.Err != nil {
+		return op.Err
+	}
+	return err
+}
+
+// readBanner connects and waits briefly for the server to speak first. A
+// connection failure is returned as an error; silence is an empty banner.
+func readBanner(addr string, timeout time.Duration) ([]byte, error) {
+	c, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, dialError(err)
+	}
+	defer c.Close()
+	_ = c.SetReadDeadline(time.Now().Add(timeout / 2))
+	buf := make([]byte, 512)
+	n, _ := c.Read(buf)
+	return buf[:n], nil
+}
+
+// classifyBanner identifies server-first protocols.
+func classifyBanner(b []byte) model.Fingerprint {
+	switch {
+	case bytes.HasPrefix(b, []byte("SSH-")):
+		line, _, _ := bytes.Cut(b, []byte("\n"))
+		ver := strings.TrimSpace(string(line))
+		if _, v, ok := strings.Cut(strings.TrimPrefix(ver, "SSH-"), "-"); ok {
+			ver = v
+		}
+		return model.Fingerprint{Protocol: "ssh", Server: ver}
+	case bytes.HasPrefix(b, []byte("-DENIED")), bytes.HasPrefix(b, []byte("-ERR")):
+		return model.Fingerprint{Protocol: "redis", Detail: redisDetail(string(b))}
+	}
+	if fp, ok := mysqlGreeting(b); ok {
+		return fp
+	}
+	return model.Fingerprint{Protocol: "unknown", Detail: "banner " + printable(b, 60)}
+}
+
+// mysqlGreeting reads the initial handshake packet (protocol 10) or the
+// error packet MySQL sends to hosts it refuses.
+func mysqlGreeting(b []byte) (model.Fingerprint, bool) {
+	if len(b) < 6 || b[3] != 0 {
+		return model.Fingerprint{}, false
+	}
+	n := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+	if n < 2 || n > 1<<16 || n > len(b)-4+512 {
+		return model.Fingerprint{}, false
+	}
+	switch b[4] {
+	case 0x0a:
+		ver, _, ok := bytes.Cut(b[5:], []byte{0})
+		if !ok {
+			return model.Fingerprint{}, false
+		}
+		v := strings.TrimPrefix(string(ver), "5.5.5-") // MariaDB
+		return model.Fingerprint{Protocol: "mysql", Server: v}, true
+	case 0xff:
+		if len(b) < 7 {
+			return model.Fingerprint{}, false
+		}
+		return model.Fingerprint{Protocol: "mysql", Detail: printable(b[7:], 80)}, true
+	}
+	return model.Fingerprint{}, false
+}
+
+func probeHTTP(addr string, timeout time.Duration) (model.Fingerprint, bool) {
+	c, err := dial(addr, timeout)
+	if err != nil {
+		return model.Fingerprint{}, false
+	}
+	defer c.Close()
+	status, server, ok := httpOver(c, addr)
+	if !ok {
+		return model.Fingerprint{}, false
+	}
+	return model.Fingerprint{Protocol: "http", Status: status, Server: server}, true
+}
+
+// httpOver sends GET / on c and reads the status and Server header.
+func httpOver(c net.Conn, addr string) (int, string, bool) {
+	host, _, _ := net.SplitHostPort(addr)
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	req := "GET / HTTP/1.1\r\nHost: " + host + "\r\nUser-Agent: portik-probe\r\nAccept: */*\r\nConnection: close\r\n\r\n"
+	if _, err := c.Write([]byte(req)); err != nil {
+		return 0, "", false
+	}
+	br := bufio.NewReader(c)
+	if head, err := br.Peek(5); err != nil || string(head) != "HTTP/" {
+		return 0, "", false
+	}
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		return 0, "", false
+	}
+	resp.Body.Close()
+	return resp.StatusCode, resp.Header.Get("Server"), true
+}
+
+// probePostgres sends an SSLRequest; a server answers with one byte, S or
+// N, or with an error message if it is too old to know the request.
+func probePostgres(addr string, timeout time.Duration) (model.Fingerprint, bool) {
+	c, err := dial(addr, timeout)
+	if err != nil {
+		return model.Fingerprint{}, false
+	}
+	defer c.Close()
+	if _, err := c.Write([]byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}); err != nil {
+		return model.Fingerprint{}, false
+	}
+	buf := make([]byte, 64)
+	n, _ := c.Read(buf)
+	switch {
+	case n == 1 && buf[0] == 'S':
+		return model.Fingerprint{Protocol: "postgres", Detail: "TLS available"}, true
+	case n == 1 && buf[0] == 'N':
+		return model.Fingerprint{Protocol: "postgres", Detail: "no TLS"}, true
+	case n > 5 && buf[0] == 'E' && bytes.Contains(buf[:n], []byte("SFATAL")):
+		return model.Fingerprint{Protocol: "postgres"}, true
+	}
+	return model.Fingerprint{}, false
+}
+
+// probeRedis sends an inline PING.
+func probeRedis(addr string, timeout time.Duration) (model.Fingerprint, bool) {
+	c, err := dial(addr, timeout)
+	if err != nil {
+		return model.Fingerprint{}, false
+	}
+	defer c.Close()
+	if _, err := c.Write([]byte("PING\r\n")); err != nil {
+		return model.Fingerprint{}, false
+	}
+	line, err := bufio.NewReader(c).ReadString('\n')
+	if err != nil {
+		return model.Fingerprint{}, false
+	}
+	switch {
+	case strings.HasPrefix(line, "+PONG"):
+		return model.Fingerprint{Protocol: "redis"}, true
+	case len(line) > 2 && line[0] == '-' && line[1] >= 'A' && line[1] <= 'Z':
+		return model.Fingerprint{Protocol: "redis", Detail: redisDetail(line)}, true
+	}
+	return model.Fingerprint{}, false
+}
+
+func redisDetail(reply string) string {
+	switch {
+	case strings.HasPrefix(reply, "-NOAUTH"), strings.HasPrefix(reply, "-WRONGPASS"), strings.Contains(strings.ToLower(reply), "auth"):
+		return "requires AUTH"
+	case strings.HasPrefix(reply, "-DENIED"):
+		return "protected mode"
+	}
+	return ""
+}
+
+// printable quotes at most max bytes of b for display.
+func printable(b []byte, max int) string {
+	if len(b) > max {
+		b = b[:max]
+	}
+	return strconv.Quote(strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,222 @@
+package probe
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// serve accepts connections on a loopback port and hands each to h.
+func serve(t *testing.T, h func(net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_ = c.SetDeadline(time.Now().Add(5 * time.Second))
+				h(c)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func run(addr string) model.Fingerprint {
+	return Run(addr, 0, 500*time.Millisecond)
+}
+
+func TestServerFirst(t *testing.T) {
+	ssh := serve(t, func(c net.Conn) { c.Write([]byte("SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n")) })
+	if fp := run(ssh); fp.Protocol != "ssh" || fp.Server != "OpenSSH_9.6p1 Ubuntu-3ubuntu13" {
+		t.Errorf("ssh: %+v", fp)
+	}
+
+	greeting := append([]byte{0x4a, 0, 0, 0, 0x0a}, "5.5.5-10.11.6-MariaDB\x00\x08\x00\x00\x00"...)
+	mysql := serve(t, func(c net.Conn) { c.Write(greeting) })
+	if fp := run(mysql); fp.Protocol != "mysql" || fp.Server != "10.11.6-MariaDB" {
+		t.Errorf("mysql: %+v", fp)
+	}
+
+	denied := append([]byte{0x3f, 0, 0, 0, 0xff, 0x6a, 0x04}, "Host '10.0.0.9' is not allowed to connect to this MySQL server"...)
+	if fp := run(serve(t, func(c net.Conn) { c.Write(denied) })); fp.Protocol != "mysql" || !strings.Contains(fp.Detail, "not allowed") {
+		t.Errorf("mysql refused host: %+v", fp)
+	}
+
+	if fp := run(serve(t, func(c net.Conn) { c.Write([]byte("220 mail.example.com ESMTP Postfix\r\n")) })); fp.Protocol != "unknown" || !strings.Contains(fp.Detail, "ESMTP") {
+		t.Errorf("other banner: %+v", fp)
+	}
+}
+
+func TestClientFirst(t *testing.T) {
+	redis := serve(t, func(c net.Conn) {
+		if line, _ := bufio.NewReader(c).ReadString('\n'); line == "PING\r\n" {
+			c.Write([]byte("-NOAUTH Authentication required.\r\n"))
+		}
+	})
+	if fp := run(redis); fp.Protocol != "redis" || fp.Detail != "requires AUTH" {
+		t.Errorf("redis: %+v", fp)
+	}
+
+	pg := serve(t, func(c net.Conn) {
+		buf := make([]byte, 8)
+		if n, _ := c.Read(buf); n == 8 && buf[4] == 0x04 && buf[7] == 0x2f {
+			c.Write([]byte("S"))
+		}
+	})
+	if fp := run(pg); fp.Protocol != "postgres" || fp.Detail != "TLS available" {
+		t.Errorf("postgres: %+v", fp)
+	}
+
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "vite")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer web.Close()
+	if fp := run(web.Listener.Addr().String()); fp.Protocol != "http" || fp.Status != 404 || fp.Server != "vite" {
+		t.Errorf("http: %+v", fp)
+	}
+
+	silent := serve(t, func(c net.Conn) { time.Sleep(time.Second) })
+	if fp := run(silent); fp.Protocol != "unknown" {
+		t.Errorf("silent: %+v", fp)
+	}
+}
+
+func TestTLS(t *testing.T) {
+	web := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "caddy")
+	}))
+	defer web.Close()
+	fp := run(web.Listener.Addr().String())
+	if fp.Protocol != "https" || !fp.TLS || fp.Status != 200 || fp.Server != "caddy" {
+		t.Fatalf("https: %+v", fp)
+	}
+	if fp.Cert == nil || fp.Cert.NotAfter.IsZero() || len(fp.Cert.DNSNames) == 0 {
+		t.Fatalf("cert: %+v", fp.Cert)
+	}
+
+	grpc := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "12")
+	}))
+	grpc.EnableHTTP2 = true
+	grpc.StartTLS()
+	defer grpc.Close()
+	if fp := run(grpc.Listener.Addr().String()); fp.Protocol != "grpc" || fp.ALPN != "h2" {
+		t.Fatalf("grpc over tls: %+v", fp)
+	}
+}
+
+func TestH2C(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var protos http.Protocols
+	protos.SetUnencryptedHTTP2(true)
+	srv := &http.Server{
+		Protocols: &protos,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Content-Type") == "application/grpc" {
+				w.Header().Set("Content-Type", "application/grpc")
+			}
+		}),
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+	if fp := run(ln.Addr().String()); fp.Protocol != "grpc" || fp.TLS {
+		t.Fatalf("h2c grpc: %+v", fp)
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	var names []string
+	for _, p := range ordered(5432) {
+		names = append(names, p.name)
+	}
+	if got := strings.Join(names, ","); !strings.HasPrefix(got, "postgres,") {
+		t.Fatalf("5432 order = %s", got)
+	}
+	if got := ordered(12345)[0].name; got != "tls" {
+		t.Fatalf("default order starts with %s", got)
+	}
+}
+
+func TestAddrs(t *testing.T) {
+	got := Addrs(model.Listener{LocalIP: "::", LocalPort: 8080})
+	if strings.Join(got, " ") != "[::1]:8080 127.0.0.1:8080" {
+		t.Fatalf("Addrs(::) = %v", got)
+	}
+	if got := Addrs(model.Listener{LocalIP: "10.0.0.4", LocalPort: 22}); got[0] != "10.0.0.4:22" {
+		t.Fatalf("Addrs(10.0.0.4) = %v", got)
+	}
+}
+
+func TestInspectTLS(t *testing.T) {
+	web := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer web.Close()
+	addr := web.Listener.Addr().String()
+	info, err := InspectTLS(addr, 0, TLSOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "TLS 1.3" || len(info.Chain) != 1 || info.StartTLS != "" {
+		t.Fatalf("info: %+v", info)
+	}
+	if v := strings.Join(info.Versions, ","); !strings.HasSuffix(v, "TLS 1.2,TLS 1.3") {
+		t.Fatalf("versions = %s", v)
+	}
+	leaf := info.Chain[0]
+	if leaf.Key != "RSA" || leaf.KeyBits != 2048 || !leaf.Covers("127.0.0.1") || !leaf.Covers("example.com") {
+		t.Fatalf("leaf: %+v", leaf)
+	}
+
+	plain := serve(t, func(c net.Conn) { c.Read(make([]byte, 512)) })
+	if _, err := InspectTLS(plain, 0, TLSOptions{}); !errors.Is(err, ErrNoTLS) {
+		t.Fatalf("plain listener: %v", err)
+	}
+
+	cfg := web.TLS
+	pg := serve(t, func(c net.Conn) {
+		buf := make([]byte, 8)
+		if n, _ := c.Read(buf); n != 8 {
+			return
+		}
+		c.Write([]byte("S"))
+		tls.Server(c, cfg).Handshake()
+	})
+	if info, err := InspectTLS(pg, 5432, TLSOptions{StartTLS: "postgres"}); err != nil || info.StartTLS != "postgres" || len(info.Chain) != 1 {
+		t.Fatalf("postgres STARTTLS: %+v %v", info, err)
+	}
+
+	smtp := serve(t, func(c net.Conn) {
+		br := bufio.NewReader(c)
+		c.Write([]byte("220 mx ESMTP\r\n"))
+		br.ReadString('\n')
+		c.Write([]byte("250-mx\r\n250-PIPELINING\r\n250 STARTTLS\r\n"))
+		if line, _ := br.ReadString('\n'); line == "STARTTLS\r\n" {
+			c.Write([]byte("220 go ahead\r\n"))
+			tls.Server(c, cfg).Handshake()
+		}
+	})
+	if info, err := InspectTLS(smtp, 587, TLSOptions{}); err != nil || info.StartTLS != "smtp" {
+		t.Fatalf("smtp STARTTLS: %+v %v", info, err)
+	}
+}
@@ -0,0 +1,239 @@
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// probeTLS completes a TLS handshake without verifying the certificate,
+// then asks for / over HTTP/2 or HTTP/1.1, whichever was negotiated.
+func probeTLS(addr string, timeout time.Duration) (model.Fingerprint, bool) {
+	c, err := dial(addr, timeout)
+	if err != nil {
+		return model.Fingerprint{}, false
+	}
+	defer c.Close()
+	tc := tls.Client(c, &tls.Config{
+		InsecureSkipVerify: true, // identify the service; trust is not the question here
+		NextProtos:         []string{"h2", "http/1.1"},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return model.Fingerprint{}, false
+	}
+	st := tc.ConnectionState()
+	fp := model.Fingerprint{Protocol: "tls", TLS: true, ALPN: st.NegotiatedProtocol}
+	if len(st.PeerCertificates) > 0 {
+		c := certInfo(st.PeerCertificates[0])
+		fp.Cert = &c
+	}
+	if st.NegotiatedProtocol == "h2" {
+		if grpc, ok := h2Request(tc, addr, "https"); ok {
+			fp.Protocol = "https"
+			if grpc {
+				fp.Protocol = "grpc"
+			}
+		}
+		return fp, true
+	}
+	if status, server, ok := httpOver(tc, addr); ok {
+		fp.Protocol, fp.Status, fp.Server = "https", status, server
+	}
+	return fp, true
+}
+
+// TLSOptions control InspectTLS.
+type TLSOptions struct {
+	ServerName string // SNI; none when empty
+	// StartTLS is how to reach TLS: "postgres" or "smtp" upgrade a
+	// plaintext session, "none" handshakes at once, and "" picks by port.
+	StartTLS string
+	Timeout  time.Duration
+}
+
+// startTLSPorts are the ports where TLS is negotiated in-protocol.
+var startTLSPorts = map[int]string{5432: "postgres", 25: "smtp", 587: "smtp", 2525: "smtp"}
+
+// ErrNoTLS is returned when the listener does not complete a handshake.
+var ErrNoTLS = errors.New("no TLS handshake")
+
+var tlsVersions = []struct {
+	v    uint16
+	name string
+}{
+	{tls.VersionTLS10, "TLS 1.0"},
+	{tls.VersionTLS11, "TLS 1.1"},
+	{tls.VersionTLS12, "TLS 1.2"},
+	{tls.VersionTLS13, "TLS 1.3"},
+}
+
+// InspectTLS handshakes with addr and reports the certificate chain, the
+// negotiated parameters and every protocol version the server accepts.
+// The certificate is not verified: expired and self-signed chains are what
+// it is for.
+func InspectTLS(addr string, port int, opt TLSOptions) (model.TLSInfo, error) {
+	if opt.Timeout <= 0 {
+		opt.Timeout = DefaultTimeout
+	}
+	mode := opt.StartTLS
+	if mode == "" {
+		mode = startTLSPorts[port]
+	}
+	if mode == "none" {
+		mode = ""
+	}
+	info := model.TLSInfo{Addr: addr, ServerName: opt.ServerName, StartTLS: mode}
+	cfg := &tls.Config{
+		InsecureSkipVerify: true,
+		ServerName:         opt.ServerName,
+		NextProtos:         []string{"h2", "http/1.1"},
+	}
+	if mode == "postgres" {
+		cfg.NextProtos = nil
+	}
+	st, err := handshake(addr, mode, cfg, opt.Timeout)
+	if err != nil {
+		return info, err
+	}
+	info.Version = tls.VersionName(st.Version)
+	info.Cipher = tls.CipherSuiteName(st.CipherSuite)
+	info.ALPN = st.NegotiatedProtocol
+	for _, c := range st.PeerCertificates {
+		info.Chain = append(info.Chain, certInfo(c))
+	}
+	for _, v := range tlsVersions {
+		if v.v == st.Version {
+			info.Versions = append(info.Versions, v.name)
+			continue
+		}
+		only := cfg.Clone()
+		only.MinVersion, only.MaxVersion = v.v, v.v
+		if _, err := handshake(addr, mode, only, opt.Timeout); err == nil {
+			info.Versions = append(info.Versions, v.name)
+		}
+	}
+	return info, nil
+}
+
+// handshake connects, upgrades the session for mode and completes a TLS
+// handshake.
+func handshake(addr, mode string, cfg *tls.Config, timeout time.Duration) (tls.ConnectionState, error) {
+	c, err := dial(addr, timeout)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer c.Close()
+	switch mode {
+	case "postgres":
+		err = startPostgres(c)
+	case "smtp":
+		err = startSMTP(c)
+	}
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	tc := tls.Client(c, cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := tc.HandshakeContext(ctx); err != nil {
+		var rh tls.RecordHeaderError
+		if errors.As(err, &rh) {
+			return tls.ConnectionState{}, ErrNoTLS
+		}
+		return tls.ConnectionState{}, fmt.Errorf("%w: %v", ErrNoTLS, err)
+	}
+	return tc.ConnectionState(), nil
+}
+
+// startPostgres asks for TLS with an SSLRequest.
+func startPostgres(c net.Conn) error {
+	if _, err := c.Write([]byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}); err != nil {
+		return err
+	}
+	b := make([]byte, 1)
+	if _, err := c.Read(b); err != nil {
+		return err
+	}
+	if b[0] != 'S' {
+		return fmt.Errorf("%w: postgres server does not offer TLS", ErrNoTLS)
+	}
+	return nil
+}
+
+// startSMTP issues EHLO and STARTTLS.
+func startSMTP(c net.Conn) error {
+	br := bufio.NewReader(c)
+	reply := func() (string, error) {
+		var last string
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				return "", err
+			}
+			last += line
+			if len(line) < 4 || line[3] != '-' {
+				return last, nil
+			}
+		}
+	}
+	if r, err := reply(); err != nil || !strings.HasPrefix(r, "220") {
+		return fmt.Errorf("%w: no SMTP greeting", ErrNoTLS)
+	}
+	if _, err := c.Write([]byte("EHLO portik\r\n")); err != nil {
+		return err
+	}
+	r, err := reply()
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(strings.ToUpper(r), "STARTTLS") {
+		return fmt.Errorf("%w: SMTP server does not offer STARTTLS", ErrNoTLS)
+	}
+	if _, err := c.Write([]byte("STARTTLS\r\n")); err != nil {
+		return err
+	}
+	if r, err := reply(); err != nil || !strings.HasPrefix(r, "220") {
+		return fmt.Errorf("%w: STARTTLS refused", ErrNoTLS)
+	}
+	return nil
+}
+
+// certInfo summarises a certificate.
+func certInfo(c *x509.Certificate) model.Cert {
+	out := model.Cert{
+		Subject:    c.Subject.String(),
+		Issuer:     c.Issuer.String(),
+		DNSNames:   c.DNSNames,
+		NotBefore:  c.NotBefore,
+		NotAfter:   c.NotAfter,
+		SelfSigned: bytes.Equal(c.RawIssuer, c.RawSubject) && c.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature) == nil,
+		IsCA:       c.IsCA,
+		Signature:  c.SignatureAlgorithm.String(),
+	}
+	for _, ip := range c.IPAddresses {
+		out.IPs = append(out.IPs, ip.String())
+	}
+	switch k := c.PublicKey.(type) {
+	case *rsa.PublicKey:
+		out.Key, out.KeyBits = "RSA", k.N.BitLen()
+	case *ecdsa.PublicKey:
+		out.Key, out.KeyBits = "ECDSA", k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		out.Key, out.KeyBits = "Ed25519", 256
+	}
+	return out
+}
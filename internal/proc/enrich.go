@@ -1,36 +1,112 @@
 package proc
 
 import (
-	"bytes"
-	"os/exec"
 	"strings"
 
 	"github.com/pratik-anurag/portik/internal/model"
 )
 
+// Source looks up processes. Live reads the running system (or a replayed
+// bundle); callers may plug in their own.
+type Source interface {
+	Lookup(pid int32) (Info, bool)
+}
+
+type live struct{}
+
+func (live) Lookup(pid int32) (Info, bool) { return Lookup(pid) }
+
+// Live is the process table of the running system.
+var Live Source = live{}
+
 func Enrich(l *model.Listener) {
+	EnrichFrom(Live, l)
+}
+
+// EnrichFrom is Enrich with process details from src.
+func EnrichFrom(src Source, l *model.Listener) {
+	enrichHolders(src, l)
 	if l.PID <= 0 {
 		return
 	}
-	l.ProcName = firstNonEmpty(l.ProcName, psField(l.PID, "comm="))
-	l.User = firstNonEmpty(l.User, psField(l.PID, "user="))
-	l.Cmdline = firstNonEmpty(l.Cmdline, compact(psField(l.PID, "command=")))
-	l.IsZombie = strings.Contains(strings.ToUpper(psField(l.PID, "stat=")), "Z")
+	info, ok := src.Lookup(l.PID)
+	if !ok {
+		return
+	}
+	l.ProcName = firstNonEmpty(l.ProcName, info.Name)
+	l.User = firstNonEmpty(l.User, info.User)
+	l.Cmdline = firstNonEmpty(l.Cmdline, compact(info.Cmdline))
+	l.WorkingDir = firstNonEmpty(l.WorkingDir, info.Cwd)
+	l.IsZombie = info.IsZombie()
+	l.Container = firstNonEmpty(l.Container, info.Container)
 }
 
-func EnrichConn(c *model.Conn) {
-	if c.PID <= 0 {
+// enrichHolders fills in parent pids for every socket holder and marks the
+// master/worker relationship: a holder whose parent also holds the socket is
+// a worker. When the holders form a single tree, the listener is attributed
+// to its master so kill/restart act on the process that owns the workers.
+func enrichHolders(src Source, l *model.Listener) {
+	if len(l.Holders) == 0 {
+		return
+	}
+	seen := map[int32]bool{}
+	holders := l.Holders[:0]
+	for _, h := range l.Holders {
+		if h.PID <= 0 || seen[h.PID] {
+			continue
+		}
+		seen[h.PID] = true
+		if info, ok := src.Lookup(h.PID); ok {
+			h.PPID = info.PPID
+			h.ProcName = firstNonEmpty(h.ProcName, info.Name)
+		}
+		holders = append(holders, h)
+	}
+	l.Holders = holders
+	if len(holders) < 2 {
 		return
 	}
-	c.ProcName = firstNonEmpty(c.ProcName, psField(c.PID, "comm="))
+
+	parents := map[int32]bool{}
+	var roots []int
+	for i := range holders {
+		if seen[holders[i].PPID] {
+			holders[i].Role = "worker"
+			parents[holders[i].PPID] = true
+		} else {
+			roots = append(roots, i)
+		}
+	}
+	for _, i := range roots {
+		if parents[holders[i].PID] {
+			holders[i].Role = "master"
+		}
+	}
+	if len(roots) != 1 {
+		return
+	}
+	m := holders[roots[0]]
+	if l.PID != m.PID {
+		l.PID = m.PID
+		l.ProcName = m.ProcName
+		l.Cmdline = ""
+		l.WorkingDir = ""
+		l.User = ""
+	}
 }
 
-func psField(pid int32, format string) string {
-	cmd := exec.Command("ps", "-p", itoa32(pid), "-o", format)
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	_ = cmd.Run()
-	return strings.TrimSpace(buf.String())
+func EnrichConn(c *model.Conn) {
+	EnrichConnFrom(Live, c)
+}
+
+// EnrichConnFrom is EnrichConn with process details from src.
+func EnrichConnFrom(src Source, c *model.Conn) {
+	if c.PID <= 0 {
+		return
+	}
+	if info, ok := src.Lookup(c.PID); ok {
+		c.ProcName = firstNonEmpty(c.ProcName, info.Name)
+	}
 }
 
 func compact(s string) string {
@@ -0,0 +1,41 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func TestEnrichHoldersPicksMaster(t *testing.T) {
+	// pids beyond pid_max so Lookup finds nothing and the given PPIDs stand
+	l := model.Listener{
+		PID:      99999902,
+		ProcName: "worker",
+		Holders: []model.SocketHolder{
+			{PID: 99999902, PPID: 99999901, ProcName: "nginx", FD: 6},
+			{PID: 99999903, PPID: 99999901, ProcName: "nginx", FD: 6},
+			{PID: 99999901, PPID: 1, ProcName: "nginx", FD: 6},
+		},
+	}
+	enrichHolders(Live, &l)
+	if l.PID != 99999901 {
+		t.Fatalf("expected listener attributed to master 99999901, got %d", l.PID)
+	}
+	if len(l.Workers()) != 2 || l.Holders[2].Role != "master" {
+		t.Fatalf("unexpected roles: %+v", l.Holders)
+	}
+}
+
+func TestEnrichHoldersUnrelated(t *testing.T) {
+	l := model.Listener{
+		PID: 99999911,
+		Holders: []model.SocketHolder{
+			{PID: 99999911, PPID: 1},
+			{PID: 99999912, PPID: 2},
+		},
+	}
+	enrichHolders(Live, &l)
+	if l.PID != 99999911 || l.Holders[0].Role != "" || l.Holders[1].Role != "" {
+		t.Fatalf("expected no roles for unrelated holders, got pid=%d %+v", l.PID, l.Holders)
+	}
+}
@@ -0,0 +1,180 @@
+package proc
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Info is a snapshot of one process. All fields come from a single read so
+// they describe the same moment (on Linux: /proc/<pid>/{stat,status,cmdline,cwd,exe,cgroup}).
+type Info struct {
+	PID     int32  `json:"pid"`
+	PPID    int32  `json:"ppid,omitempty"`
+	Name    string `json:"name,omitempty"`
+	User    string `json:"user,omitempty"`
+	UID     int    `json:"uid"` // -1 if unknown
+	State   string `json:"state,omitempty"`
+	Cmdline string `json:"cmdline,omitempty"`
+	Cwd     string `json:"cwd,omitempty"`
+	Exe     string `json:"exe,omitempty"`
+
+	// Cgroup is the raw /proc/<pid>/cgroup (Linux).
+	Cgroup string `json:"cgroup,omitempty"`
+	// Container is the short id of the container the process runs in,
+	// e.g. "docker:3f2a9c1b7d4e" (Linux, from the cgroup path).
+	Container string `json:"container,omitempty"`
+}
+
+func (i Info) IsZombie() bool {
+	return strings.Contains(strings.ToUpper(i.State), "Z")
+}
+
+// cacheTTL bounds how long a snapshot is reused. It is long enough for one
+// command run to read each pid once, and short enough for watch/daemon loops
+// to observe restarts.
+const cacheTTL = 2 * time.Second
+
+type cacheEntry struct {
+	info Info
+	ok   bool
+	at   time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[int32]cacheEntry{}
+
+	// replayed, when set, is the whole process table (see Replay).
+	replayed map[int32]Info
+)
+
+// Lookup returns process info for pid, reading it at most once per cacheTTL.
+// All enrichment call sites share this cache.
+func Lookup(pid int32) (Info, bool) {
+	if pid <= 0 {
+		return Info{}, false
+	}
+	if replayed != nil {
+		info, ok := replayed[pid]
+		return info, ok
+	}
+	now := time.Now()
+	cacheMu.Lock()
+	e, hit := cache[pid]
+	cacheMu.Unlock()
+	if hit && now.Sub(e.at) < cacheTTL {
+		return e.info, e.ok
+	}
+
+	info, ok := readInfo(pid)
+	cacheMu.Lock()
+	cache[pid] = cacheEntry{info: info, ok: ok, at: now}
+	cacheMu.Unlock()
+	return info, ok
+}
+
+// Replay makes Lookup answer from a captured process table instead of the
+// live system.
+func Replay(infos []Info) {
+	replayed = make(map[int32]Info, len(infos))
+	for _, info := range infos {
+		replayed[info.PID] = info
+	}
+}
+
+// Replaying reports whether Lookup answers from a captured table.
+func Replaying() bool {
+	return replayed != nil
+}
+
+// Forget drops any cached info for pid, e.g. after signalling it.
+func Forget(pid int32) {
+	cacheMu.Lock()
+	delete(cache, pid)
+	cacheMu.Unlock()
+}
+
+// parseStat parses /proc/<pid>/stat. comm is enclosed in parentheses and may
+// itself contain spaces or ')', so split on the last ')'.
+//
+//	1234 (my proc) S 1 1234 1234 0 -1 ...
+func parseStat(s string) (name, state string, ppid int32, ok bool) {
+	lp := strings.IndexByte(s, '(')
+	rp := strings.LastIndexByte(s, ')')
+	if lp < 0 || rp < lp {
+		return "", "", 0, false
+	}
+	name = s[lp+1 : rp]
+	rest := strings.Fields(s[rp+1:])
+	if len(rest) < 2 {
+		return "", "", 0, false
+	}
+	return name, rest[0], atoi32(rest[1]), true
+}
+
+// parseStatusUID returns the effective uid from /proc/<pid>/status
+// ("Uid:	real	effective	saved	fs").
+func parseStatusUID(s string) (int, bool) {
+	for _, line := range strings.Split(s, "\n") {
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+		f := strings.Fields(strings.TrimPrefix(line, "Uid:"))
+		if len(f) < 2 {
+			return -1, false
+		}
+		return int(atoi32(f[1])), true
+	}
+	return -1, false
+}
+
+// cmdlineString turns NUL-separated /proc/<pid>/cmdline into a shell-ish line.
+func cmdlineString(b []byte) string {
+	s := strings.TrimRight(string(b), "\x00")
+	return strings.ReplaceAll(s, "\x00", " ")
+}
+
+func atoi32(s string) int32 {
+	s = strings.TrimSpace(s)
+	sign := int32(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+		s = s[1:]
+	}
+	var n int32
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			break
+		}
+		n = n*10 + int32(r-'0')
+	}
+	return sign * n
+}
+
+// Container runtimes name cgroups after the 64-hex container id:
+//
+//	0::/system.slice/docker-<id>.scope
+//	0::/kubepods.slice/.../cri-containerd-<id>.scope
+//	12:pids:/docker/<id>
+var reCgroupContainer = regexp.MustCompile(`(?:(docker|cri-containerd|crio|libpod|containerd)[-/]|kubepods\S*/)([0-9a-f]{64})`)
+
+// parseCgroupContainer returns "<runtime>:<short id>" for the first
+// container id found in /proc/<pid>/cgroup, or "".
+func parseCgroupContainer(s string) string {
+	m := reCgroupContainer.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	runtime := m[1]
+	switch runtime {
+	case "":
+		runtime = "kubepods"
+	case "cri-containerd":
+		runtime = "containerd"
+	case "libpod":
+		runtime = "podman"
+	}
+	return runtime + ":" + m[2][:12]
+}
@@ -0,0 +1,63 @@
+//go:build linux
+
+package proc
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+)
+
+func readInfo(pid int32) (Info, bool) {
+	dir := "/proc/" + itoa32(pid)
+	stat, err := os.ReadFile(dir + "/stat")
+	if err != nil {
+		return Info{}, false
+	}
+	name, state, ppid, ok := parseStat(string(stat))
+	if !ok {
+		return Info{}, false
+	}
+	info := Info{PID: pid, PPID: ppid, Name: name, State: state, UID: -1}
+
+	if b, err := os.ReadFile(dir + "/status"); err == nil {
+		if uid, ok := parseStatusUID(string(b)); ok {
+			info.UID = uid
+			info.User = usernameForUID(uid)
+		}
+	}
+	if b, err := os.ReadFile(dir + "/cmdline"); err == nil {
+		info.Cmdline = cmdlineString(b)
+	}
+	if info.Cmdline == "" {
+		// kernel threads and zombies have no argv; mirror ps
+		info.Cmdline = "[" + name + "]"
+	}
+	info.Cwd, _ = os.Readlink(dir + "/cwd")
+	info.Exe, _ = os.Readlink(dir + "/exe")
+	if b, err := os.ReadFile(dir + "/cgroup"); err == nil {
+		info.Cgroup = string(b)
+		info.Container = parseCgroupContainer(info.Cgroup)
+	}
+	return info, true
+}
+
+var (
+	usersMu sync.Mutex
+	users   = map[int]string{}
+)
+
+func usernameForUID(uid int) string {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	if name, ok := users[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	users[uid] = name
+	return name
+}
@@ -0,0 +1,35 @@
+//go:build !linux
+
+package proc
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// readInfo uses two ps invocations: the fixed-width fields plus the command
+// line (which may contain spaces), and comm (which may too on darwin).
+func readInfo(pid int32) (Info, bool) {
+	out, err := exec.Command("ps", "-p", itoa32(pid), "-o", "ppid=,uid=,user=,stat=,command=").Output()
+	if err != nil {
+		return Info{}, false
+	}
+	f := strings.Fields(strings.TrimSpace(string(out)))
+	if len(f) < 4 {
+		return Info{}, false
+	}
+	info := Info{
+		PID:   pid,
+		PPID:  atoi32(f[0]),
+		UID:   int(atoi32(f[1])),
+		User:  f[2],
+		State: f[3],
+	}
+	if len(f) > 4 {
+		info.Cmdline = strings.Join(f[4:], " ")
+	}
+	if comm, err := exec.Command("ps", "-p", itoa32(pid), "-o", "comm=").Output(); err == nil {
+		info.Name = strings.TrimSpace(string(comm))
+	}
+	return info, true
+}
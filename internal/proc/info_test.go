@@ -0,0 +1,49 @@
+package proc
+
+import "testing"
+
+func TestParseStat(t *testing.T) {
+	name, state, ppid, ok := parseStat("4242 (tmux: server) S 1 4242 4242 0 -1 4194560 1450 0 0 0")
+	if !ok || name != "tmux: server" || state != "S" || ppid != 1 {
+		t.Fatalf("unexpected stat parse: name=%q state=%q ppid=%d ok=%v", name, state, ppid, ok)
+	}
+	name, _, _, ok = parseStat("99 (a) b)) Z 7 99")
+	if !ok || name != "a) b)" {
+		t.Fatalf("expected comm with parens, got %q ok=%v", name, ok)
+	}
+	if _, _, _, ok := parseStat("garbage"); ok {
+		t.Fatalf("expected parse failure")
+	}
+}
+
+func TestParseStatusUID(t *testing.T) {
+	status := "Name:\tnginx\nState:\tS (sleeping)\nUid:\t0\t33\t33\t33\nGid:\t0\t33\t33\t33\n"
+	uid, ok := parseStatusUID(status)
+	if !ok || uid != 33 {
+		t.Fatalf("expected effective uid 33, got %d ok=%v", uid, ok)
+	}
+}
+
+func TestCmdlineString(t *testing.T) {
+	got := cmdlineString([]byte("python3\x00-m\x00http.server\x008000\x00"))
+	if got != "python3 -m http.server 8000" {
+		t.Fatalf("unexpected cmdline: %q", got)
+	}
+}
+
+func TestParseCgroupContainer(t *testing.T) {
+	id := "4f1c2d3e4b5a69788796a5b4c3d2e1f00112233445566778899aabbccddeeff0"
+	cases := map[string]string{
+		"0::/system.slice/docker-" + id + ".scope\n":                                   "docker:4f1c2d3e4b5a",
+		"12:pids:/docker/" + id + "\n":                                                 "docker:4f1c2d3e4b5a",
+		"0::/kubepods.slice/kubepods-besteffort.slice/cri-containerd-" + id + ".scope": "containerd:4f1c2d3e4b5a",
+		"0::/machine.slice/libpod-" + id + ".scope/container":                          "podman:4f1c2d3e4b5a",
+		"11:memory:/kubepods/burstable/pod1234/" + id:                                  "kubepods:4f1c2d3e4b5a",
+		"0::/user.slice/user-1000.slice/session-2.scope\n":                             "",
+	}
+	for in, want := range cases {
+		if got := parseCgroupContainer(in); got != want {
+			t.Fatalf("parseCgroupContainer(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package proc
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// deletedSuffix is what the kernel appends to /proc/<pid>/exe once the
+// binary was unlinked or replaced by a new file.
+const deletedSuffix = " (deleted)"
+
+// ExePath returns the executable without the " (deleted)" marker, and
+// whether the marker was there.
+func (i Info) ExePath() (exe string, deleted bool) {
+	exe, deleted = strings.CutSuffix(i.Exe, deletedSuffix)
+	return exe, deleted
+}
+
+// shells run inline code with -c, or read commands from a socket with -i.
+var shells = map[string]bool{"sh": true, "bash": true, "dash": true, "zsh": true, "ksh": true, "ash": true, "busybox": true}
+
+// inlineFlags are the options that make an interpreter run code from the
+// command line instead of a file.
+var inlineFlags = map[string][]string{
+	"python": {"-c"},
+	"perl":   {"-e", "-E"},
+	"ruby":   {"-e"},
+	"node":   {"-e", "--eval", "-p", "--print"},
+	"php":    {"-r"},
+	"shell":  {"-c", "-i"},
+}
+
+// InlineCode reports how an interpreter command line runs code that is not
+// in a file, e.g. "python3 -c" or "bash -i", or "" if it does not.
+func InlineCode(cmdline string) string {
+	args := strings.Fields(cmdline)
+	if len(args) == 0 {
+		return ""
+	}
+	prog := path.Base(args[0])
+	kind := strings.TrimRight(prog, "0123456789.")
+	if shells[kind] {
+		kind = "shell"
+	}
+	flags, ok := inlineFlags[kind]
+	if !ok {
+		return ""
+	}
+	for _, a := range args[1:] {
+		if a == "--" || !strings.HasPrefix(a, "-") {
+			break // the script file, or its arguments
+		}
+		for _, f := range flags {
+			name, _, _ := strings.Cut(a, "=")
+			// shells take combined short flags: bash -lc, sh -ic
+			combined := kind == "shell" && len(f) == 2 && !strings.HasPrefix(a, "--") && strings.Contains(a[1:], f[1:])
+			if name == f || combined {
+				return prog + " " + f
+			}
+		}
+	}
+	return ""
+}
+
+// DebugService names the remote debugger or profiler a process exposes on
+// port ("Node inspector", "JDWP", "Delve", "pprof"), or returns "". It goes
+// by the command line, and by the well-known default ports only for the
+// runtimes that use them: 9229 for node, 6060 for Go binaries. isGo is
+// asked only for port 6060 and may be nil when that is unknown.
+func DebugService(name, cmdline string, port int, isGo func() bool) string {
+	args := strings.Fields(cmdline)
+	prog := name
+	if len(args) > 0 {
+		prog = path.Base(args[0])
+	}
+	if name == "dlv" || prog == "dlv" {
+		return "Delve"
+	}
+	switch {
+	case flagPort(args, "--inspect", 9229) == port,
+		flagPort(args, "--inspect-brk", 9229) == port,
+		flagPort(args, "--inspect-port", 9229) == port:
+		return "Node inspector"
+	case jdwpPort(args) == port:
+		return "JDWP"
+	case pprofPort(args) == port:
+		return "pprof"
+	}
+	switch {
+	case port == 9229 && (name == "node" || prog == "node"):
+		return "Node inspector"
+	case port == 6060 && isGo != nil && isGo():
+		return "pprof"
+	}
+	return ""
+}
+
+// flagPort returns the port in --flag or --flag=[host:]port, def for a bare
+// --flag, or -1 if the flag is absent.
+func flagPort(args []string, flag string, def int) int {
+	for _, a := range args {
+		if a == flag {
+			return def
+		}
+		if v, ok := strings.CutPrefix(a, flag+"="); ok {
+			return addrPort(v)
+		}
+	}
+	return -1
+}
+
+// jdwpPort reads address=[host:]port from -agentlib:jdwp=... or
+// -Xrunjdwp:..., or returns -1.
+func jdwpPort(args []string) int {
+	for _, a := range args {
+		opts, ok := strings.CutPrefix(a, "-agentlib:jdwp=")
+		if !ok {
+			opts, ok = strings.CutPrefix(a, "-Xrunjdwp:")
+		}
+		if !ok {
+			continue
+		}
+		for _, kv := range strings.Split(opts, ",") {
+			if v, ok := strings.CutPrefix(kv, "address="); ok {
+				return addrPort(v)
+			}
+		}
+	}
+	return -1
+}
+
+// pprofPort reads the address of a flag mentioning pprof, such as
+// --pprof-addr=:6061 or -pprof.listen 127.0.0.1:6061, or returns -1.
+func pprofPort(args []string) int {
+	for i, a := range args {
+		if !strings.HasPrefix(a, "-") || !strings.Contains(strings.ToLower(a), "pprof") {
+			continue
+		}
+		if _, v, ok := strings.Cut(a, "="); ok {
+			return addrPort(v)
+		}
+		if i+1 < len(args) {
+			if p := addrPort(args[i+1]); p > 0 {
+				return p
+			}
+		}
+	}
+	return -1
+}
+
+// addrPort parses "port", ":port", "host:port" or "*:port".
+func addrPort(v string) int {
+	if i := strings.LastIndexByte(v, ':'); i >= 0 {
+		v = v[i+1:]
+	}
+	p, err := strconv.Atoi(v)
+	if err != nil || p <= 0 || p > 65535 {
+		return -1
+	}
+	return p
+}
@@ -0,0 +1,67 @@
+package proc
+
+import "testing"
+
+func TestExePath(t *testing.T) {
+	exe, deleted := Info{Exe: "/usr/sbin/nginx (deleted)"}.ExePath()
+	if exe != "/usr/sbin/nginx" || !deleted {
+		t.Errorf("ExePath = %q, %v", exe, deleted)
+	}
+	if exe, deleted := (Info{Exe: "/usr/bin/redis-server"}).ExePath(); exe != "/usr/bin/redis-server" || deleted {
+		t.Errorf("ExePath = %q, %v", exe, deleted)
+	}
+}
+
+func TestInlineCode(t *testing.T) {
+	cases := map[string]string{
+		"python3 -c import socket; s=socket.socket()": "python3 -c",
+		"/usr/bin/python3.12 -u -c print(1)":          "python3.12 -c",
+		"bash -i":                                     "bash -i",
+		"/bin/sh -lc nc -l 4444":                      "sh -c",
+		"node --eval=require('http')":                 "node --eval",
+		"perl -e use Socket":                          "perl -e",
+		"php -r echo 1;":                              "php -r",
+		"python3 -m http.server 8000":                 "",
+		"python3 app.py -c config.yaml":               "",
+		"bash --login ./serve.sh":                     "",
+		"nginx -c /etc/nginx/nginx.conf":              "",
+		"":                                            "",
+	}
+	for cmdline, want := range cases {
+		if got := InlineCode(cmdline); got != want {
+			t.Errorf("InlineCode(%q) = %q, want %q", cmdline, got, want)
+		}
+	}
+}
+
+func TestDebugService(t *testing.T) {
+	yes := func() bool { return true }
+	no := func() bool { return false }
+	cases := []struct {
+		name, cmdline string
+		port          int
+		isGo          func() bool
+		want          string
+	}{
+		{"node", "node --inspect=0.0.0.0:9230 server.js", 9230, nil, "Node inspector"},
+		{"node", "node --inspect server.js", 9229, nil, "Node inspector"},
+		{"node", "node --inspect=0.0.0.0:9230 server.js", 3000, nil, ""},
+		{"node", "node server.js", 9229, nil, "Node inspector"},
+		{"python3", "python3 -m http.server 9229", 9229, nil, ""},
+		{"java", "java -agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:5005 -jar app.jar", 5005, nil, "JDWP"},
+		{"java", "java -Xrunjdwp:transport=dt_socket,address=8000,server=y -jar app.jar", 8000, nil, "JDWP"},
+		{"java", "java -jar app.jar", 8080, nil, ""},
+		{"dlv", "dlv debug --headless --listen=:2345", 2345, nil, "Delve"},
+		{"api", "/srv/api --pprof-addr=:6061", 6061, nil, "pprof"},
+		{"api", "/srv/api -pprof.listen 127.0.0.1:6062", 6062, nil, "pprof"},
+		{"api", "/srv/api", 6060, yes, "pprof"},
+		{"api", "/srv/api", 6060, nil, ""},
+		{"api", "/srv/api", 6060, no, ""},
+		{"api", "/srv/api", 8080, yes, ""},
+	}
+	for _, c := range cases {
+		if got := DebugService(c.name, c.cmdline, c.port, c.isGo); got != c.want {
+			t.Errorf("DebugService(%q, %d) = %q, want %q", c.cmdline, c.port, got, c.want)
+		}
+	}
+}
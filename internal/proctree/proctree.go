@@ -1,11 +1,11 @@
 package proctree
 
 import (
-	"bytes"
-	"os"
 	"os/exec"
-	"runtime"
 	"strings"
+
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/proc"
 )
 
 type Proc struct {
@@ -48,30 +48,19 @@ func Build(pid int32, maxDepth int) ([]Proc, StartedBy) {
 }
 
 func procInfo(pid int32) Proc {
-	ppidStr := psField(pid, "ppid=")
-	userStr := psField(pid, "user=")
-	commStr := psField(pid, "comm=")
-	cmdStr := psField(pid, "command=")
-
+	info, _ := proc.Lookup(pid)
 	return Proc{
 		PID:     pid,
-		PPID:    atoi32(ppidStr),
-		User:    strings.TrimSpace(userStr),
-		Name:    strings.TrimSpace(commStr),
-		Cmdline: strings.TrimSpace(cmdStr),
+		PPID:    info.PPID,
+		User:    info.User,
+		Name:    info.Name,
+		Cmdline: strings.TrimSpace(info.Cmdline),
 	}
 }
 
-func psField(pid int32, format string) string {
-	cmd := exec.Command("ps", "-p", itoa32(pid), "-o", format)
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	_ = cmd.Run()
-	return strings.TrimSpace(buf.String())
-}
-
 func whoStarted(pid int32) StartedBy {
-	switch runtime.GOOS {
+	// the OS the process ran on, which differs when replaying a bundle
+	switch platform.HostSummary().OS {
 	case "linux":
 		if unit := systemdUnitFromCgroup(pid); unit != "" {
 			return StartedBy{Kind: "systemd", Details: unit}
@@ -96,11 +85,11 @@ func whoStarted(pid int32) StartedBy {
 func parentLooksLikeLaunchd(pid int32) bool {
 	cur := pid
 	for i := 0; i < 15 && cur > 0; i++ {
-		name := strings.ToLower(psField(cur, "comm="))
-		if strings.Contains(name, "launchd") {
+		info, _ := proc.Lookup(cur)
+		if strings.Contains(strings.ToLower(info.Name), "launchd") {
 			return true
 		}
-		ppid := atoi32(psField(cur, "ppid="))
+		ppid := info.PPID
 		if ppid <= 0 || ppid == cur {
 			break
 		}
@@ -110,6 +99,9 @@ func parentLooksLikeLaunchd(pid int32) bool {
 }
 
 func systemctlStatusHint(pid int32) string {
+	if proc.Replaying() {
+		return ""
+	}
 	if _, err := exec.LookPath("systemctl"); err != nil {
 		return ""
 	}
@@ -128,11 +120,8 @@ func systemctlStatusHint(pid int32) string {
 }
 
 func systemdUnitFromCgroup(pid int32) string {
-	b, err := os.ReadFile("/proc/" + itoa32(pid) + "/cgroup")
-	if err != nil {
-		return ""
-	}
-	txt := string(b)
+	info, _ := proc.Lookup(pid)
+	txt := info.Cgroup
 	for _, line := range strings.Split(txt, "\n") {
 		if strings.Contains(line, "system.slice/") && strings.Contains(line, ".service") {
 			i := strings.Index(line, "system.slice/")
@@ -151,11 +140,8 @@ func systemdUnitFromCgroup(pid int32) string {
 }
 
 func containerIDFromCgroup(pid int32) string {
-	b, err := os.ReadFile("/proc/" + itoa32(pid) + "/cgroup")
-	if err != nil {
-		return ""
-	}
-	txt := string(b)
+	info, _ := proc.Lookup(pid)
+	txt := info.Cgroup
 	tokens := splitNonHex(txt)
 	best := ""
 	for _, t := range tokens {
@@ -211,23 +197,3 @@ func itoa32(n int32) string {
 	}
 	return string(b[i:])
 }
-
-func atoi32(s string) int32 {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return 0
-	}
-	sign := int32(1)
-	if strings.HasPrefix(s, "-") {
-		sign = -1
-		s = strings.TrimPrefix(s, "-")
-	}
-	var n int32
-	for _, r := range s {
-		if r < '0' || r > '9' {
-			break
-		}
-		n = n*10 + int32(r-'0')
-	}
-	return sign * n
-}
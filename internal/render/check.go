@@ -0,0 +1,48 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// CheckTable renders a manifest check (portik check), one line per
+// declared port or difference, followed by a summary.
+func CheckTable(checks []model.PortCheck) string {
+	if len(checks) == 0 {
+		return "Nothing declared and nothing listening.\n"
+	}
+	var b strings.Builder
+	b.WriteString("SEV    SERVICE          PORT/PROTO  STATUS       DETAIL\n")
+	b.WriteString("─────  ───────────────  ──────────  ───────────  ─────────────────────────────────────────\n")
+	counts := map[string]int{}
+	var order []string
+	for _, c := range checks {
+		if counts[c.Status] == 0 {
+			order = append(order, c.Status)
+		}
+		counts[c.Status]++
+		status := strings.ToUpper(strings.ReplaceAll(c.Status, "_", " "))
+		if c.Status == "ok" {
+			status = "ok"
+		}
+		detail := c.Got
+		if c.Want != "" {
+			detail += ", want " + c.Want
+		}
+		fmt.Fprintf(&b, "%-5s  %-15s  %-10s  %-11s  %s\n",
+			strings.ToUpper(nonEmpty(c.Severity, "-")),
+			trunc(nonEmpty(c.Service, "-"), 15),
+			fmt.Sprintf("%d/%s", c.Port, c.Proto),
+			status,
+			detail,
+		)
+	}
+	var parts []string
+	for _, s := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[s], strings.ReplaceAll(s, "_", " ")))
+	}
+	fmt.Fprintf(&b, "\n%s\n", strings.Join(parts, ", "))
+	return b.String()
+}
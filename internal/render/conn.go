@@ -11,6 +11,12 @@ type ConnAggRow struct {
 	Total    int
 	ByState  map[string]int
 	Samples  []string
+
+	// tcp_info aggregates; Measured is false when the backend had none
+	Measured bool
+	AvgRTTMs float64
+	Retrans  int
+	Unacked  int
 }
 
 // ConnTable renders top remote IPs and per-state counts.
@@ -28,16 +34,25 @@ func ConnTable(port int, proto string, rows any) string {
 func connTableFrom(rows []ConnAggRow, port int, proto string) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "Connections for %d/%s (top clients)\n", port, proto)
-	b.WriteString("REMOTE IP              TOTAL   STATES                      SAMPLES\n")
-	b.WriteString("────────────────────   ─────   ─────────────────────────   ─────────────────────────\n")
+	b.WriteString("REMOTE IP              TOTAL   STATES                      RTT ms    RETRANS   UNACKED   SAMPLES\n")
+	b.WriteString("────────────────────   ─────   ─────────────────────────   ───────   ───────   ───────   ─────────────────────────\n")
 
 	for _, r := range rows {
 		stateStr := formatStates(r.ByState, 3)
 		samp := strings.Join(r.Samples, ", ")
-		fmt.Fprintf(&b, "%-20s   %-5d   %-25s   %s\n",
+		rtt, retrans, unacked := "-", "-", "-"
+		if r.Measured {
+			rtt = fmt.Sprintf("%.2f", r.AvgRTTMs)
+			retrans = fmt.Sprintf("%d", r.Retrans)
+			unacked = fmt.Sprintf("%d", r.Unacked)
+		}
+		fmt.Fprintf(&b, "%-20s   %-5d   %-25s   %-7s   %-7s   %-7s   %s\n",
 			trunc(r.RemoteIP, 20),
 			r.Total,
 			trunc(stateStr, 25),
+			rtt,
+			retrans,
+			unacked,
 			trunc(samp, 25),
 		)
 	}
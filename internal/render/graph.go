@@ -14,7 +14,14 @@ type GraphRenderOptions struct {
 
 func GraphText(g graph.Graph, deps []graph.Dependency, warns []string, opt GraphRenderOptions) string {
 	var b strings.Builder
-	b.WriteString("Local dependency graph (tcp)\n")
+	title := "tcp"
+	for _, n := range g.Nodes {
+		if n.Type == graph.NodeSocket {
+			title = "tcp, unix"
+			break
+		}
+	}
+	fmt.Fprintf(&b, "Local dependency graph (%s)\n", title)
 
 	if len(warns) > 0 {
 		b.WriteString("\nWarnings:\n")
@@ -33,7 +40,14 @@ func GraphText(g graph.Graph, deps []graph.Dependency, warns []string, opt Graph
 			if name == "" {
 				name = "unknown"
 			}
-			fmt.Fprintf(&b, "  %-10s (pid %d) LISTEN %s:%d\n", trunc(name, 10), l.pid, fmtIP(l.ip), l.port)
+			addr := fmt.Sprintf("%s:%d", fmtIP(l.ip), l.port)
+			if l.path != "" {
+				addr = l.path
+			}
+			if l.netns != "" {
+				addr += "  netns=" + l.netns
+			}
+			fmt.Fprintf(&b, "  %-10s (pid %d) LISTEN %s\n", trunc(name, 10), l.pid, addr)
 		}
 	}
 
@@ -55,9 +69,9 @@ func GraphText(g graph.Graph, deps []graph.Dependency, warns []string, opt Graph
 			if d.TimeWait > 0 {
 				label = fmt.Sprintf("%s TW=%d", label, d.TimeWait)
 			}
-			fmt.Fprintf(&b, "  %s(pid %d) -> %s:%d   %s\n",
+			fmt.Fprintf(&b, "  %s(pid %d) -> %s:%s   %s\n",
 				trunc(clientName, 12), d.Client.PID,
-				trunc(serverName, 12), d.Port.Port,
+				trunc(serverName, 12), depEndpoint(d.Port),
 				label,
 			)
 		}
@@ -81,7 +95,7 @@ func GraphDOT(deps []graph.Dependency, top int) string {
 		}
 		client := fmt.Sprintf("%s (pid %d)", clientName, d.Client.PID)
 		server := fmt.Sprintf("%s (pid %d)", serverName, d.Server.PID)
-		label := fmt.Sprintf("%d (%d)", d.Port.Port, d.Established)
+		label := fmt.Sprintf("%s (%d)", depEndpoint(d.Port), d.Established)
 		if d.TimeWait > 0 {
 			label = fmt.Sprintf("%s TW=%d", label, d.TimeWait)
 		}
@@ -91,11 +105,25 @@ func GraphDOT(deps []graph.Dependency, top int) string {
 	return b.String()
 }
 
+// depEndpoint labels a dependency target: a port number or a socket path.
+func depEndpoint(n graph.Node) string {
+	ep := fmt.Sprintf("%d", n.Port)
+	if n.Type == graph.NodeSocket {
+		ep = n.Path
+	}
+	if n.Netns != "" {
+		ep += "@" + n.Netns
+	}
+	return ep
+}
+
 type listenerRow struct {
 	procName string
 	pid      int32
 	ip       string
 	port     int
+	path     string
+	netns    string
 }
 
 func graphListeners(g graph.Graph) []listenerRow {
@@ -110,7 +138,7 @@ func graphListeners(g graph.Graph) []listenerRow {
 		}
 		proc := nodes[e.From]
 		port := nodes[e.To]
-		if proc.Type != graph.NodeProcess || port.Type != graph.NodePort {
+		if proc.Type != graph.NodeProcess || (port.Type != graph.NodePort && port.Type != graph.NodeSocket) {
 			continue
 		}
 		rows = append(rows, listenerRow{
@@ -118,10 +146,18 @@ func graphListeners(g graph.Graph) []listenerRow {
 			pid:      proc.PID,
 			ip:       port.LocalIP,
 			port:     port.Port,
+			path:     port.Path,
+			netns:    port.Netns,
 		})
 	}
 	sort.Slice(rows, func(i, j int) bool {
 		if rows[i].port == rows[j].port {
+			if rows[i].path != rows[j].path {
+				return rows[i].path < rows[j].path
+			}
+			if rows[i].netns != rows[j].netns {
+				return rows[i].netns < rows[j].netns
+			}
 			if rows[i].procName == rows[j].procName {
 				return rows[i].pid < rows[j].pid
 			}
@@ -15,3 +15,16 @@ func trunc(s string, n int) string {
 	}
 	return s[:n-1] + "…"
 }
+
+// truncLeft keeps the end of a string (useful for paths), adding a leading
+// ellipsis if truncated.
+func truncLeft(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	if n <= 1 {
+		return s[len(s)-n:]
+	}
+	return "…" + s[len(s)-n+1:]
+}
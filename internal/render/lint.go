@@ -41,24 +41,29 @@ func LintTable(findings []model.LintFinding) string {
 	})
 
 	var b strings.Builder
-	b.WriteString("SEV   PORT/PROTO  BIND              PID     PROCESS            SUMMARY\n")
-	b.WriteString("────  ─────────  ───────────────  ──────  ───────────────  ─────────────────────────────────────────\n")
+	b.WriteString("SEV    PORT/PROTO  BIND              PID     PROCESS            SUMMARY\n")
+	b.WriteString("─────  ─────────  ───────────────  ──────  ───────────────  ─────────────────────────────────────────\n")
 
 	for _, f := range findings {
 		bind := f.LocalIP
 		if bind == "" {
 			bind = "*"
 		}
-		fmt.Fprintf(&b, "%-4s  %-9s  %-15s  %-6s  %-15s  %s\n",
+		where := fmt.Sprintf("%d/%s", f.Port, f.Proto)
+		if f.Path != "" {
+			bind = truncLeft(f.Path, 15)
+			where = f.Proto
+		}
+		fmt.Fprintf(&b, "%-5s  %-9s  %-15s  %-6s  %-15s  %s\n",
 			strings.ToUpper(f.Severity),
-			fmt.Sprintf("%d/%s", f.Port, f.Proto),
+			where,
 			trunc(bind, 15),
 			pidStr(f.PID),
 			trunc(nonEmpty(f.ProcName, "-"), 15),
 			trunc(f.Summary, 56),
 		)
 		if f.Action != "" {
-			fmt.Fprintf(&b, "      ↳ %s\n", f.Action)
+			fmt.Fprintf(&b, "       ↳ %s\n", f.Action)
 		}
 	}
 	return b.String()
@@ -0,0 +1,376 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// lintTarget names what a finding is about: "tcp/5432" or a socket path.
+func lintTarget(f model.LintFinding) string {
+	if f.Path != "" {
+		return f.Path
+	}
+	return fmt.Sprintf("%s/%d", f.Proto, f.Port)
+}
+
+func lintMessage(f model.LintFinding) string {
+	if f.Details == "" {
+		return f.Summary
+	}
+	return f.Summary + ": " + f.Details
+}
+
+func lintRank(sev string) int {
+	switch sev {
+	case "error":
+		return 2
+	case "warn":
+		return 1
+	}
+	return 0
+}
+
+// sarifLevel maps info|warn|error to SARIF's note|warning|error.
+func sarifLevel(sev string) string {
+	switch sev {
+	case "error":
+		return "error"
+	case "warn":
+		return "warning"
+	}
+	return "note"
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver sarifDriver `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string    `json:"id"`
+	ShortDescription sarifText `json:"shortDescription"`
+	Help             sarifText `json:"help"`
+	Default          struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           *int              `json:"ruleIndex,omitempty"`
+	Level               string            `json:"level"`
+	Message             sarifText         `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysical  `json:"physicalLocation"`
+	LogicalLocations []sarifLogical `json:"logicalLocations"`
+}
+
+type sarifPhysical struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+}
+
+type sarifLogical struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// LintSARIF renders findings as a SARIF 2.1.0 log for code-scanning tools.
+// Every rule is listed, with or without findings. A finding's Action ends
+// its message and is its remediation property. Code-scanning tools need a
+// file location, so each result points at portik://<host>/<proto>/<port>
+// (or the socket path) on host.
+func LintSARIF(findings []model.LintFinding, rules []model.LintRule, host, version string) string {
+	index := map[string]int{}
+	driverRules := make([]sarifRule, len(rules))
+	for i, r := range rules {
+		index[r.Code] = i
+		driverRules[i] = sarifRule{ID: r.Code, ShortDescription: sarifText{r.Summary}, Help: sarifText{r.Help}}
+		driverRules[i].Default.Level = sarifLevel(r.Severity)
+	}
+
+	results := []sarifResult{}
+	for _, f := range findings {
+		fqn := lintTarget(f)
+		if f.Path == "" && f.LocalIP != "" {
+			fqn = f.Proto + "/" + joinHostPort(f.LocalIP, f.Port)
+		}
+		msg := lintMessage(f)
+		if f.Action != "" {
+			msg += "\nRemediation: " + f.Action
+		}
+		loc := sarifLocation{LogicalLocations: []sarifLogical{{Name: lintTarget(f), FullyQualifiedName: fqn, Kind: "resource"}}}
+		loc.PhysicalLocation.ArtifactLocation.URI = lintURI(host, f)
+		r := sarifResult{
+			RuleID:    f.Code,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifText{msg},
+			Locations: []sarifLocation{loc},
+			// stable across runs, like the baseline
+			PartialFingerprints: map[string]string{"portik/v1": f.Fingerprint()},
+		}
+		if i, ok := index[f.Code]; ok {
+			r.RuleIndex = &i
+		}
+		props := map[string]any{"severity": f.Severity}
+		if f.Action != "" {
+			props["remediation"] = f.Action
+		}
+		if f.PID > 0 {
+			props["pid"] = f.PID
+		}
+		if f.ProcName != "" {
+			props["process"] = f.ProcName
+		}
+		if f.User != "" {
+			props["user"] = f.User
+		}
+		r.Properties = props
+		results = append(results, r)
+	}
+
+	var log sarifLog
+	log.Schema = "https://json.schemastore.org/sarif-2.1.0.json"
+	log.Version = "2.1.0"
+	log.Runs = make([]sarifRun, 1)
+	log.Runs[0].Tool.Driver = sarifDriver{
+		Name:           "portik",
+		Version:        version,
+		InformationURI: "https://github.com/pratik-anurag/portik",
+		Rules:          driverRules,
+	}
+	log.Runs[0].Results = results
+	b, _ := json.MarshalIndent(log, "", "  ")
+	return string(b) + "\n"
+}
+
+// lintURI is the synthetic artifact a finding is reported against:
+// portik://web-1/tcp/5432 or portik://web-1/unix/run/app.sock.
+func lintURI(host string, f model.LintFinding) string {
+	u := url.URL{Scheme: "portik", Host: nonEmpty(host, "localhost"), Path: "/" + f.Proto + "/" + strconv.Itoa(f.Port)}
+	if f.Path != "" {
+		u.Path = "/" + f.Proto + f.Path
+	}
+	return u.String()
+}
+
+func joinHostPort(ip string, port int) string {
+	if strings.Contains(ip, ":") {
+		return fmt.Sprintf("[%s]:%d", ip, port)
+	}
+	return fmt.Sprintf("%s:%d", ip, port)
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// LintJUnit renders findings as a JUnit XML report: one suite per rule,
+// one testcase per finding. Findings at or above failOn (info|warn|error,
+// or none) are failures; a rule without findings is a single passing case.
+func LintJUnit(findings []model.LintFinding, rules []model.LintRule, failOn string) string {
+	failRank := 3
+	if failOn != "none" {
+		failRank = lintRank(failOn)
+	}
+	byCode := map[string][]model.LintFinding{}
+	for _, f := range findings {
+		byCode[f.Code] = append(byCode[f.Code], f)
+	}
+	// findings from codes missing in rules still get a suite
+	for _, f := range findings {
+		if !hasRule(rules, f.Code) {
+			rules = append(rules, model.LintRule{Code: f.Code, Severity: f.Severity, Summary: f.Summary})
+		}
+	}
+
+	out := junitSuites{Name: "portik lint"}
+	for _, r := range rules {
+		s := junitSuite{Name: r.Code}
+		fs := byCode[r.Code]
+		if len(fs) == 0 {
+			s.Cases = []junitCase{{Name: r.Summary, Classname: "portik.lint." + r.Code}}
+		}
+		for _, f := range fs {
+			c := junitCase{
+				Name:      fmt.Sprintf("%s %s", lintTarget(f), nonEmpty(f.ProcName, "-")),
+				Classname: "portik.lint." + f.Code,
+			}
+			body := strings.ToUpper(f.Severity) + " " + lintMessage(f)
+			if f.Action != "" {
+				body += "\nRemediation: " + f.Action
+			}
+			if lintRank(f.Severity) >= failRank {
+				c.Failure = &junitFailure{Message: f.Summary, Type: f.Severity, Body: body}
+				s.Failures++
+			} else {
+				c.SystemOut = body
+			}
+			s.Cases = append(s.Cases, c)
+		}
+		s.Tests = len(s.Cases)
+		out.Tests += s.Tests
+		out.Failures += s.Failures
+		out.Suites = append(out.Suites, s)
+	}
+	b, _ := xml.MarshalIndent(out, "", "  ")
+	return xml.Header + string(b) + "\n"
+}
+
+func hasRule(rules []model.LintRule, code string) bool {
+	for _, r := range rules {
+		if r.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// LintCheckstyle renders findings as Checkstyle XML, with one <file> per
+// port or socket path.
+func LintCheckstyle(findings []model.LintFinding) string {
+	out := checkstyleReport{Version: "4.3"}
+	at := map[string]int{}
+	for _, f := range findings {
+		name := lintTarget(f)
+		i, ok := at[name]
+		if !ok {
+			i = len(out.Files)
+			at[name] = i
+			out.Files = append(out.Files, checkstyleFile{Name: name})
+		}
+		sev := map[string]string{"error": "error", "warn": "warning"}[f.Severity]
+		msg := lintMessage(f)
+		if f.Action != "" {
+			msg += " (" + f.Action + ")"
+		}
+		out.Files[i].Errors = append(out.Files[i].Errors, checkstyleError{
+			Severity: nonEmpty(sev, "info"),
+			Message:  msg,
+			Source:   "portik." + f.Code,
+		})
+	}
+	b, _ := xml.MarshalIndent(out, "", "  ")
+	return xml.Header + string(b) + "\n"
+}
+
+// LintMarkdown renders findings as a Markdown summary, e.g. for a CI job
+// summary or a pull request comment.
+func LintMarkdown(findings []model.LintFinding) string {
+	var b strings.Builder
+	b.WriteString("## portik lint\n\n")
+	if len(findings) == 0 {
+		b.WriteString("No lint findings.\n")
+		return b.String()
+	}
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for _, sev := range []string{"error", "warn", "info"} {
+		if counts[sev] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[sev], sev))
+		}
+	}
+	noun := "findings"
+	if len(findings) == 1 {
+		noun = "finding"
+	}
+	fmt.Fprintf(&b, "%d %s: %s\n\n", len(findings), noun, strings.Join(parts, ", "))
+	b.WriteString("| Severity | Code | Port | Bind | Process | Summary | Remediation |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, f := range findings {
+		bind := nonEmpty(f.LocalIP, "*")
+		if f.Path != "" {
+			bind = "-"
+		}
+		proc := nonEmpty(f.ProcName, "-")
+		if f.PID > 0 {
+			proc = fmt.Sprintf("%s (%d)", proc, f.PID)
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s | %s | %s | %s |\n",
+			strings.ToUpper(f.Severity), f.Code, mdCell(lintTarget(f)), mdCell(bind),
+			mdCell(proc), mdCell(f.Summary), mdCell(nonEmpty(f.Action, "-")))
+	}
+	return b.String()
+}
+
+// mdCell keeps text on one line and escapes the table separator.
+func mdCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
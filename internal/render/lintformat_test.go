@@ -0,0 +1,140 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+var (
+	testRules = []model.LintRule{
+		{Code: "NO_PID", Severity: "info", Summary: "PID not visible"},
+		{Code: "PUBLIC_SENSITIVE", Severity: "warn", Summary: "Sensitive service port is publicly bound", Help: "Bind to loopback."},
+	}
+	testFindings = []model.LintFinding{
+		{Severity: "warn", Code: "PUBLIC_SENSITIVE", Summary: "Sensitive service port is publicly bound", Action: "Bind to 127.0.0.1 | ::1",
+			Proto: "tcp", Port: 5432, LocalIP: "::", PID: 42, ProcName: "postgres"},
+		{Severity: "error", Code: "CUSTOM", Summary: "custom rule", Proto: "unix", Path: "/run/app.sock"},
+	}
+)
+
+func TestLintSARIF(t *testing.T) {
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID                   string
+						DefaultConfiguration struct{ Level string }
+					}
+				}
+			}
+			Results []struct {
+				RuleID              string
+				RuleIndex           *int
+				Level               string
+				PartialFingerprints map[string]string
+				Properties          map[string]any
+				Message             struct{ Text string }
+				Locations           []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+					}
+					LogicalLocations []struct{ Name, FullyQualifiedName string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(LintSARIF(testFindings, testRules, "web-1", "1.2.3")), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	if log.Version != "2.1.0" || len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[1].DefaultConfiguration.Level != "warning" {
+		t.Fatalf("sarif: %+v", log)
+	}
+	r := run.Results[0]
+	if r.RuleID != "PUBLIC_SENSITIVE" || *r.RuleIndex != 1 || r.Level != "warning" || r.Properties["remediation"] != "Bind to 127.0.0.1 | ::1" {
+		t.Errorf("result: %+v", r)
+	}
+	if l := r.Locations[0].LogicalLocations[0]; l.Name != "tcp/5432" || l.FullyQualifiedName != "tcp/[::]:5432" {
+		t.Errorf("location: %+v", l)
+	}
+	if uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "portik://web-1/tcp/5432" {
+		t.Errorf("uri = %q", uri)
+	}
+	if !strings.HasSuffix(r.Message.Text, "Remediation: Bind to 127.0.0.1 | ::1") {
+		t.Errorf("message = %q", r.Message.Text)
+	}
+	if uri := run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "portik://web-1/unix/run/app.sock" {
+		t.Errorf("socket uri = %q", uri)
+	}
+	if r.PartialFingerprints["portik/v1"] != "PUBLIC_SENSITIVE tcp/5432 postgres" {
+		t.Errorf("fingerprint: %v", r.PartialFingerprints)
+	}
+	if r := run.Results[1]; r.RuleIndex != nil || r.Level != "error" || r.Locations[0].LogicalLocations[0].Name != "/run/app.sock" {
+		t.Errorf("unknown rule: %+v", r)
+	}
+}
+
+func TestLintJUnit(t *testing.T) {
+	type junit struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Failure *struct {
+					Body string `xml:",chardata"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	var report junit
+	if err := xml.Unmarshal([]byte(LintJUnit(testFindings, testRules, "error")), &report); err != nil {
+		t.Fatal(err)
+	}
+	// NO_PID passes, PUBLIC_SENSITIVE is below --fail-on, CUSTOM fails
+	if report.Tests != 3 || report.Failures != 1 || len(report.Suites) != 3 || report.Suites[2].Name != "CUSTOM" {
+		t.Errorf("junit: %+v", report)
+	}
+	report = junit{}
+	if err := xml.Unmarshal([]byte(LintJUnit(testFindings, testRules, "warn")), &report); err != nil {
+		t.Fatal(err)
+	}
+	if f := report.Suites[1].Cases[0].Failure; f == nil || !strings.Contains(f.Body, "Remediation: Bind to 127.0.0.1") {
+		t.Errorf("--fail-on warn: %+v", f)
+	}
+}
+
+func TestLintCheckstyle(t *testing.T) {
+	var report struct {
+		Files []struct {
+			Name   string `xml:"name,attr"`
+			Errors []struct {
+				Severity string `xml:"severity,attr"`
+				Source   string `xml:"source,attr"`
+			} `xml:"error"`
+		} `xml:"file"`
+	}
+	if err := xml.Unmarshal([]byte(LintCheckstyle(testFindings)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 2 || report.Files[0].Name != "tcp/5432" || report.Files[0].Errors[0].Severity != "warning" ||
+		report.Files[1].Errors[0].Source != "portik.CUSTOM" {
+		t.Errorf("checkstyle: %+v", report)
+	}
+}
+
+func TestLintMarkdown(t *testing.T) {
+	md := LintMarkdown(testFindings)
+	if !strings.Contains(md, "2 findings: 1 error, 1 warn") || !strings.Contains(md, `Bind to 127.0.0.1 \| ::1`) {
+		t.Errorf("markdown:\n%s", md)
+	}
+	if !strings.Contains(LintMarkdown(nil), "No lint findings.") {
+		t.Error("empty report")
+	}
+}
@@ -26,7 +26,11 @@ type OwnerEvent struct {
 func Who(rep model.Report, opt Options) string {
 	opt = normalizeOptions(opt)
 	var b strings.Builder
-	fmt.Fprintf(&b, "%s %d/%s\n", label("PORT", opt), rep.Port, rep.Proto)
+	if rep.Path != "" {
+		fmt.Fprintf(&b, "%s %s (%s)\n", label("SOCKET", opt), rep.Path, rep.Proto)
+	} else {
+		fmt.Fprintf(&b, "%s %d/%s\n", label("PORT", opt), rep.Port, rep.Proto)
+	}
 
 	if len(rep.Listeners) == 0 {
 		b.WriteString("  (no listeners)\n")
@@ -34,27 +38,33 @@ func Who(rep model.Report, opt Options) string {
 		if opt.Summary {
 			l, ok := rep.PrimaryListener()
 			if ok {
-				fmt.Fprintf(&b, "  %-7s %-24s pid=%d  user=%s  %-12s\n",
+				fmt.Fprintf(&b, "  %-7s %-24s pid=%d  user=%s  %-12s%s\n",
 					stateLabel(l.State, opt),
-					fmt.Sprintf("%s:%d", fmtIP(l.LocalIP), l.LocalPort),
+					listenerAddr(l),
 					l.PID,
 					dash(l.User),
 					dash(l.ProcName),
+					workersSuffix(l)+netnsSuffix(l)+tunnelSuffix(l),
 				)
 			}
 		} else {
 			b.WriteString("\n")
-			b.WriteString("  STATE   ADDRESS                  PID     USER        PROCESS       CMD\n")
-			b.WriteString("  -----   -----------------------  ------  ----------  ------------  ---\n")
+			b.WriteString("  STATE   ADDRESS                  QUEUE       PID     USER        PROCESS       CMD\n")
+			b.WriteString("  -----   -----------------------  ----------  ------  ----------  ------------  ---\n")
 			for _, l := range rep.Listeners {
-				fmt.Fprintf(&b, "  %-7s %-24s %-6d  %-10s  %-12s  %s\n",
+				fmt.Fprintf(&b, "  %-7s %-24s %-10s  %-6d  %-10s  %-12s  %s\n",
 					stateLabel(l.State, opt),
-					fmt.Sprintf("%s:%d", fmtIP(l.LocalIP), l.LocalPort),
+					listenerAddr(l),
+					QueueLabel(l),
 					l.PID,
 					dash(l.User),
 					dash(l.ProcName),
 					dash(l.Cmdline),
 				)
+				writeNetns(&b, l)
+				writeTunnel(&b, l)
+				writeProbe(&b, l)
+				writeHolders(&b, l)
 			}
 		}
 	}
@@ -94,10 +104,110 @@ func Who(rep model.Report, opt Options) string {
 	return b.String()
 }
 
+// writeHolders prints the processes sharing a listener's socket as a small
+// tree under its row, e.g. a pre-fork master and its workers.
+func writeHolders(b *strings.Builder, l model.Listener) {
+	if len(l.Holders) < 2 {
+		return
+	}
+	for i, h := range l.Holders {
+		prefix := "├─"
+		if i == len(l.Holders)-1 {
+			prefix = "└─"
+		}
+		role := h.Role
+		if role == "" {
+			role = "shared"
+		}
+		fmt.Fprintf(b, "          %s %-6s pid=%d  %s", prefix, role, h.PID, dash(h.ProcName))
+		if h.Role == "worker" && h.PPID > 0 {
+			fmt.Fprintf(b, "  (child of %d)", h.PPID)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// writeNetns notes where a listener lives when it is not a plain host
+// socket: its network namespace and/or container.
+func writeNetns(b *strings.Builder, l model.Listener) {
+	if s := strings.TrimSpace(netnsSuffix(l)); s != "" {
+		fmt.Fprintf(b, "          %s\n", s)
+	}
+}
+
+// writeTunnel says where a forwarder (ssh -L, kubectl port-forward, ...)
+// sends the port's traffic.
+func writeTunnel(b *strings.Builder, l model.Listener) {
+	if l.Tunnel == nil {
+		return
+	}
+	fmt.Fprintf(b, "          %s", l.Tunnel)
+	if l.Tunnel.Peer != "" {
+		fmt.Fprintf(b, "  upstream=%s", l.Tunnel.Peer)
+	}
+	b.WriteString("\n")
+}
+
+// writeProbe shows what the listener answered to --probe.
+func writeProbe(b *strings.Builder, l model.Listener) {
+	if l.Probe == nil {
+		return
+	}
+	fmt.Fprintf(b, "          serves %s", l.Probe)
+	if c := l.Probe.Cert; c != nil {
+		fmt.Fprintf(b, "  cert=%q expires=%s", c.Subject, c.NotAfter.Format("2006-01-02"))
+	}
+	b.WriteString("\n")
+}
+
+func tunnelSuffix(l model.Listener) string {
+	if l.Tunnel == nil {
+		return ""
+	}
+	return "  " + l.Tunnel.String()
+}
+
+func netnsSuffix(l model.Listener) string {
+	var parts []string
+	if l.Netns != "" {
+		parts = append(parts, "netns="+l.Netns)
+	}
+	if l.Container != "" {
+		parts = append(parts, "container="+l.Container)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "  " + strings.Join(parts, " ")
+}
+
+// QueueLabel formats a listener's accept queue as "pending/backlog".
+func QueueLabel(l model.Listener) string {
+	switch {
+	case l.SendQ > 0:
+		return fmt.Sprintf("%d/%d", l.RecvQ, l.SendQ)
+	case l.RecvQ > 0:
+		return fmt.Sprintf("%d/?", l.RecvQ)
+	default:
+		return "-"
+	}
+}
+
+func workersSuffix(l model.Listener) string {
+	if n := len(l.Workers()); n > 0 {
+		return fmt.Sprintf("  +%d workers", n)
+	}
+	if len(l.Holders) > 1 {
+		return fmt.Sprintf("  +%d sharing", len(l.Holders)-1)
+	}
+	return ""
+}
+
 func Explain(rep model.Report, opt Options) string {
 	opt = normalizeOptions(opt)
 	var b strings.Builder
 	b.WriteString(Who(rep, opt))
+	writeBind(&b, rep.Bind, opt)
 
 	if opt.NoHints {
 		return b.String()
@@ -149,6 +259,35 @@ func Explain(rep model.Report, opt Options) string {
 	return b.String()
 }
 
+// writeBind prints the outcome of explain --bind.
+func writeBind(b *strings.Builder, bc *model.BindCheck, opt Options) {
+	if bc == nil {
+		return
+	}
+	result := "ok"
+	if !bc.OK {
+		result = bc.Errno
+		if result == "" {
+			result = bc.Error
+		}
+	}
+	var opts []string
+	if bc.ReuseAddr {
+		opts = append(opts, "reuseaddr")
+	}
+	if bc.ReusePort {
+		opts = append(opts, "reuseport")
+	}
+	if bc.V6Only {
+		opts = append(opts, "v6only")
+	}
+	suffix := ""
+	if len(opts) > 0 {
+		suffix = "  (" + strings.Join(opts, ",") + ")"
+	}
+	fmt.Fprintf(b, "\n%s %s:%d/%s -> %s%s\n", label("BIND", opt), fmtIP(bc.Addr), bc.Port, bc.Proto, result, suffix)
+}
+
 func Blame(rep model.Report, chain []proctree.Proc, started proctree.StartedBy) string {
 	var b strings.Builder
 	b.WriteString(Who(rep, Options{}))
@@ -184,6 +323,13 @@ func ActionResult(r sys.ActionResult) string {
 	return b.String()
 }
 
+func listenerAddr(l model.Listener) string {
+	if l.Path != "" {
+		return l.Path
+	}
+	return fmt.Sprintf("%s:%d", fmtIP(l.LocalIP), l.LocalPort)
+}
+
 func fmtIP(ip string) string {
 	if ip == "" {
 		return "*"
@@ -207,7 +353,7 @@ type diagSection struct {
 }
 
 func groupDiagnostics(in []model.Diagnostic) []diagSection {
-	ordered := []string{"Port & process", "Network & reachability", "Environment", "Other"}
+	ordered := []string{"Bind test", "Port & process", "Network & reachability", "Environment", "Custom rules", "Other"}
 	buckets := map[string][]model.Diagnostic{}
 	for _, d := range in {
 		buckets[diagCategory(d.Kind)] = append(buckets[diagCategory(d.Kind)], d)
@@ -224,12 +370,17 @@ func groupDiagnostics(in []model.Diagnostic) []diagSection {
 
 func diagCategory(kind string) string {
 	switch kind {
-	case "permission", "in-use", "time-wait", "zombie", "pid-missing", "multi-listener":
+	case "bind", "bind-conflict":
+		return "Bind test"
+	case "permission", "in-use", "tunnel", "protocol", "time-wait", "close-wait", "ephemeral-ports", "zombie", "pid-missing", "multi-listener", "backlog",
+		"socket-file", "stale-socket":
 		return "Port & process"
 	case "ipv6-only", "loopback-only", "firewall":
 		return "Network & reachability"
 	case "docker", "env", "vm":
 		return "Environment"
+	case "custom":
+		return "Custom rules"
 	default:
 		return "Other"
 	}
@@ -12,7 +12,11 @@ type ScanRows []struct {
 	Owner  string
 	PID    int32
 	Addr   string
+	Queue  string
 	Docker string
+	Netns  string
+	Probe  string // protocol found by --probe
+	Health string // readiness check result (--http, --tls, ...)
 	Hint   string
 	Error  string
 }
@@ -20,8 +24,28 @@ type ScanRows []struct {
 func ScanTableRows(rows ScanRows) string {
 	var b strings.Builder
 
-	b.WriteString("PORT   STATUS   OWNER                 PID     ADDR                 DOCKER              HINT\n")
-	b.WriteString("────   ──────   ────────────────────  ──────  ───────────────────  ──────────────────  ─────────────────────\n")
+	// the NETNS column only appears when --netns/--all-netns tagged rows,
+	// SERVES only with --probe, HEALTH only with a readiness check
+	withNetns, withProbe, withHealth := false, false, false
+	for _, r := range rows {
+		withNetns = withNetns || r.Netns != ""
+		withProbe = withProbe || r.Probe != ""
+		withHealth = withHealth || r.Health != ""
+	}
+	head, rule := "PORT   ", "────   "
+	if withNetns {
+		head, rule = head+"NETNS         ", rule+"────────────  "
+	}
+	head += "STATUS   OWNER                 PID     ADDR                 QUEUE       "
+	rule += "──────   ────────────────────  ──────  ───────────────────  ──────────  "
+	if withProbe {
+		head, rule = head+"SERVES                ", rule+"────────────────────  "
+	}
+	if withHealth {
+		head, rule = head+"HEALTH                ", rule+"────────────────────  "
+	}
+	b.WriteString(head + "DOCKER              HINT\n")
+	b.WriteString(rule + "──────────────────  ─────────────────────\n")
 
 	for _, r := range rows {
 		owner := trunc(r.Owner, 20)
@@ -35,8 +59,22 @@ func ScanTableRows(rows ScanRows) string {
 		if r.PID > 0 {
 			pid = fmt.Sprintf("%d", r.PID)
 		}
-		fmt.Fprintf(&b, "%-5d  %-7s  %-20s  %-6s  %-19s  %-18s  %-20s\n",
-			r.Port, r.Status, owner, pid, addr, docker, hint)
+		queue := r.Queue
+		if queue == "" {
+			queue = "-"
+		}
+		fmt.Fprintf(&b, "%-5d  ", r.Port)
+		if withNetns {
+			fmt.Fprintf(&b, "%-12s  ", trunc(r.Netns, 12))
+		}
+		fmt.Fprintf(&b, "%-7s  %-20s  %-6s  %-19s  %-10s  ", r.Status, owner, pid, addr, trunc(queue, 10))
+		if withProbe {
+			fmt.Fprintf(&b, "%-20s  ", trunc(dash(r.Probe), 20))
+		}
+		if withHealth {
+			fmt.Fprintf(&b, "%-20s  ", trunc(dash(r.Health), 20))
+		}
+		fmt.Fprintf(&b, "%-18s  %-20s\n", docker, hint)
 	}
 	return b.String()
 }
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// TLS renders the handshake result for a port: negotiated parameters, the
+// presented chain and any certificate findings.
+func TLS(port int, l model.Listener, info model.TLSInfo, findings []model.LintFinding, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TLS %d/tcp  %s", port, info.Addr)
+	if l.PID > 0 {
+		fmt.Fprintf(&b, "  pid %d (%s)", l.PID, dash(l.ProcName))
+	}
+	b.WriteString("\n\n")
+
+	neg := info.Version + " " + info.Cipher
+	if info.ALPN != "" {
+		neg += ", ALPN " + info.ALPN
+	}
+	fmt.Fprintf(&b, "  Negotiated  %s\n", neg)
+	fmt.Fprintf(&b, "  Accepts     %s\n", dash(strings.Join(info.Versions, ", ")))
+	if info.StartTLS != "" {
+		fmt.Fprintf(&b, "  STARTTLS    %s\n", info.StartTLS)
+	}
+	if info.ServerName != "" {
+		fmt.Fprintf(&b, "  SNI         %s\n", info.ServerName)
+	}
+
+	b.WriteString("\nCHAIN\n")
+	for i, c := range info.Chain {
+		fmt.Fprintf(&b, "  [%d] %s\n", i, dash(c.Subject))
+		issuer := dash(c.Issuer)
+		if c.SelfSigned {
+			issuer += " (self-signed)"
+		}
+		fmt.Fprintf(&b, "      Issuer   %s\n", issuer)
+		if sans := append(append([]string(nil), c.DNSNames...), c.IPs...); len(sans) > 0 {
+			fmt.Fprintf(&b, "      SANs     %s\n", strings.Join(sans, ", "))
+		}
+		key := c.Key
+		if c.KeyBits > 0 {
+			key = fmt.Sprintf("%s %d", c.Key, c.KeyBits)
+		}
+		fmt.Fprintf(&b, "      Key      %s, signed with %s\n", dash(key), dash(c.Signature))
+		fmt.Fprintf(&b, "      Valid    %s → %s (%s)\n", c.NotBefore.Format("2006-01-02"), c.NotAfter.Format("2006-01-02"), expiryLabel(c.NotAfter, now))
+	}
+
+	b.WriteString("\nFINDINGS\n")
+	if len(findings) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, f := range findings {
+		fmt.Fprintf(&b, "  %-5s %-18s %s\n", strings.ToUpper(f.Severity), f.Code, f.Summary)
+		if f.Action != "" {
+			fmt.Fprintf(&b, "        ↳ %s\n", f.Action)
+		}
+	}
+	return b.String()
+}
+
+func expiryLabel(notAfter, now time.Time) string {
+	d := int(notAfter.Sub(now).Hours() / 24)
+	switch {
+	case !notAfter.After(now) && d == 0:
+		return "expired today"
+	case !notAfter.After(now):
+		return fmt.Sprintf("expired %d days ago", -d)
+	case d == 0:
+		return "less than a day left"
+	case d == 1:
+		return "1 day left"
+	}
+	return fmt.Sprintf("%d days left", d)
+}
@@ -15,6 +15,10 @@ type TopRow struct {
 type TopClient struct {
 	IP    string
 	Count int
+
+	// from tcp_info, zero when the backend had none
+	AvgRTTMs float64 `json:",omitempty"`
+	Retrans  int     `json:",omitempty"`
 }
 
 func TopTable(rows []TopRow, opt Options) string {
@@ -50,7 +54,14 @@ func formatClients(clients []TopClient) string {
 	}
 	var parts []string
 	for _, c := range clients {
-		parts = append(parts, fmt.Sprintf("%s(%d)", c.IP, c.Count))
+		var extra string
+		if c.AvgRTTMs > 0 {
+			extra += fmt.Sprintf(" rtt=%.2fms", c.AvgRTTMs)
+		}
+		if c.Retrans > 0 {
+			extra += fmt.Sprintf(" retrans=%d", c.Retrans)
+		}
+		parts = append(parts, fmt.Sprintf("%s(%d%s)", c.IP, c.Count, extra))
 	}
 	return strings.Join(parts, ", ")
 }
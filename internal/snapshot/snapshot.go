@@ -0,0 +1,177 @@
+// Package snapshot captures everything portik reads from a host into one
+// bundle file, and replays a bundle so commands run against it instead of
+// the live system.
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/docker"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/proc"
+	"github.com/pratik-anurag/portik/internal/sockets"
+)
+
+// Version is bumped when the bundle layout changes incompatibly.
+const Version = 1
+
+// Bundle is a self-contained snapshot of one host.
+type Bundle struct {
+	Version   int                `json:"version"`
+	Portik    string             `json:"portik,omitempty"` // version that captured it
+	Backend   string             `json:"backend,omitempty"`
+	Env       platform.Env       `json:"env"`
+	Sockets   sockets.Tables     `json:"sockets"`
+	Processes []proc.Info        `json:"processes"`
+	Docker    []docker.Container `json:"docker"` // null when not captured
+}
+
+// Options control what Capture collects.
+type Options struct {
+	Docker bool   // also record containers and their published ports
+	Portik string // version string stored in the bundle
+}
+
+// Capture reads the live system. Processes are limited to those holding a
+// socket and their ancestors, which is what enrichment and trace look up.
+func Capture(opt Options) (Bundle, error) {
+	tables, err := sockets.Capture()
+	if err != nil {
+		return Bundle{}, err
+	}
+	b := Bundle{
+		Version:   Version,
+		Portik:    opt.Portik,
+		Backend:   sockets.Backend(),
+		Sockets:   tables,
+		Processes: captureProcs(socketPIDs(tables)),
+	}
+	var files []string
+	for _, s := range tables.Unix {
+		if s.Path != "" && !strings.HasPrefix(s.Path, "@") {
+			files = append(files, s.Path)
+		}
+	}
+	b.Env = platform.CaptureEnv(files)
+	if opt.Docker {
+		b.Docker = docker.Containers()
+	}
+	return b, nil
+}
+
+func socketPIDs(t sockets.Tables) []int32 {
+	var pids []int32
+	for _, ls := range t.Listeners {
+		for _, l := range ls {
+			pids = append(pids, l.PID)
+			for _, h := range l.Holders {
+				pids = append(pids, h.PID)
+			}
+		}
+	}
+	for _, cs := range t.Connections {
+		for _, c := range cs {
+			pids = append(pids, c.PID)
+		}
+	}
+	for _, s := range t.Unix {
+		pids = append(pids, s.PID)
+		for _, h := range s.Holders {
+			pids = append(pids, h.PID)
+		}
+	}
+	return pids
+}
+
+func captureProcs(pids []int32) []proc.Info {
+	seen := map[int32]bool{}
+	var out []proc.Info
+	for _, pid := range pids {
+		// walk up to init so trace/blame can rebuild the process chain
+		for pid > 0 && !seen[pid] {
+			seen[pid] = true
+			info, ok := proc.Lookup(pid)
+			if !ok {
+				break
+			}
+			out = append(out, info)
+			pid = info.PPID
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PID < out[j].PID })
+	return out
+}
+
+// Install makes sockets, proc, docker and platform answer from b.
+func (b Bundle) Install() {
+	sockets.Replay(b.Sockets)
+	proc.Replay(b.Processes)
+	docker.Replay(b.Docker)
+	platform.Replay(b.Env)
+}
+
+// Write saves b to path, gzip-compressed when path ends in ".gz".
+func Write(path string, b Bundle) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = f
+	var zw *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(b)
+	if zw != nil {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Load reads a bundle written by Write; compression is detected from the
+// content, so renamed files still load.
+func Load(path string) (Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Bundle{}, err
+	}
+	defer f.Close()
+	return decode(f)
+}
+
+func decode(r io.Reader) (Bundle, error) {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return Bundle{}, err
+		}
+		defer zr.Close()
+		src = zr
+	}
+	var b Bundle
+	if err := json.NewDecoder(src).Decode(&b); err != nil {
+		return Bundle{}, fmt.Errorf("not a portik bundle: %w", err)
+	}
+	if b.Version == 0 || b.Version > Version {
+		return Bundle{}, fmt.Errorf("unsupported bundle version %d (this portik reads up to %d)", b.Version, Version)
+	}
+	return b, nil
+}
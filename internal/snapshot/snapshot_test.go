@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pratik-anurag/portik/internal/inspect"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/proctree"
+)
+
+func TestWriteLoadRoundTrip(t *testing.T) {
+	b, err := Load(filepath.Join("testdata", "bundle.json"))
+	if err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	for _, name := range []string{"b.json", "b.json.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := Write(path, b); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		got, err := Load(path)
+		if err != nil {
+			t.Fatalf("load %s: %v", name, err)
+		}
+		if got.Env.Host.Hostname != "db-1" || len(got.Sockets.Listeners["tcp"]) != 1 || len(got.Processes) != 2 {
+			t.Fatalf("%s: unexpected bundle after round trip: %+v", name, got)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	_, err := decode(strings.NewReader(`{"version": 99}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported bundle version") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+	if _, err := decode(strings.NewReader("not json")); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
+
+func TestInstallReplaysBundle(t *testing.T) {
+	b, err := Load(filepath.Join("testdata", "bundle.json"))
+	if err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	b.Install()
+
+	rep, err := inspect.InspectPort(5432, "tcp", inspect.Options{IncludeConnections: true})
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	l, ok := rep.PrimaryListener()
+	if !ok || l.PID != 900 || l.User != "postgres" || !strings.Contains(l.Cmdline, "postgresql") {
+		t.Fatalf("listener not enriched from the bundle: %+v", rep.Listeners)
+	}
+	if len(rep.Connections) != 1 || rep.Host.Hostname != "db-1" || rep.User.Username != "sre" {
+		t.Fatalf("unexpected report: conns=%d host=%q user=%q", len(rep.Connections), rep.Host.Hostname, rep.User.Username)
+	}
+	if !rep.Generated.Equal(b.Env.Captured) {
+		t.Fatalf("expected capture time, got %v", rep.Generated)
+	}
+	if fw := platform.FirewallStatus(); !fw.Active || fw.Name != "ufw" {
+		t.Fatalf("firewall not replayed: %+v", fw)
+	}
+
+	if _, started := proctree.Build(900, 4); started.Kind != "systemd" || started.Details != "postgresql.service" {
+		t.Fatalf("expected systemd unit from captured cgroup, got %+v", started)
+	}
+
+	urep, err := inspect.InspectUnix("/run/pg.sock", inspect.Options{})
+	if err != nil {
+		t.Fatalf("inspect unix: %v", err)
+	}
+	for _, d := range urep.Diagnostics {
+		if d.Kind == "socket-file" || d.Kind == "stale-socket" {
+			t.Fatalf("socket file should be found in the bundle: %+v", d)
+		}
+	}
+}
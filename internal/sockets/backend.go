@@ -0,0 +1,45 @@
+package sockets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Backend names accepted by SetBackend. Not every backend exists on every OS;
+// see Backends for the ones available on the current platform.
+const (
+	BackendAuto    = "auto"
+	BackendNetlink = "netlink" // linux: NETLINK_SOCK_DIAG dump
+	BackendProcfs  = "procfs"  // linux: read /proc/net/* directly
+	BackendSS      = "ss"      // linux: shell out to ss
+	BackendLsof    = "lsof"    // darwin: shell out to lsof
+)
+
+var backend = BackendAuto
+
+// SetBackend selects how sockets are enumerated. "auto" picks the best
+// backend available at runtime.
+func SetBackend(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = BackendAuto
+	}
+	for _, b := range backends {
+		if b == name {
+			backend = name
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported socket backend %q (available: %s)", name, strings.Join(backends, "|"))
+}
+
+// Backend returns the selected backend name, "auto" unless SetBackend
+// chose another.
+func Backend() string {
+	return backend
+}
+
+// Backends lists the backend names usable on this OS.
+func Backends() []string {
+	return append([]string(nil), backends...)
+}
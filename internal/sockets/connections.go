@@ -12,5 +12,8 @@ func ListConnections(proto string) ([]model.Conn, error) {
 	if proto != "tcp" {
 		return nil, fmt.Errorf("unsupported proto: %s", proto)
 	}
+	if replayed != nil {
+		return append([]model.Conn(nil), replayed.Connections[proto]...), nil
+	}
 	return listConnections(proto)
 }
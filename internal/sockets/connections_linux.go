@@ -10,16 +10,35 @@ import (
 )
 
 func listConnections(proto string) ([]model.Conn, error) {
-	args := []string{"-H", "-tanp"}
+	socks, ok, err := kernelSockets(proto)
+	if !ok {
+		return listConnectionsSS(proto)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return listConnectionsKernel(socks, proto), nil
+}
+
+func listConnectionsSS(proto string) ([]model.Conn, error) {
+	args := []string{"-H", "-tanpi"}
 	out, err := exec.Command("ss", args...).Output()
 	if err != nil {
 		return nil, err
 	}
 
 	var conns []model.Conn
+	last := false // whether the socket line before was kept
 	for _, line := range splitLines(out) {
+		// -i adds an indented tcp_info line after each socket
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ") {
+			if info, ok := parseSSInfo(line); ok && last {
+				conns[len(conns)-1].TCP = info
+			}
+			continue
+		}
 		parsed, ok := parseSSLine(line)
-		if !ok {
+		if last = ok; !ok {
 			continue
 		}
 		lip, lp := splitHostPort(parsed.laddr)
@@ -31,6 +50,8 @@ func listConnections(proto string) ([]model.Conn, error) {
 			RemotePort: rp,
 			Family:     familyFromIP(lip),
 			State:      parsed.state,
+			RecvQ:      parsed.recvQ,
+			SendQ:      parsed.sendQ,
 			PID:        int32(parsed.pid),
 			ProcName:   parsed.proc,
 		})
@@ -13,7 +13,7 @@ import (
 )
 
 func inspectDarwin(port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
-	var listeners []model.Listener
+	var rows []lsofListener
 	var conns []model.Conn
 
 	args := []string{"-nP", fmt.Sprintf("-i%s:%d", strings.ToUpper(proto), port)}
@@ -28,21 +28,20 @@ func inspectDarwin(port int, proto string, includeConnections bool) ([]model.Lis
 		if !ok {
 			continue
 		}
-		cwd := getCwd(parsed.pid)
 		ip, p := parseLsofAddr(parsed.addr)
 		fam := familyFromIP(ip)
 
 		if parsed.state == "LISTEN" && p == port {
-			listeners = append(listeners, model.Listener{
-				LocalIP:    ip,
-				LocalPort:  p,
-				Family:     fam,
-				State:      "LISTEN",
-				PID:        int32(parsed.pid),
-				ProcName:   parsed.cmd,
-				WorkingDir: cwd,
-				User:       parsed.user,
-			})
+			rows = append(rows, lsofListener{device: parsed.device, l: model.Listener{
+				LocalIP:   ip,
+				LocalPort: p,
+				Family:    fam,
+				State:     "LISTEN",
+				PID:       int32(parsed.pid),
+				ProcName:  parsed.cmd,
+				User:      parsed.user,
+				Holders:   []model.SocketHolder{{PID: int32(parsed.pid), ProcName: parsed.cmd, FD: parsed.fd}},
+			}})
 		} else if includeConnections {
 			lip, lp, rip, rp := parseLsofConn(parsed.addr)
 			conns = append(conns, model.Conn{
@@ -58,6 +57,10 @@ func inspectDarwin(port int, proto string, includeConnections bool) ([]model.Lis
 		}
 	}
 
+	listeners := mergeLsofListeners(rows)
+	for i := range listeners {
+		listeners[i].WorkingDir = getCwd(int(listeners[i].PID))
+	}
 	return listeners, conns, nil
 }
 
@@ -0,0 +1,146 @@
+//go:build linux
+
+package sockets
+
+import "github.com/pratik-anurag/portik/internal/model"
+
+// Shared by the procfs and netlink backends: both produce a raw socket table
+// that is filtered here and mapped to processes via inodeOwners.
+
+// kernelSockets returns the socket table for proto from the selected kernel
+// backend. ok is false when the ss backend should be used instead.
+func kernelSockets(proto string) (socks []rawSocket, ok bool, err error) {
+	switch backend {
+	case BackendSS:
+		return nil, false, nil
+	case BackendNetlink:
+		socks, err = dumpNetlink(proto)
+		return socks, true, err
+	case BackendProcfs:
+		socks, err = readProcNet(proto)
+		return socks, true, err
+	}
+	if socks, err = dumpNetlink(proto); err == nil {
+		return socks, true, nil
+	}
+	if procfsAvailable() {
+		socks, err = readProcNet(proto)
+		return socks, true, err
+	}
+	return nil, false, nil
+}
+
+func isListenSocket(proto string, s rawSocket) bool {
+	if proto == "udp" {
+		return s.state == "UNCONN"
+	}
+	return s.state == "LISTEN"
+}
+
+func inspectKernel(socks []rawSocket, port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn) {
+	var ls, cs []rawSocket
+	want := map[uint64]bool{}
+	for _, s := range socks {
+		switch {
+		case isListenSocket(proto, s) && s.lport == port:
+			ls = append(ls, s)
+		case includeConnections && proto == "tcp" && !isListenSocket(proto, s) && (s.lport == port || s.rport == port):
+			cs = append(cs, s)
+		default:
+			continue
+		}
+		if s.inode != 0 {
+			want[s.inode] = true
+		}
+	}
+	owners := inodeOwners(want)
+
+	listeners := make([]model.Listener, 0, len(ls))
+	for _, s := range ls {
+		l := kernelListener(s, owners[s.inode])
+		l.WorkingDir = readCwd(int(l.PID))
+		listeners = append(listeners, l)
+	}
+	conns := make([]model.Conn, 0, len(cs))
+	for _, s := range cs {
+		conns = append(conns, kernelConn(s, owners[s.inode]))
+	}
+	return listeners, conns
+}
+
+func listListenersKernel(socks []rawSocket, proto string) []model.Listener {
+	var ls []rawSocket
+	want := map[uint64]bool{}
+	for _, s := range socks {
+		if !isListenSocket(proto, s) || s.lport == 0 {
+			continue
+		}
+		ls = append(ls, s)
+		want[s.inode] = true
+	}
+	owners := inodeOwners(want)
+
+	listeners := make([]model.Listener, 0, len(ls))
+	for _, s := range ls {
+		listeners = append(listeners, kernelListener(s, owners[s.inode]))
+	}
+	return listeners
+}
+
+func listConnectionsKernel(socks []rawSocket, proto string) []model.Conn {
+	var cs []rawSocket
+	want := map[uint64]bool{}
+	for _, s := range socks {
+		if isListenSocket(proto, s) {
+			continue
+		}
+		cs = append(cs, s)
+		want[s.inode] = true
+	}
+	owners := inodeOwners(want)
+
+	conns := make([]model.Conn, 0, len(cs))
+	for _, s := range cs {
+		conns = append(conns, kernelConn(s, owners[s.inode]))
+	}
+	return conns
+}
+
+func kernelListener(s rawSocket, owners []inodeOwner) model.Listener {
+	l := model.Listener{
+		LocalIP:   s.laddr,
+		LocalPort: s.lport,
+		Family:    familyFromIP(s.laddr),
+		State:     s.state,
+		RecvQ:     s.rxQueue,
+		SendQ:     s.txQueue,
+	}
+	for _, o := range owners {
+		l.Holders = append(l.Holders, model.SocketHolder{PID: int32(o.pid), ProcName: o.comm, FD: o.fd})
+	}
+	if len(owners) > 0 {
+		l.PID = int32(owners[0].pid)
+		l.ProcName = owners[0].comm
+	}
+	return l
+}
+
+func kernelConn(s rawSocket, owners []inodeOwner) model.Conn {
+	var o inodeOwner
+	if len(owners) > 0 {
+		o = owners[0]
+	}
+	return model.Conn{
+		LocalIP:    s.laddr,
+		LocalPort:  s.lport,
+		RemoteIP:   s.raddr,
+		RemotePort: s.rport,
+		Family:     familyFromIP(s.laddr),
+		State:      s.state,
+		RecvQ:      s.rxQueue,
+		SendQ:      s.txQueue,
+		PID:        int32(o.pid),
+		ProcName:   o.comm,
+		TCP:        s.tcp.toModel(),
+	}
+}
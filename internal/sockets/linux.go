@@ -38,16 +38,26 @@ func inspectLinux(port int, proto string, includeConnections bool) ([]model.List
 			LocalPort:  p,
 			Family:     familyFromIP(ip),
 			State:      parsed.state,
+			RecvQ:      parsed.recvQ,
+			SendQ:      parsed.sendQ,
 			PID:        int32(parsed.pid),
 			ProcName:   parsed.proc,
 			WorkingDir: cwd,
+			Holders:    parsed.users,
 		})
 	}
 
 	if includeConnections && proto == "tcp" {
-		args := []string{"-H", "-tanp", fmt.Sprintf("( sport = :%d or dport = :%d )", port, port)}
+		args := []string{"-H", "-tanpi", fmt.Sprintf("( sport = :%d or dport = :%d )", port, port)}
 		out2, _ := exec.Command("ss", args...).Output()
 		for _, line := range splitLines(out2) {
+			// -i adds an indented tcp_info line after each socket
+			if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ") {
+				if info, ok := parseSSInfo(line); ok && len(conns) > 0 {
+					conns[len(conns)-1].TCP = info
+				}
+				continue
+			}
 			parsed, ok := parseSSLine(line)
 			if !ok {
 				continue
@@ -62,6 +72,8 @@ func inspectLinux(port int, proto string, includeConnections bool) ([]model.List
 				RemotePort: rp,
 				Family:     familyFromIP(lip),
 				State:      parsed.state,
+				RecvQ:      parsed.recvQ,
+				SendQ:      parsed.sendQ,
 				PID:        int32(parsed.pid),
 				ProcName:   parsed.proc,
 			})
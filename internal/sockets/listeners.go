@@ -12,5 +12,8 @@ func ListListeners(proto string) ([]model.Listener, error) {
 	if proto != "tcp" && proto != "udp" {
 		return nil, fmt.Errorf("unsupported proto: %s", proto)
 	}
+	if replayed != nil {
+		return append([]model.Listener(nil), replayed.Listeners[proto]...), nil
+	}
 	return listListeners(proto)
 }
@@ -26,7 +26,7 @@ func listListeners(proto string) ([]model.Listener, error) {
 		return nil, err
 	}
 
-	var listeners []model.Listener
+	var rows []lsofListener
 	for _, line := range strings.Split(strings.TrimSpace(string(bytes.TrimSpace(out))), "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" || strings.HasPrefix(line, "COMMAND") {
@@ -40,6 +40,8 @@ func listListeners(proto string) ([]model.Listener, error) {
 		user := m[reLsof.SubexpIndex("user")]
 		state := strings.ToUpper(strings.TrimSpace(m[reLsof.SubexpIndex("state")]))
 		addr := m[reLsof.SubexpIndex("addr")]
+		fd := parseInt(m[reLsof.SubexpIndex("fd")])
+		device := m[reLsof.SubexpIndex("device")]
 
 		var pid int
 		_, _ = fmt.Sscanf(m[reLsof.SubexpIndex("pid")], "%d", &pid)
@@ -49,7 +51,7 @@ func listListeners(proto string) ([]model.Listener, error) {
 			continue
 		}
 
-		listeners = append(listeners, model.Listener{
+		rows = append(rows, lsofListener{device: device, l: model.Listener{
 			LocalIP:   ip,
 			LocalPort: p,
 			Family:    familyFromIP(ip),
@@ -57,7 +59,8 @@ func listListeners(proto string) ([]model.Listener, error) {
 			PID:       int32(pid),
 			ProcName:  cmd,
 			User:      user,
-		})
+			Holders:   []model.SocketHolder{{PID: int32(pid), ProcName: cmd, FD: fd}},
+		}})
 	}
-	return listeners, nil
+	return mergeLsofListeners(rows), nil
 }
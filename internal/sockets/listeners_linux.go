@@ -11,6 +11,17 @@ import (
 )
 
 func listListeners(proto string) ([]model.Listener, error) {
+	socks, ok, err := kernelSockets(proto)
+	if !ok {
+		return listListenersSS(proto)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return listListenersKernel(socks, proto), nil
+}
+
+func listListenersSS(proto string) ([]model.Listener, error) {
 	ssArgs := []string{"-H"}
 	if proto == "tcp" {
 		ssArgs = append(ssArgs, "-ltnp")
@@ -25,18 +36,12 @@ func listListeners(proto string) ([]model.Listener, error) {
 
 	var listeners []model.Listener
 	for _, line := range strings.Split(strings.TrimSpace(string(bytes.TrimSpace(out))), "\n") {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		m := reSS.FindStringSubmatch(line)
-		if m == nil {
+		// For UDP, ss still shows UNCONN, but we treat as listener-like.
+		parsed, ok := parseSSLine(line)
+		if !ok {
 			continue
 		}
-		state := strings.ToUpper(m[reSS.SubexpIndex("state")])
-		// For UDP, ss still shows UNCONN, but we treat as listener-like.
-		laddr := m[reSS.SubexpIndex("laddr")]
-		pid, pname := parseUsers(m[reSS.SubexpIndex("users")])
-		ip, p := splitHostPort(laddr)
+		ip, p := splitHostPort(parsed.laddr)
 		if p == 0 {
 			continue
 		}
@@ -44,9 +49,12 @@ func listListeners(proto string) ([]model.Listener, error) {
 			LocalIP:   ip,
 			LocalPort: p,
 			Family:    familyFromIP(ip),
-			State:     state,
-			PID:       int32(pid),
-			ProcName:  pname,
+			State:     parsed.state,
+			RecvQ:     parsed.recvQ,
+			SendQ:     parsed.sendQ,
+			PID:       int32(parsed.pid),
+			ProcName:  parsed.proc,
+			Holders:   parsed.users,
 		})
 	}
 	return listeners, nil
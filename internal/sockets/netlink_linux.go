@@ -0,0 +1,143 @@
+//go:build linux
+
+package sockets
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// netlink backend: one NETLINK_SOCK_DIAG dump per address family returns
+// every socket with its inode, uid, queues and (for TCP) tcp_info.
+
+const sockDiagByFamily = 20 // SOCK_DIAG_BY_FAMILY
+
+func dumpNetlink(proto string) ([]rawSocket, error) {
+	var ipproto uint8
+	switch proto {
+	case "tcp":
+		ipproto = syscall.IPPROTO_TCP
+	case "udp":
+		ipproto = syscall.IPPROTO_UDP
+	default:
+		return nil, fmt.Errorf("unsupported proto: %s", proto)
+	}
+
+	fd, err := openSockDiag()
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	var out []rawSocket
+	for i, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		socks, err := diagDump(fd, uint32(i+1), family, ipproto)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, socks...)
+	}
+	return out, nil
+}
+
+func openSockDiag() (int, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_INET_DIAG)
+	if err != nil {
+		return -1, os.NewSyscallError("socket", err)
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		syscall.Close(fd)
+		return -1, os.NewSyscallError("bind", err)
+	}
+	return fd, nil
+}
+
+func diagDump(fd int, seq uint32, family, ipproto uint8) ([]rawSocket, error) {
+	body := make([]byte, sizeofInetDiagReqV2)
+	body[0] = family
+	body[1] = ipproto
+	if ipproto == syscall.IPPROTO_TCP {
+		body[2] = 1 << (inetDiagInfo - 1)
+	}
+	binary.NativeEndian.PutUint32(body[4:8], 0xffffffff) // all states
+
+	var out []rawSocket
+	err := sockDiagDump(fd, seq, body, func(b []byte) {
+		if s, ok := parseDiagMsg(b); ok {
+			out = append(out, s)
+		}
+	})
+	return out, err
+}
+
+// dumpUnixNetlink lists every AF_UNIX socket with its name, peer inode and
+// queue lengths.
+func dumpUnixNetlink() ([]rawUnix, error) {
+	fd, err := openSockDiag()
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	body := make([]byte, sizeofUnixDiagReq)
+	ne := binary.NativeEndian
+	body[0] = syscall.AF_UNIX
+	ne.PutUint32(body[4:8], 0xffffffff) // all states
+	ne.PutUint32(body[12:16], udiagShowName|udiagShowPeer|udiagShowRQLen)
+
+	var out []rawUnix
+	err = sockDiagDump(fd, 1, body, func(b []byte) {
+		if s, ok := parseUnixDiagMsg(b); ok {
+			out = append(out, s)
+		}
+	})
+	return out, err
+}
+
+// sockDiagDump sends one SOCK_DIAG_BY_FAMILY dump request and calls each for
+// every reply payload until the kernel signals the end of the dump.
+func sockDiagDump(fd int, seq uint32, body []byte, each func([]byte)) error {
+	req := make([]byte, syscall.NLMSG_HDRLEN+len(body))
+	ne := binary.NativeEndian
+	ne.PutUint32(req[0:4], uint32(len(req)))
+	ne.PutUint16(req[4:6], sockDiagByFamily)
+	ne.PutUint16(req[6:8], syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP)
+	ne.PutUint32(req[8:12], seq)
+	copy(req[syscall.NLMSG_HDRLEN:], body)
+
+	if err := syscall.Sendto(fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return os.NewSyscallError("sendto", err)
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return os.NewSyscallError("recvfrom", err)
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != seq {
+				continue
+			}
+			switch m.Header.Type {
+			case syscall.NLMSG_DONE:
+				return nil
+			case syscall.NLMSG_ERROR:
+				if len(m.Data) >= 4 {
+					if errno := int32(ne.Uint32(m.Data[0:4])); errno != 0 {
+						return os.NewSyscallError("sock_diag", syscall.Errno(-errno))
+					}
+				}
+				return nil
+			case sockDiagByFamily:
+				each(m.Data)
+			}
+		}
+	}
+}
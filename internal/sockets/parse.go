@@ -12,3 +12,7 @@ func parseInt(s string) int {
 	}
 	return n
 }
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,98 @@
+package sockets
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+// NETLINK_SOCK_DIAG (linux/inet_diag.h). Integers are host byte order except
+// the ports and addresses inside inet_diag_sockid, which are network order.
+const (
+	sizeofInetDiagReqV2 = 56
+	sizeofInetDiagMsg   = 72
+	inetDiagInfo        = 2 // INET_DIAG_INFO attribute: struct tcp_info
+)
+
+// inet_diag_msg:
+//
+//	u8 family, state, timer, retrans
+//	inet_diag_sockid id (sport, dport, src[16], dst[16], if, cookie[2])
+//	u32 expires, rqueue, wqueue, uid, inode
+//	followed by rtattr-encoded extensions
+func parseDiagMsg(b []byte) (rawSocket, bool) {
+	if len(b) < sizeofInetDiagMsg {
+		return rawSocket{}, false
+	}
+	family := b[0]
+	var lip, rip netip.Addr
+	switch family {
+	case 2: // AF_INET
+		lip = netip.AddrFrom4([4]byte(b[8:12]))
+		rip = netip.AddrFrom4([4]byte(b[24:28]))
+	case 10: // AF_INET6
+		lip = netip.AddrFrom16([16]byte(b[8:24]))
+		rip = netip.AddrFrom16([16]byte(b[24:40]))
+	default:
+		return rawSocket{}, false
+	}
+	ne := binary.NativeEndian
+	s := rawSocket{
+		laddr:   lip.String(),
+		lport:   int(binary.BigEndian.Uint16(b[4:6])),
+		raddr:   rip.String(),
+		rport:   int(binary.BigEndian.Uint16(b[6:8])),
+		state:   tcpStateName(int(b[1])),
+		rxQueue: int(ne.Uint32(b[56:60])),
+		txQueue: int(ne.Uint32(b[60:64])),
+		uid:     int(ne.Uint32(b[64:68])),
+		inode:   uint64(ne.Uint32(b[68:72])),
+	}
+
+	// rtattrs: u16 len, u16 type, payload, padded to 4 bytes
+	attrs := b[sizeofInetDiagMsg:]
+	for len(attrs) >= 4 {
+		alen := int(ne.Uint16(attrs[0:2]))
+		if alen < 4 || alen > len(attrs) {
+			break
+		}
+		if ne.Uint16(attrs[2:4]) == inetDiagInfo {
+			s.tcp = parseTCPInfo(attrs[4:alen])
+		}
+		next := (alen + 3) &^ 3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	return s, true
+}
+
+// parseTCPInfo decodes struct tcp_info. Older kernels send a shorter struct,
+// so fields past the end of b are left zero.
+func parseTCPInfo(b []byte) *tcpInfo {
+	if len(b) < 104 {
+		return nil
+	}
+	ne := binary.NativeEndian
+	u32 := func(off int) uint32 { return ne.Uint32(b[off : off+4]) }
+	u64 := func(off int) uint64 {
+		if len(b) < off+8 {
+			return 0
+		}
+		return ne.Uint64(b[off : off+8])
+	}
+	return &tcpInfo{
+		retransmits:    b[2],
+		rto:            u32(8),
+		unacked:        u32(24),
+		lastDataSentMs: u32(44),
+		lastDataRecvMs: u32(52),
+		rtt:            u32(68),
+		rttVar:         u32(72),
+		sndCwnd:        u32(80),
+		totalRetrans:   u32(100),
+		bytesAcked:     u64(120),
+		bytesReceived:  u64(128),
+		bytesSent:      u64(200),
+	}
+}
@@ -3,18 +3,24 @@ package sockets
 import (
 	"regexp"
 	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
 )
 
 // lsof -nP -iTCP:5432
-// postgres 8123 me  6u  IPv6 ... TCP [::1]:5432 (LISTEN)
-var reLsof = regexp.MustCompile(`^(?P<cmd>\S+)\s+(?P<pid>\d+)\s+(?P<user>\S+)\s+.*\sTCP\s+(?P<addr>\S+)\s+\((?P<state>[^)]+)\)\s*$`)
+// postgres 8123 me  6u  IPv6 0x1234 0t0  TCP [::1]:5432 (LISTEN)
+// DEVICE (0x1234) is the kernel socket address: processes sharing a socket
+// print the same value.
+var reLsof = regexp.MustCompile(`^(?P<cmd>\S+)\s+(?P<pid>\d+)\s+(?P<user>\S+)\s+(?P<fd>\d*)\S*\s+\S+\s+(?P<device>\S+)\s+.*\sTCP\s+(?P<addr>\S+)\s+\((?P<state>[^)]+)\)\s*$`)
 
 type lsofLine struct {
-	cmd   string
-	user  string
-	addr  string
-	state string
-	pid   int
+	cmd    string
+	user   string
+	addr   string
+	state  string
+	pid    int
+	fd     int
+	device string
 }
 
 func parseLsofLine(line string) (lsofLine, bool) {
@@ -34,7 +40,53 @@ func parseLsofLine(line string) (lsofLine, bool) {
 	if pid <= 0 {
 		return lsofLine{}, false
 	}
-	return lsofLine{cmd: cmd, user: user, addr: addr, state: state, pid: pid}, true
+	fd := parseInt(m[reLsof.SubexpIndex("fd")])
+	device := m[reLsof.SubexpIndex("device")]
+	return lsofLine{cmd: cmd, user: user, addr: addr, state: state, pid: pid, fd: fd, device: device}, true
+}
+
+// lsofListener is a listener row plus the socket DEVICE it came from.
+type lsofListener struct {
+	device string
+	l      model.Listener
+}
+
+// mergeLsofListeners folds rows for the same socket into one listener whose
+// Holders lists every process (lsof prints one row per process and fd).
+func mergeLsofListeners(in []lsofListener) []model.Listener {
+	var out []model.Listener
+	index := map[string]int{}
+	for _, x := range in {
+		h := model.SocketHolder{PID: x.l.PID, ProcName: x.l.ProcName}
+		if len(x.l.Holders) > 0 {
+			h = x.l.Holders[0]
+		}
+		key := x.device
+		if key == "" {
+			key = x.l.LocalIP + "|" + x.l.State + "|" + itoa(x.l.LocalPort) + "|" + itoa(int(x.l.PID))
+		}
+		i, ok := index[key]
+		if !ok {
+			x.l.Holders = []model.SocketHolder{h}
+			index[key] = len(out)
+			out = append(out, x.l)
+			continue
+		}
+		dup := false
+		for _, e := range out[i].Holders {
+			if e.PID == h.PID {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			out[i].Holders = append(out[i].Holders, h)
+			if h.PID < out[i].PID {
+				out[i].PID, out[i].ProcName, out[i].User = h.PID, h.ProcName, x.l.User
+			}
+		}
+	}
+	return out
 }
 
 func parseLsofAddr(addr string) (string, int) {
@@ -0,0 +1,93 @@
+package sockets
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// /proc/net/tcp (tcp6, udp and udp6 share the layout)
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	 0: 0100007F:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 31337 1 ...
+func parseProcNetLine(line string) (rawSocket, bool) {
+	f := strings.Fields(line)
+	if len(f) < 10 || !strings.HasSuffix(f[0], ":") {
+		return rawSocket{}, false
+	}
+	lip, lp, ok := decodeProcNetAddr(f[1])
+	if !ok {
+		return rawSocket{}, false
+	}
+	rip, rp, ok := decodeProcNetAddr(f[2])
+	if !ok {
+		return rawSocket{}, false
+	}
+	st, err := strconv.ParseInt(f[3], 16, 32)
+	if err != nil {
+		return rawSocket{}, false
+	}
+	tx, rx := 0, 0
+	if q := strings.SplitN(f[4], ":", 2); len(q) == 2 {
+		tx = parseHexInt(q[0])
+		rx = parseHexInt(q[1])
+	}
+	inode, err := strconv.ParseUint(f[9], 10, 64)
+	if err != nil {
+		return rawSocket{}, false
+	}
+	state := tcpStateName(int(st))
+	if state == "LISTEN" {
+		// tx_queue of a listener is unsent data, not the backlog sock_diag
+		// reports there; leave the backlog unknown
+		tx = 0
+	}
+	return rawSocket{
+		laddr:   lip,
+		lport:   lp,
+		raddr:   rip,
+		rport:   rp,
+		state:   state,
+		txQueue: tx,
+		rxQueue: rx,
+		uid:     parseInt(f[7]),
+		inode:   inode,
+	}, true
+}
+
+// decodeProcNetAddr decodes "0100007F:1538" or the 32-hex-digit IPv6 form.
+// The kernel prints each 32-bit word of the address in host byte order.
+func decodeProcNetAddr(s string) (string, int, bool) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	raw, err := hex.DecodeString(s[:i])
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", 0, false
+	}
+	port, err := strconv.ParseUint(s[i+1:], 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+	for w := 0; w < len(raw); w += 4 {
+		binary.BigEndian.PutUint32(raw[w:], binary.NativeEndian.Uint32(raw[w:]))
+	}
+	var addr netip.Addr
+	if len(raw) == 4 {
+		addr = netip.AddrFrom4([4]byte(raw))
+	} else {
+		addr = netip.AddrFrom16([16]byte(raw))
+	}
+	return addr.String(), int(port), true
+}
+
+func parseHexInt(s string) int {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 16, 64)
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
@@ -2,23 +2,30 @@ package sockets
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/pratik-anurag/portik/internal/model"
 )
 
 // ss -H -ltnp 'sport = :5432'
 // LISTEN 0 4096 127.0.0.1:5432 0.0.0.0:* users:(("postgres",pid=8123,fd=7))
 var (
-	reSS        = regexp.MustCompile(`^(?P<state>\S+)\s+\d+\s+\d+\s+(?P<laddr>\S+)\s+(?P<raddr>\S+)\s*(?P<users>users:\(\(.*\)\))?$`)
+	reSS        = regexp.MustCompile(`^(?P<state>\S+)\s+(?P<recvq>\d+)\s+(?P<sendq>\d+)\s+(?P<laddr>\S+)\s+(?P<raddr>\S+)\s*(?P<users>users:\(\(.*\)\))?$`)
 	reUsersPid  = regexp.MustCompile(`pid=(\d+)`)
 	reUsersProc = regexp.MustCompile(`\(\("([^"]+)"`)
+	reUsersAll  = regexp.MustCompile(`\("([^"]*)",pid=(\d+),fd=(\d+)\)`)
 )
 
 type ssLine struct {
 	state string
+	recvQ int
+	sendQ int
 	laddr string
 	raddr string
 	pid   int
 	proc  string
+	users []model.SocketHolder
 }
 
 func parseSSLine(line string) (ssLine, bool) {
@@ -33,8 +40,18 @@ func parseSSLine(line string) (ssLine, bool) {
 	state := strings.ToUpper(m[reSS.SubexpIndex("state")])
 	laddr := m[reSS.SubexpIndex("laddr")]
 	raddr := m[reSS.SubexpIndex("raddr")]
-	pid, pname := parseUsers(m[reSS.SubexpIndex("users")])
-	return ssLine{state: state, laddr: laddr, raddr: raddr, pid: pid, proc: pname}, true
+	users := m[reSS.SubexpIndex("users")]
+	pid, pname := parseUsers(users)
+	return ssLine{
+		state: state,
+		recvQ: parseInt(m[reSS.SubexpIndex("recvq")]),
+		sendQ: parseInt(m[reSS.SubexpIndex("sendq")]),
+		laddr: laddr,
+		raddr: raddr,
+		pid:   pid,
+		proc:  pname,
+		users: parseUsersAll(users),
+	}, true
 }
 
 func parseUsers(users string) (pid int, proc string) {
@@ -49,3 +66,76 @@ func parseUsers(users string) (pid int, proc string) {
 	}
 	return pid, proc
 }
+
+// parseUsersAll returns every process listed in users:(...). ss prints one
+// entry per (process, fd), e.g. after a pre-fork server forks its workers:
+// users:(("nginx",pid=1201,fd=6),("nginx",pid=1200,fd=6))
+func parseUsersAll(users string) []model.SocketHolder {
+	var out []model.SocketHolder
+	for _, m := range reUsersAll.FindAllStringSubmatch(users, -1) {
+		out = append(out, model.SocketHolder{
+			PID:      int32(parseInt(m[2])),
+			ProcName: m[1],
+			FD:       parseInt(m[3]),
+		})
+	}
+	return out
+}
+
+// ss -ti prints tcp_info on an indented line after each socket:
+//
+//	cubic wscale:7,7 rto:204 rtt:0.05/0.025 mss:32768 cwnd:10 bytes_sent:512
+//	bytes_acked:513 bytes_received:80 lastsnd:120 lastrcv:96 retrans:0/2 unacked:1
+func parseSSInfo(line string) (*model.TCPInfo, bool) {
+	info := &model.TCPInfo{}
+	found := false
+	lastSnd, lastRcv := int64(-1), int64(-1)
+	var acked uint64
+	for _, tok := range strings.Fields(line) {
+		k, v, ok := strings.Cut(tok, ":")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "rtt":
+			rtt, rttVar, _ := strings.Cut(v, "/")
+			info.RTTMs, _ = strconv.ParseFloat(rtt, 64)
+			info.RTTVarMs, _ = strconv.ParseFloat(rttVar, 64)
+		case "retrans":
+			cur, total, _ := strings.Cut(v, "/")
+			info.Retransmits = parseInt(cur)
+			info.TotalRetrans = parseInt(total)
+		case "unacked":
+			info.Unacked = parseInt(v)
+		case "cwnd":
+			// listeners report cwnd alone; it does not make a measurement
+			info.Cwnd = parseInt(v)
+			continue
+		case "bytes_sent":
+			info.BytesSent, _ = strconv.ParseUint(v, 10, 64)
+		case "bytes_acked":
+			acked, _ = strconv.ParseUint(v, 10, 64)
+		case "bytes_received":
+			info.BytesReceived, _ = strconv.ParseUint(v, 10, 64)
+		case "lastsnd":
+			lastSnd = int64(parseInt(v))
+		case "lastrcv":
+			lastRcv = int64(parseInt(v))
+		default:
+			continue
+		}
+		found = true
+	}
+	if info.BytesSent == 0 {
+		info.BytesSent = acked
+	}
+	switch {
+	case lastSnd >= 0 && lastRcv >= 0:
+		info.LastActivityMs = min(lastSnd, lastRcv)
+	case lastSnd >= 0:
+		info.LastActivityMs = lastSnd
+	case lastRcv >= 0:
+		info.LastActivityMs = lastRcv
+	}
+	return info, found
+}
@@ -1,9 +1,12 @@
 package sockets
 
 import (
+	"encoding/binary"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/pratik-anurag/portik/internal/model"
 )
 
 func TestParseSSFixture(t *testing.T) {
@@ -48,3 +51,291 @@ func TestParseLsofFixture(t *testing.T) {
 		t.Fatalf("expected parsed connection endpoints, got lip=%q lp=%d rip=%q rp=%d", lip, lp, rip, rp)
 	}
 }
+
+func TestParseProcNetFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/proc_net_tcp.txt")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if _, ok := parseProcNetLine(lines[0]); ok {
+		t.Fatalf("expected header line to be skipped")
+	}
+	l, ok := parseProcNetLine(lines[1])
+	if !ok {
+		t.Fatalf("expected parse ok for /proc/net/tcp line")
+	}
+	if l.laddr != "127.0.0.1" || l.lport != 5432 || l.state != "LISTEN" || l.inode != 41001 || l.uid != 999 {
+		t.Fatalf("unexpected listen parse: %+v", l)
+	}
+	c, ok := parseProcNetLine(lines[3])
+	if !ok {
+		t.Fatalf("expected parse ok for established line")
+	}
+	if c.raddr != "127.0.0.1" || c.rport != 5432 || c.lport != 54321 || c.state != "ESTAB" {
+		t.Fatalf("unexpected conn parse: %+v", c)
+	}
+	tw, _ := parseProcNetLine(lines[4])
+	if tw.state != "TIME-WAIT" || tw.inode != 0 {
+		t.Fatalf("unexpected time-wait parse: %+v", tw)
+	}
+}
+
+func TestParseProcNet6Fixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/proc_net_tcp6.txt")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []struct {
+		ip   string
+		port int
+	}{
+		{"::1", 8080},
+		{"::", 3000},
+		{"::ffff:127.0.0.1", 3000},
+	}
+	for i, w := range want {
+		l, ok := parseProcNetLine(lines[i+1])
+		if !ok {
+			t.Fatalf("line %d: expected parse ok", i+1)
+		}
+		if l.laddr != w.ip || l.lport != w.port {
+			t.Fatalf("line %d: expected %s:%d, got %s:%d", i+1, w.ip, w.port, l.laddr, l.lport)
+		}
+	}
+	if l, _ := parseProcNetLine(lines[1]); l.rxQueue != 5 {
+		t.Fatalf("expected rx_queue=5, got %d", l.rxQueue)
+	}
+}
+
+func TestParseDiagMsg(t *testing.T) {
+	ne := binary.NativeEndian
+	msg := make([]byte, sizeofInetDiagMsg)
+	msg[0] = 2    // AF_INET
+	msg[1] = 0x01 // ESTABLISHED
+	binary.BigEndian.PutUint16(msg[4:6], 54321)
+	binary.BigEndian.PutUint16(msg[6:8], 5432)
+	copy(msg[8:12], []byte{127, 0, 0, 1})
+	copy(msg[24:28], []byte{10, 0, 0, 7})
+	ne.PutUint32(msg[56:60], 3)    // rqueue
+	ne.PutUint32(msg[60:64], 7)    // wqueue
+	ne.PutUint32(msg[64:68], 1000) // uid
+	ne.PutUint32(msg[68:72], 424242)
+
+	info := make([]byte, 232)
+	ne.PutUint32(info[68:72], 1500) // rtt
+	ne.PutUint32(info[72:76], 250)  // rttvar
+	ne.PutUint32(info[100:104], 9)  // total_retrans
+	ne.PutUint64(info[200:208], 4096)
+	attr := make([]byte, 4+len(info))
+	ne.PutUint16(attr[0:2], uint16(len(attr)))
+	ne.PutUint16(attr[2:4], inetDiagInfo)
+	copy(attr[4:], info)
+	msg = append(msg, attr...)
+
+	s, ok := parseDiagMsg(msg)
+	if !ok {
+		t.Fatalf("expected parse ok for inet_diag_msg")
+	}
+	if s.laddr != "127.0.0.1" || s.lport != 54321 || s.raddr != "10.0.0.7" || s.rport != 5432 || s.state != "ESTAB" {
+		t.Fatalf("unexpected socket parse: %+v", s)
+	}
+	if s.rxQueue != 3 || s.txQueue != 7 || s.uid != 1000 || s.inode != 424242 {
+		t.Fatalf("unexpected queue/uid/inode parse: %+v", s)
+	}
+	if s.tcp == nil || s.tcp.rtt != 1500 || s.tcp.rttVar != 250 || s.tcp.totalRetrans != 9 || s.tcp.bytesSent != 4096 {
+		t.Fatalf("unexpected tcp_info parse: %+v", s.tcp)
+	}
+}
+
+func TestParseUsersAll(t *testing.T) {
+	line := `LISTEN 0 511 0.0.0.0:80 0.0.0.0:* users:(("nginx",pid=1202,fd=6),("nginx",pid=1201,fd=6),("nginx",pid=1200,fd=6))`
+	parsed, ok := parseSSLine(line)
+	if !ok {
+		t.Fatalf("expected parse ok for ss line")
+	}
+	if parsed.pid != 1202 || len(parsed.users) != 3 {
+		t.Fatalf("expected 3 holders with first pid 1202, got pid=%d users=%+v", parsed.pid, parsed.users)
+	}
+	if parsed.users[2].PID != 1200 || parsed.users[2].ProcName != "nginx" || parsed.users[2].FD != 6 {
+		t.Fatalf("unexpected holder parse: %+v", parsed.users[2])
+	}
+}
+
+func TestMergeLsofListeners(t *testing.T) {
+	lines := []string{
+		"nginx 1200 root 6u IPv4 0xabc1 0t0 TCP *:80 (LISTEN)",
+		"nginx 1201 www 6u IPv4 0xabc1 0t0 TCP *:80 (LISTEN)",
+		"nginx 1201 www 7u IPv4 0xabc1 0t0 TCP *:80 (LISTEN)",
+		"other 1300 me 4u IPv4 0xdef2 0t0 TCP 127.0.0.1:80 (LISTEN)",
+	}
+	var rows []lsofListener
+	for _, line := range lines {
+		p, ok := parseLsofLine(line)
+		if !ok {
+			t.Fatalf("expected parse ok for %q", line)
+		}
+		ip, port := parseLsofAddr(p.addr)
+		rows = append(rows, lsofListener{device: p.device, l: model.Listener{
+			LocalIP: ip, LocalPort: port, State: p.state, PID: int32(p.pid), ProcName: p.cmd,
+			Holders: []model.SocketHolder{{PID: int32(p.pid), ProcName: p.cmd, FD: p.fd}},
+		}})
+	}
+	merged := mergeLsofListeners(rows)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 listeners after merge, got %d", len(merged))
+	}
+	if merged[0].PID != 1200 || len(merged[0].Holders) != 2 || merged[0].Holders[1].FD != 6 {
+		t.Fatalf("unexpected merged listener: %+v", merged[0])
+	}
+}
+
+func TestParseSSQueues(t *testing.T) {
+	data, err := os.ReadFile("testdata/ss.txt")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	parsed, ok := parseSSLine(lines[0])
+	if !ok {
+		t.Fatalf("expected parse ok for ss line")
+	}
+	if parsed.state != "LISTEN" || parsed.recvQ != 0 || parsed.sendQ != 4096 {
+		t.Fatalf("expected LISTEN with backlog 4096, got %+v", parsed)
+	}
+}
+
+func TestParseSSInfo(t *testing.T) {
+	line := "\t cubic wscale:7,7 rto:204 rtt:1.5/0.25 mss:1448 cwnd:10 bytes_sent:900 bytes_acked:800 bytes_received:80 lastsnd:120 lastrcv:96 retrans:1/4 unacked:2"
+	info, ok := parseSSInfo(line)
+	if !ok {
+		t.Fatalf("expected parse ok for ss -i line")
+	}
+	if info.RTTMs != 1.5 || info.RTTVarMs != 0.25 || info.Retransmits != 1 || info.TotalRetrans != 4 || info.Unacked != 2 {
+		t.Fatalf("unexpected rtt/retrans parse: %+v", info)
+	}
+	if info.BytesSent != 900 || info.BytesReceived != 80 || info.LastActivityMs != 96 || info.Cwnd != 10 {
+		t.Fatalf("unexpected byte/activity parse: %+v", info)
+	}
+	if _, ok := parseSSInfo("\t cubic cwnd:10"); ok {
+		t.Fatalf("listener info line should not count as a measurement")
+	}
+}
+
+func TestTCPInfoToModel(t *testing.T) {
+	ti := &tcpInfo{rtt: 1500, rttVar: 250, totalRetrans: 3, bytesAcked: 700, lastDataSentMs: 40, lastDataRecvMs: 900}
+	m := ti.toModel()
+	if m.RTTMs != 1.5 || m.RTTVarMs != 0.25 || m.TotalRetrans != 3 {
+		t.Fatalf("unexpected conversion: %+v", m)
+	}
+	if m.BytesSent != 700 || m.LastActivityMs != 40 {
+		t.Fatalf("expected bytes_acked fallback and min activity, got %+v", m)
+	}
+}
+
+func TestParseProcNetUnixFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/proc_net_unix.txt")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if _, ok := parseProcNetUnixLine(lines[0]); ok {
+		t.Fatalf("expected header line to be skipped")
+	}
+	l, ok := parseProcNetUnixLine(lines[1])
+	if !ok || l.path != "/run/postgresql/.s.PGSQL.5432" || l.state != "LISTEN" || l.typ != "stream" || l.inode != 22020 {
+		t.Fatalf("unexpected listen parse: %+v", l)
+	}
+	c, _ := parseProcNetUnixLine(lines[3])
+	if c.path != "" || c.state != "ESTAB" || c.inode != 22309 {
+		t.Fatalf("unexpected client parse: %+v", c)
+	}
+	d, _ := parseProcNetUnixLine(lines[4])
+	if d.typ != "dgram" || d.state != "UNCONN" {
+		t.Fatalf("unexpected dgram parse: %+v", d)
+	}
+	a, _ := parseProcNetUnixLine(lines[5])
+	if a.path != "@/tmp/.X11-unix/X0" {
+		t.Fatalf("unexpected abstract name: %q", a.path)
+	}
+}
+
+func TestParseSSUnixFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/ss_unix.txt")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	s, ok := parseSSUnixLine(lines[1])
+	if !ok || s.path != "/run/postgresql/.s.PGSQL.5432" || s.inode != 22310 || s.peer != 22309 || s.state != "ESTAB" {
+		t.Fatalf("unexpected ss -x parse: %+v", s)
+	}
+	c, ok := parseSSUnixLine(lines[2])
+	if !ok || c.path != "" || c.peer != 22310 {
+		t.Fatalf("unexpected client parse: %+v", c)
+	}
+	l, ok := parseSSUnixLine(lines[0])
+	if !ok || l.state != "LISTEN" || l.txQueue != 244 {
+		t.Fatalf("unexpected listener parse: %+v", l)
+	}
+	if users := parseUsersAll(lines[0]); len(users) != 1 || users[0].PID != 812 {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+}
+
+func TestParseUnixDiagMsg(t *testing.T) {
+	ne := binary.NativeEndian
+	msg := make([]byte, sizeofUnixDiagMsg)
+	msg[0] = 1    // AF_UNIX
+	msg[1] = 1    // SOCK_STREAM
+	msg[2] = 0x0A // TCP_LISTEN
+	ne.PutUint32(msg[4:8], 22020)
+
+	attr := func(typ uint16, payload []byte) []byte {
+		a := make([]byte, 4+len(payload))
+		ne.PutUint16(a[0:2], uint16(len(a)))
+		ne.PutUint16(a[2:4], typ)
+		copy(a[4:], payload)
+		for len(a)%4 != 0 {
+			a = append(a, 0)
+		}
+		return a
+	}
+	msg = append(msg, attr(unixDiagName, []byte("/run/app.sock"))...)
+	peer := make([]byte, 4)
+	ne.PutUint32(peer, 0)
+	msg = append(msg, attr(unixDiagPeer, peer)...)
+	rq := make([]byte, 8)
+	ne.PutUint32(rq[0:4], 2)
+	ne.PutUint32(rq[4:8], 128)
+	msg = append(msg, attr(unixDiagRQLen, rq)...)
+
+	s, ok := parseUnixDiagMsg(msg)
+	if !ok {
+		t.Fatalf("expected parse ok for unix_diag_msg")
+	}
+	if s.path != "/run/app.sock" || s.state != "LISTEN" || s.typ != "stream" || s.inode != 22020 || s.rxQueue != 2 || s.txQueue != 128 {
+		t.Fatalf("unexpected unix_diag parse: %+v", s)
+	}
+	if got := unixName([]byte("\x00portik")); got != "@portik" {
+		t.Fatalf("expected abstract name @portik, got %q", got)
+	}
+}
+
+func TestInspectUnix(t *testing.T) {
+	path := "/run/postgresql/.s.PGSQL.5432"
+	socks := []model.UnixSocket{
+		{Path: path, Type: "stream", State: "LISTEN", Inode: 1, PID: 812, ProcName: "postgres", SendQ: 244},
+		{Path: path, Type: "stream", State: "ESTAB", Inode: 2, PeerInode: 3, PID: 4410, ProcName: "postgres"},
+		{Type: "stream", State: "ESTAB", Inode: 3, PeerInode: 2, PID: 4409, ProcName: "psql"},
+		{Path: "/run/other.sock", Type: "stream", State: "LISTEN", Inode: 4, PID: 1},
+	}
+	ls, cs := inspectUnix(socks, path, true)
+	if len(ls) != 1 || ls[0].PID != 812 || ls[0].Family != "unix" || ls[0].SendQ != 244 {
+		t.Fatalf("unexpected listeners: %+v", ls)
+	}
+	if len(cs) != 1 || cs[0].PID != 4409 || cs[0].ProcName != "psql" {
+		t.Fatalf("expected one connection from psql, got %+v", cs)
+	}
+}
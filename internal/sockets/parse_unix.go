@@ -0,0 +1,155 @@
+package sockets
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// /proc/net/unix
+//
+//	Num       RefCount Protocol Flags    Type St Inode Path
+//	0000000000000000: 00000002 00000000 00010000 0001 01 22020 /run/docker.sock
+//
+// Flags 0x10000 (__SO_ACCEPTCON) marks a listening socket. The file does not
+// report peers.
+func parseProcNetUnixLine(line string) (rawUnix, bool) {
+	f := strings.Fields(line)
+	if len(f) < 7 || !strings.HasSuffix(f[0], ":") {
+		return rawUnix{}, false
+	}
+	flags, err := strconv.ParseUint(f[3], 16, 32)
+	if err != nil {
+		return rawUnix{}, false
+	}
+	inode, err := strconv.ParseUint(f[6], 10, 64)
+	if err != nil {
+		return rawUnix{}, false
+	}
+	state := "UNCONN"
+	switch {
+	case flags&0x10000 != 0:
+		state = "LISTEN"
+	case parseHexInt(f[5]) == 3: // SS_CONNECTED
+		state = "ESTAB"
+	case parseHexInt(f[5]) == 2: // SS_CONNECTING
+		state = "SYN-SENT"
+	}
+	return rawUnix{
+		path:  strings.Join(f[7:], " "),
+		typ:   unixTypeName(parseHexInt(f[4])),
+		state: state,
+		inode: inode,
+	}, true
+}
+
+// NETLINK_SOCK_DIAG for AF_UNIX (linux/unix_diag.h).
+const (
+	sizeofUnixDiagReq = 24
+	sizeofUnixDiagMsg = 16
+
+	unixDiagName  = 0
+	unixDiagPeer  = 2
+	unixDiagRQLen = 4
+
+	udiagShowName  = 0x01
+	udiagShowPeer  = 0x04
+	udiagShowRQLen = 0x10
+)
+
+// unix_diag_msg:
+//
+//	u8 family, type, state, pad
+//	u32 ino, cookie[2]
+//	followed by rtattr-encoded UNIX_DIAG_* attributes
+func parseUnixDiagMsg(b []byte) (rawUnix, bool) {
+	if len(b) < sizeofUnixDiagMsg || b[0] != 1 { // AF_UNIX
+		return rawUnix{}, false
+	}
+	ne := binary.NativeEndian
+	s := rawUnix{
+		typ:   unixTypeName(int(b[1])),
+		state: tcpStateName(int(b[2])),
+		inode: uint64(ne.Uint32(b[4:8])),
+	}
+	attrs := b[sizeofUnixDiagMsg:]
+	for len(attrs) >= 4 {
+		alen := int(ne.Uint16(attrs[0:2]))
+		if alen < 4 || alen > len(attrs) {
+			break
+		}
+		payload := attrs[4:alen]
+		switch ne.Uint16(attrs[2:4]) {
+		case unixDiagName:
+			s.path = unixName(payload)
+		case unixDiagPeer:
+			if len(payload) >= 4 {
+				s.peer = uint64(ne.Uint32(payload[0:4]))
+			}
+		case unixDiagRQLen:
+			if len(payload) >= 8 {
+				s.rxQueue = int(ne.Uint32(payload[0:4]))
+				s.txQueue = int(ne.Uint32(payload[4:8]))
+			}
+		}
+		next := (alen + 3) &^ 3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	return s, true
+}
+
+// unixName formats a sun_path the way ss and /proc/net/unix do: abstract
+// names (leading NUL) are shown with a leading "@".
+func unixName(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	if b[0] == 0 {
+		return "@" + string(b[1:])
+	}
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// ss -H -xanp
+//
+//	u_str LISTEN 0 4096 /run/docker.sock 22020 * 0 users:(("dockerd",pid=811,fd=6))
+//	u_str ESTAB  0 0    * 29109 * 29110 users:(("bash",pid=14169,fd=10))
+func parseSSUnixLine(line string) (rawUnix, bool) {
+	f := strings.Fields(line)
+	if len(f) < 8 || !strings.HasPrefix(f[0], "u_") {
+		return rawUnix{}, false
+	}
+	inode, err := strconv.ParseUint(f[5], 10, 64)
+	if err != nil {
+		return rawUnix{}, false
+	}
+	peer, _ := strconv.ParseUint(f[7], 10, 64)
+	typ := "unknown"
+	switch f[0] {
+	case "u_str":
+		typ = "stream"
+	case "u_dgr":
+		typ = "dgram"
+	case "u_seq":
+		typ = "seqpacket"
+	}
+	path := f[4]
+	if path == "*" {
+		path = ""
+	}
+	return rawUnix{
+		path:    path,
+		typ:     typ,
+		state:   f[1],
+		inode:   inode,
+		peer:    peer,
+		rxQueue: parseInt(f[2]),
+		txQueue: parseInt(f[3]),
+	}, true
+}
@@ -0,0 +1,136 @@
+//go:build linux
+
+package sockets
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// procfs backend: reads /proc/net/{tcp,tcp6,udp,udp6}. Socket inodes are
+// mapped to processes by walking /proc/<pid>/fd (shared with netlink).
+
+const procRoot = "/proc"
+
+func procfsAvailable() bool {
+	_, err := os.Stat(procRoot + "/net/tcp")
+	return err == nil
+}
+
+// procNetDir follows the calling thread's network namespace, which differs
+// from the process's while netns.Do is active; /proc/net follows the main
+// thread. /proc/thread-self needs Linux 3.17.
+func procNetDir() string {
+	if _, err := os.Stat(procRoot + "/thread-self/net"); err == nil {
+		return procRoot + "/thread-self/net"
+	}
+	return procRoot + "/net"
+}
+
+func readProcNet(proto string) ([]rawSocket, error) {
+	var out []rawSocket
+	var firstErr error
+	read := 0
+	dir := procNetDir()
+	for _, name := range []string{proto, proto + "6"} {
+		f, err := os.Open(dir + "/" + name)
+		if err != nil {
+			// tcp6/udp6 are absent when IPv6 is disabled
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		read++
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			if l, ok := parseProcNetLine(sc.Text()); ok {
+				out = append(out, l)
+			}
+		}
+		f.Close()
+	}
+	if read == 0 {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+type inodeOwner struct {
+	pid  int
+	fd   int
+	comm string
+}
+
+// inodeOwners walks /proc/<pid>/fd and returns every process holding each
+// wanted socket inode, ordered by pid. Processes we cannot inspect (other
+// users without privileges) are skipped.
+func inodeOwners(want map[uint64]bool) map[uint64][]inodeOwner {
+	out := map[uint64][]inodeOwner{}
+	if len(want) == 0 {
+		return out
+	}
+	pids := listPIDs()
+	for _, pid := range pids {
+		dir := procRoot + "/" + strconv.Itoa(pid) + "/fd"
+		fds, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		var comm string
+		seen := map[uint64]bool{}
+		for _, fd := range fds {
+			link, err := os.Readlink(dir + "/" + fd.Name())
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil || !want[inode] || seen[inode] {
+				continue
+			}
+			seen[inode] = true
+			if comm == "" {
+				comm = readComm(pid)
+			}
+			out[inode] = append(out[inode], inodeOwner{pid: pid, fd: parseInt(fd.Name()), comm: comm})
+		}
+	}
+	return out
+}
+
+func listPIDs() []int {
+	ents, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, e := range ents {
+		if pid, err := strconv.Atoi(e.Name()); err == nil && pid > 0 {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+func readComm(pid int) string {
+	b, err := os.ReadFile(procRoot + "/" + strconv.Itoa(pid) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func readCwd(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	cwd, err := os.Readlink(procRoot + "/" + strconv.Itoa(pid) + "/cwd")
+	if err != nil {
+		return ""
+	}
+	return cwd
+}
@@ -0,0 +1,112 @@
+package sockets
+
+import "github.com/pratik-anurag/portik/internal/model"
+
+// rawSocket is one row of the kernel socket table as read from /proc/net/*
+// or NETLINK_SOCK_DIAG, before it is mapped to a process.
+type rawSocket struct {
+	laddr string
+	lport int
+	raddr string
+	rport int
+	state string
+	// LISTEN: txQueue is the configured backlog (sock_diag and ss; 0 from
+	// /proc/net, which does not report it) and rxQueue the pending accept
+	// queue. Otherwise they are Send-Q and Recv-Q.
+	txQueue int
+	rxQueue int
+	uid     int
+	inode   uint64
+	tcp     *tcpInfo // netlink only
+}
+
+// rawUnix is one AF_UNIX socket from /proc/net/unix, UNIX_DIAG or ss -x.
+type rawUnix struct {
+	path    string // "" when unnamed; abstract names start with "@"
+	typ     string // stream|dgram|seqpacket
+	state   string
+	inode   uint64
+	peer    uint64 // netlink and ss only
+	rxQueue int
+	txQueue int
+}
+
+var unixTypes = map[int]string{
+	1: "stream",
+	2: "dgram",
+	5: "seqpacket",
+}
+
+func unixTypeName(t int) string {
+	if s, ok := unixTypes[t]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// tcpInfo is the subset of the kernel's struct tcp_info portik reports.
+// Times are in microseconds unless noted.
+type tcpInfo struct {
+	retransmits    uint8
+	rto            uint32
+	unacked        uint32
+	lastDataSentMs uint32
+	lastDataRecvMs uint32
+	rtt            uint32
+	rttVar         uint32
+	sndCwnd        uint32
+	totalRetrans   uint32
+	bytesAcked     uint64
+	bytesReceived  uint64
+	bytesSent      uint64
+}
+
+// tcpStates maps the kernel's TCP_* state numbers to the names ss prints, so
+// every Linux backend reports identical State values.
+var tcpStates = map[int]string{
+	0x01: "ESTAB",
+	0x02: "SYN-SENT",
+	0x03: "SYN-RECV",
+	0x04: "FIN-WAIT-1",
+	0x05: "FIN-WAIT-2",
+	0x06: "TIME-WAIT",
+	0x07: "UNCONN",
+	0x08: "CLOSE-WAIT",
+	0x09: "LAST-ACK",
+	0x0A: "LISTEN",
+	0x0B: "CLOSING",
+	0x0C: "NEW-SYN-RECV",
+}
+
+func tcpStateName(st int) string {
+	if s, ok := tcpStates[st]; ok {
+		return s
+	}
+	return "UNKNOWN"
+}
+
+func (t *tcpInfo) toModel() *model.TCPInfo {
+	if t == nil {
+		return nil
+	}
+	sent := t.bytesSent
+	if sent == 0 {
+		// bytes_sent is newer than bytes_acked (kernel 4.19)
+		sent = t.bytesAcked
+	}
+	last := t.lastDataSentMs
+	if t.lastDataRecvMs < last {
+		last = t.lastDataRecvMs
+	}
+	return &model.TCPInfo{
+		RTTMs:          float64(t.rtt) / 1000,
+		RTTVarMs:       float64(t.rttVar) / 1000,
+		Retransmits:    int(t.retransmits),
+		TotalRetrans:   int(t.totalRetrans),
+		Unacked:        int(t.unacked),
+		Cwnd:           int(t.sndCwnd),
+		BytesSent:      sent,
+		BytesReceived:  t.bytesReceived,
+		LastActivityMs: int64(last),
+	}
+}
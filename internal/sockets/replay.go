@@ -0,0 +1,56 @@
+package sockets
+
+import (
+	"errors"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+// Tables is the socket state of a host, as stored in a snapshot bundle.
+// Entries are unenriched: process details come from the bundle's process
+// table on replay, like they come from /proc on a live system.
+type Tables struct {
+	Listeners   map[string][]model.Listener `json:"listeners"`   // by proto: tcp, udp
+	Connections map[string][]model.Conn     `json:"connections"` // by proto: tcp
+	Unix        []model.UnixSocket          `json:"unix,omitempty"`
+}
+
+// replayed, when set, replaces the backend (see Replay).
+var replayed *Tables
+
+// Capture lists every socket with the current backend. Unix sockets are
+// left out where the platform has no backend for them.
+func Capture() (Tables, error) {
+	t := Tables{
+		Listeners:   map[string][]model.Listener{},
+		Connections: map[string][]model.Conn{},
+	}
+	for _, proto := range []string{"tcp", "udp"} {
+		ls, err := ListListeners(proto)
+		if err != nil {
+			return Tables{}, err
+		}
+		t.Listeners[proto] = ls
+	}
+	cs, err := ListConnections("tcp")
+	if err != nil {
+		return Tables{}, err
+	}
+	t.Connections["tcp"] = cs
+	socks, err := ListUnix()
+	if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return Tables{}, err
+	}
+	t.Unix = socks
+	return t, nil
+}
+
+// Replay makes every lookup in this package answer from t instead of the
+// live system; SetBackend no longer matters.
+func Replay(t Tables) {
+	replayed = &t
+}
+
+func replayInspect(port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn) {
+	return filterPort(replayed.Listeners[proto], replayed.Connections[proto], port, includeConnections)
+}
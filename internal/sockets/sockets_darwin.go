@@ -4,6 +4,8 @@ package sockets
 
 import "github.com/pratik-anurag/portik/internal/model"
 
+var backends = []string{BackendAuto, BackendLsof}
+
 func inspect(port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
 	return inspectDarwin(port, proto, includeConnections)
 }
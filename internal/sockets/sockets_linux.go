@@ -4,6 +4,16 @@ package sockets
 
 import "github.com/pratik-anurag/portik/internal/model"
 
+var backends = []string{BackendAuto, BackendNetlink, BackendProcfs, BackendSS}
+
 func inspect(port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
-	return inspectLinux(port, proto, includeConnections)
+	socks, ok, err := kernelSockets(proto)
+	if !ok {
+		return inspectLinux(port, proto, includeConnections)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	ls, cs := inspectKernel(socks, port, proto, includeConnections)
+	return ls, cs, nil
 }
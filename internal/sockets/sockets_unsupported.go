@@ -8,6 +8,8 @@ import (
 	"github.com/pratik-anurag/portik/internal/model"
 )
 
+var backends = []string{BackendAuto}
+
 func inspect(port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
 	return nil, nil, fmt.Errorf("unsupported OS for socket inspection")
 }
@@ -0,0 +1,79 @@
+package sockets
+
+import "github.com/pratik-anurag/portik/internal/model"
+
+// Source is where socket tables come from. The package-level functions read
+// the live system through the selected backend (or a replayed bundle);
+// inspect and graph accept any Source so callers can plug in their own.
+type Source interface {
+	ListListeners(proto string) ([]model.Listener, error)
+	ListConnections(proto string) ([]model.Conn, error)
+	ListUnix() ([]model.UnixSocket, error)
+}
+
+// portInspector is implemented by sources that can look up a single port
+// more cheaply than listing every socket.
+type portInspector interface {
+	Inspect(port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn, error)
+}
+
+type live struct{}
+
+func (live) ListListeners(proto string) ([]model.Listener, error) { return ListListeners(proto) }
+func (live) ListConnections(proto string) ([]model.Conn, error)   { return ListConnections(proto) }
+func (live) ListUnix() ([]model.UnixSocket, error)                { return ListUnix() }
+func (live) Inspect(port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
+	return Inspect(port, proto, includeConnections)
+}
+
+// Live reads the running system.
+var Live Source = live{}
+
+// InspectFrom is Inspect against src.
+func InspectFrom(src Source, port int, proto string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
+	if pi, ok := src.(portInspector); ok {
+		return pi.Inspect(port, proto, includeConnections)
+	}
+	ls, err := src.ListListeners(proto)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cs []model.Conn
+	if includeConnections && proto == "tcp" {
+		if cs, err = src.ListConnections(proto); err != nil {
+			return nil, nil, err
+		}
+	}
+	ls, cs = filterPort(ls, cs, port, includeConnections)
+	return ls, cs, nil
+}
+
+// InspectUnixFrom is InspectUnix against src.
+func InspectUnixFrom(src Source, path string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
+	socks, err := src.ListUnix()
+	if err != nil {
+		return nil, nil, err
+	}
+	ls, cs := inspectUnix(socks, path, includeConnections)
+	return ls, cs, nil
+}
+
+// filterPort keeps the listeners bound to port and the connections with
+// port at either end, like the live backends do.
+func filterPort(listeners []model.Listener, conns []model.Conn, port int, includeConnections bool) ([]model.Listener, []model.Conn) {
+	var ls []model.Listener
+	for _, l := range listeners {
+		if l.LocalPort == port {
+			ls = append(ls, l)
+		}
+	}
+	var cs []model.Conn
+	if includeConnections {
+		for _, c := range conns {
+			if c.LocalPort == port || c.RemotePort == port {
+				cs = append(cs, c)
+			}
+		}
+	}
+	return ls, cs
+}
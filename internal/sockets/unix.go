@@ -0,0 +1,61 @@
+package sockets
+
+import "github.com/pratik-anurag/portik/internal/model"
+
+// ListUnix returns every AF_UNIX socket with the processes holding it. On
+// platforms without a Unix socket backend the error wraps
+// errors.ErrUnsupported.
+func ListUnix() ([]model.UnixSocket, error) {
+	if replayed != nil {
+		return append([]model.UnixSocket(nil), replayed.Unix...), nil
+	}
+	return listUnix()
+}
+
+// InspectUnix returns the sockets bound to path. With includeConnections it
+// also returns one Conn per accepted connection, attributed to the client
+// process at the other end when the backend reports peers.
+func InspectUnix(path string, includeConnections bool) ([]model.Listener, []model.Conn, error) {
+	return InspectUnixFrom(Live, path, includeConnections)
+}
+
+func inspectUnix(socks []model.UnixSocket, path string, includeConnections bool) ([]model.Listener, []model.Conn) {
+	byInode := make(map[uint64]model.UnixSocket, len(socks))
+	for _, s := range socks {
+		byInode[s.Inode] = s
+	}
+	var listeners []model.Listener
+	var conns []model.Conn
+	for _, s := range socks {
+		if s.Path != path {
+			continue
+		}
+		switch {
+		case s.State == "LISTEN" || (s.Type == "dgram" && s.State == "UNCONN"):
+			listeners = append(listeners, model.Listener{
+				Path:     s.Path,
+				Family:   "unix",
+				State:    s.State,
+				PID:      s.PID,
+				ProcName: s.ProcName,
+				RecvQ:    s.RecvQ,
+				SendQ:    s.SendQ,
+				Holders:  s.Holders,
+			})
+		case includeConnections && s.State == "ESTAB":
+			c := model.Conn{
+				Path:   s.Path,
+				Family: "unix",
+				State:  s.State,
+				RecvQ:  s.RecvQ,
+				SendQ:  s.SendQ,
+			}
+			if peer, ok := byInode[s.PeerInode]; ok && s.PeerInode != 0 {
+				c.PID = peer.PID
+				c.ProcName = peer.ProcName
+			}
+			conns = append(conns, c)
+		}
+	}
+	return listeners, conns
+}
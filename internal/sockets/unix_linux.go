@@ -0,0 +1,109 @@
+//go:build linux
+
+package sockets
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func listUnix() ([]model.UnixSocket, error) {
+	socks, owners, err := unixTable()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.UnixSocket, 0, len(socks))
+	for _, s := range socks {
+		u := model.UnixSocket{
+			Path:      s.path,
+			Type:      s.typ,
+			State:     s.state,
+			Inode:     s.inode,
+			PeerInode: s.peer,
+			RecvQ:     s.rxQueue,
+			SendQ:     s.txQueue,
+		}
+		for _, o := range owners[s.inode] {
+			u.Holders = append(u.Holders, model.SocketHolder{PID: int32(o.pid), ProcName: o.comm, FD: o.fd})
+		}
+		if len(u.Holders) > 0 {
+			u.PID = u.Holders[0].PID
+			u.ProcName = u.Holders[0].ProcName
+		}
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+// unixTable follows the same backend selection as kernelSockets. Only
+// netlink and ss report peer inodes.
+func unixTable() ([]rawUnix, map[uint64][]inodeOwner, error) {
+	var socks []rawUnix
+	var err error
+	switch backend {
+	case BackendSS:
+		return ssUnix()
+	case BackendNetlink:
+		socks, err = dumpUnixNetlink()
+	case BackendProcfs:
+		socks, err = readProcNetUnix()
+	default:
+		if socks, err = dumpUnixNetlink(); err != nil {
+			if !procfsAvailable() {
+				return ssUnix()
+			}
+			socks, err = readProcNetUnix()
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	want := map[uint64]bool{}
+	for _, s := range socks {
+		if s.inode != 0 {
+			want[s.inode] = true
+		}
+	}
+	return socks, inodeOwners(want), nil
+}
+
+func readProcNetUnix() ([]rawUnix, error) {
+	f, err := os.Open(procNetDir() + "/unix")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []rawUnix
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if s, ok := parseProcNetUnixLine(sc.Text()); ok {
+			out = append(out, s)
+		}
+	}
+	return out, sc.Err()
+}
+
+func ssUnix() ([]rawUnix, map[uint64][]inodeOwner, error) {
+	out, err := exec.Command("ss", "-H", "-xanp").Output()
+	if err != nil {
+		return nil, nil, err
+	}
+	var socks []rawUnix
+	owners := map[uint64][]inodeOwner{}
+	for _, line := range splitLines(out) {
+		s, ok := parseSSUnixLine(line)
+		if !ok {
+			continue
+		}
+		socks = append(socks, s)
+		for _, h := range parseUsersAll(line) {
+			owners[s.inode] = append(owners[s.inode], inodeOwner{pid: int(h.PID), fd: h.FD, comm: h.ProcName})
+		}
+		sort.SliceStable(owners[s.inode], func(i, j int) bool { return owners[s.inode][i].pid < owners[s.inode][j].pid })
+	}
+	return socks, owners, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package sockets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pratik-anurag/portik/internal/model"
+)
+
+func listUnix() ([]model.UnixSocket, error) {
+	return nil, fmt.Errorf("unix sockets: %w on this OS", errors.ErrUnsupported)
+}
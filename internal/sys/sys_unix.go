@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/proc"
 )
 
 type ActionResult struct {
@@ -28,7 +29,8 @@ func EnsureSameUser(pid int32) error {
 	if err != nil {
 		return err
 	}
-	owner := psField(pid, "user=")
+	info, _ := proc.Lookup(pid)
+	owner := info.User
 	if owner == "" {
 		return errors.New("cannot determine process owner")
 	}
@@ -46,6 +48,8 @@ func TerminateProcess(pid int32, timeout time.Duration) ActionResult {
 	if err != nil {
 		return ActionResult{ExitCode: 1, Summary: "Failed to find process", Details: err.Error()}
 	}
+	// the pid exits (or may be reused) now; later lookups must not see it cached
+	defer proc.Forget(pid)
 	_ = p.Signal(syscall.SIGTERM)
 
 	deadline := time.Now().Add(timeout)
@@ -103,19 +107,24 @@ func RestartDockerContainer(containerID string, timeout time.Duration) ActionRes
 	return ActionResult{ExitCode: 0, Summary: "Container restarted", Details: string(bytes.TrimSpace(out))}
 }
 
+// RunCommand runs argv and reports its output, e.g. to cancel one ssh
+// forward through its ControlMaster.
+func RunCommand(summary string, argv []string) ActionResult {
+	if len(argv) == 0 {
+		return ActionResult{ExitCode: 1, Summary: "Nothing to run"}
+	}
+	out, err := exec.Command(argv[0], argv[1:]...).CombinedOutput()
+	if err != nil {
+		return ActionResult{ExitCode: 1, Summary: summary + " failed", Details: strings.TrimSpace(string(out) + " " + err.Error())}
+	}
+	return ActionResult{ExitCode: 0, Summary: summary, Details: string(bytes.TrimSpace(out))}
+}
+
 func processAlive(pid int32) bool {
 	cmd := exec.Command("ps", "-p", itoa32(pid))
 	return cmd.Run() == nil
 }
 
-func psField(pid int32, format string) string {
-	cmd := exec.Command("ps", "-p", itoa32(pid), "-o", format)
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	_ = cmd.Run()
-	return strings.TrimSpace(buf.String())
-}
-
 func itoa32(n int32) string {
 	if n == 0 {
 		return "0"
@@ -23,3 +23,7 @@ func SmartRestart(pid int32, cmdline string, timeout time.Duration) ActionResult
 func RestartDockerContainer(containerID string, timeout time.Duration) ActionResult {
 	return ActionResult{ExitCode: 1, Summary: "Not implemented on Windows yet"}
 }
+
+func RunCommand(summary string, argv []string) ActionResult {
+	return ActionResult{ExitCode: 1, Summary: "Not implemented on Windows yet"}
+}
@@ -0,0 +1,175 @@
+package trace
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pratik-anurag/portik/internal/firewall"
+	"github.com/pratik-anurag/portik/internal/inspect"
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/netns"
+	"github.com/pratik-anurag/portik/internal/platform"
+	"github.com/pratik-anurag/portik/internal/proc"
+)
+
+// Path is one NAT forward of the traced port and what listens at its
+// target.
+type Path struct {
+	Forward   firewall.Forward `json:"forward"`
+	Where     string           `json:"where,omitempty"` // "host", "container web", "netns pid:812"; empty if not on this machine
+	Checked   bool             `json:"checked"`         // the target's sockets were inspected
+	Note      string           `json:"note,omitempty"`  // why they were not
+	Listeners []model.Listener `json:"listeners,omitempty"`
+}
+
+// FollowNAT finds the DNAT and REDIRECT rules for port (iptables, Docker's
+// DOCKER chain, kube-proxy services, nftables) and inspects each target: a
+// local port, or the network namespace of the container or pod that owns
+// the target address. dm names the container when docker mapped the port.
+func FollowNAT(port int, proto string, dm model.DockerMap) []Path {
+	dumps := firewall.Dumps(platform.FirewallRules())
+	var fwds []firewall.Forward
+	for _, fam := range []string{"ipv4", "ipv6"} {
+		fwds = append(fwds, firewall.Forwards(dumps, firewall.Packet{Proto: proto, Family: fam, DstPort: port, Ifaces: platform.Interfaces()})...)
+	}
+	if len(fwds) == 0 {
+		return nil
+	}
+
+	local := map[string]bool{}
+	for _, ip := range platform.LocalIPs() {
+		local[ip] = true
+	}
+	var nss []netns.Namespace
+	if !platform.Replaying() {
+		nss, _ = netns.All()
+	}
+
+	var out []Path
+	for _, f := range fwds {
+		p := Path{Forward: f}
+		toPort := port
+		if f.ToPort != 0 {
+			toPort = f.ToPort
+		}
+		if f.ToIP == "" || local[f.ToIP] {
+			rep, err := inspect.InspectPort(toPort, proto, inspect.Options{})
+			p.Where, p.Checked, p.Listeners = "host", err == nil, rep.Listeners
+			if err != nil {
+				p.Note = err.Error()
+			}
+			out = append(out, p)
+			continue
+		}
+		ip := net.ParseIP(f.ToIP)
+		switch {
+		case platform.Replaying():
+			p.Note = "snapshots do not include other network namespaces"
+		case len(nss) == 0:
+			p.Note = "network namespaces are not available"
+		default:
+			p.Note = "no network namespace on this host has " + f.ToIP + " (another host or a VM?)"
+		}
+		for _, ns := range nss {
+			if ns.Host || ip == nil {
+				continue
+			}
+			found := false
+			var rep model.Report
+			err := netns.Do(ns, func() error {
+				if !hasAddr(ip) {
+					return nil
+				}
+				found = true
+				var err error
+				rep, err = inspect.InspectPort(toPort, proto, inspect.Options{})
+				return err
+			})
+			if found {
+				p.Where, p.Checked, p.Listeners, p.Note = namespaceLabel(ns, dm), err == nil, rep.Listeners, ""
+				if err != nil {
+					p.Note = err.Error()
+				}
+				break
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// hasAddr reports whether an interface of the current namespace has ip.
+func hasAddr(ip net.IP) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceLabel names a namespace by the container its processes run in.
+func namespaceLabel(ns netns.Namespace, dm model.DockerMap) string {
+	info, _ := proc.Lookup(int32(ns.PID))
+	if info.Container == "" {
+		return "netns " + ns.Label()
+	}
+	_, id, _ := strings.Cut(info.Container, ":")
+	if dm.Mapped && id != "" && (strings.HasPrefix(dm.ContainerID, id) || strings.HasPrefix(id, dm.ContainerID)) {
+		return "container " + dm.ContainerName
+	}
+	return "container " + info.Container
+}
+
+// ForwardSteps renders each path as host:port → DNAT ip:port → container →
+// process, flagging forwards whose target has no listener.
+func ForwardSteps(port int, paths []Path) []Step {
+	var out []Step
+	for _, p := range paths {
+		f := p.Forward
+		hops := []string{fmt.Sprintf("host:%d", port)}
+		if f.Kind == "redirect" {
+			hops = append(hops, "REDIRECT "+f.Target(port))
+		} else {
+			hops = append(hops, "DNAT "+f.Target(port))
+		}
+		if f.Comment != "" && f.Source == "iptables" && strings.HasPrefix(f.Via[len(f.Via)-1], "KUBE-") {
+			hops = append(hops, "service "+f.Comment)
+		}
+		if p.Where != "" && p.Where != "host" {
+			hops = append(hops, p.Where)
+		}
+
+		details := fmt.Sprintf("%s %s: %s", f.Source, strings.Join(f.Via, " > "), f.Rule)
+		if f.Only != "" {
+			details += " (" + f.Only + ")"
+		}
+		s := Step{Kind: "nat", Details: details}
+		switch {
+		case !p.Checked:
+			hops = append(hops, "not inspected")
+			if p.Note != "" {
+				s.Details = p.Note + ". " + details
+			}
+		case len(p.Listeners) == 0:
+			hops = append(hops, "nothing listening")
+			s.Kind = "nat-dead-end"
+			s.Details = fmt.Sprintf("A forward exists but nothing listens on %s; clients will be refused or time out. %s", f.Target(port), details)
+		default:
+			l := p.Listeners[0]
+			if l.PID > 0 {
+				hops = append(hops, fmt.Sprintf("%s(%d)", dash(l.ProcName), l.PID))
+			} else {
+				hops = append(hops, "listener "+ipOrStar(l.LocalIP)+fmt.Sprintf(":%d", l.LocalPort))
+			}
+		}
+		s.Summary = strings.Join(hops, " → ")
+		out = append(out, s)
+	}
+	return out
+}
@@ -28,6 +28,9 @@ func Steps(rep model.Report, chain []proctree.Proc, started proctree.StartedBy)
 			Summary: fmt.Sprintf("Listener pid=%d %s", l.PID, procLabel(l.ProcName, l.User)),
 			Details: fmt.Sprintf("Address %s:%d %s", ipOrStar(l.LocalIP), l.LocalPort, strings.ToUpper(l.State)),
 		})
+		if l.Tunnel != nil {
+			out = append(out, tunnelStep(*l.Tunnel))
+		}
 		if isLoopback(l.LocalIP) {
 			out = append(out, Step{
 				Kind:    "loopback",
@@ -71,16 +74,6 @@ func Steps(rep model.Report, chain []proctree.Proc, started proctree.StartedBy)
 		})
 	}
 
-	if l, ok := rep.PrimaryListener(); ok {
-		if isDockerProxy(l.ProcName, l.Cmdline) {
-			out = append(out, Step{
-				Kind:    "proxy",
-				Summary: "Docker proxy appears to own the listener",
-				Details: "The real service may be inside the container.",
-			})
-		}
-	}
-
 	return out
 }
 
@@ -173,8 +166,18 @@ func dash(s string) string {
 	return s
 }
 
-func isDockerProxy(name, cmdline string) bool {
-	name = strings.ToLower(name)
-	cmdline = strings.ToLower(cmdline)
-	return strings.Contains(name, "docker-proxy") || strings.Contains(cmdline, "docker-proxy")
+// tunnelStep says where a forwarder owning the listener sends traffic; the
+// real service runs there, not in the listening process.
+func tunnelStep(t model.Tunnel) Step {
+	var details []string
+	if t.Spec != "" {
+		details = append(details, "forward "+t.Spec)
+	}
+	if t.Peer != "" {
+		details = append(details, "upstream connection "+t.Peer)
+	}
+	if len(details) == 0 {
+		details = append(details, "The real service runs behind the tunnel, not in this process.")
+	}
+	return Step{Kind: "tunnel", Summary: "This is a " + t.String(), Details: strings.Join(details, "; ")}
 }
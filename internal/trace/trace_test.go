@@ -1,8 +1,10 @@
 package trace
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/pratik-anurag/portik/internal/firewall"
 	"github.com/pratik-anurag/portik/internal/model"
 	"github.com/pratik-anurag/portik/internal/proctree"
 )
@@ -32,3 +34,36 @@ func TestStepsBasic(t *testing.T) {
 		t.Fatalf("expected loopback step")
 	}
 }
+
+func TestForwardSteps(t *testing.T) {
+	paths := []Path{
+		{
+			Forward: firewall.Forward{Kind: "dnat", Source: "iptables", Via: []string{"PREROUTING", "DOCKER"},
+				Rule: "-A DOCKER ! -i docker0 -p tcp -m tcp --dport 8080 -j DNAT --to-destination 172.17.0.3:80", ToIP: "172.17.0.3", ToPort: 80},
+			Where: "container web", Checked: true,
+			Listeners: []model.Listener{{LocalIP: "0.0.0.0", LocalPort: 80, PID: 812, ProcName: "nginx"}},
+		},
+		{
+			Forward: firewall.Forward{Kind: "dnat", Source: "iptables", Via: []string{"PREROUTING", "KUBE-SERVICES", "KUBE-SEP-B"},
+				Rule: "-A KUBE-SEP-B ...", Comment: "default/web:http", ToIP: "10.244.2.7", ToPort: 8080},
+			Where: "container containerd:9c1b", Checked: true,
+		},
+		{
+			Forward: firewall.Forward{Kind: "redirect", Source: "nftables", Via: []string{"ip nat prerouting"}, Rule: "tcp dport 8080 redirect", ToPort: 3128},
+			Note:    "snapshots do not include other network namespaces",
+		},
+	}
+	steps := ForwardSteps(8080, paths)
+	if len(steps) != 3 {
+		t.Fatalf("steps: %+v", steps)
+	}
+	if want := "host:8080 → DNAT 172.17.0.3:80 → container web → nginx(812)"; steps[0].Summary != want {
+		t.Fatalf("summary = %q, want %q", steps[0].Summary, want)
+	}
+	if steps[1].Kind != "nat-dead-end" || !strings.Contains(steps[1].Summary, "service default/web:http") || !strings.Contains(steps[1].Details, "nothing listens on 10.244.2.7:8080") {
+		t.Fatalf("dead end: %+v", steps[1])
+	}
+	if !strings.HasSuffix(steps[2].Summary, "REDIRECT :3128 → not inspected") || !strings.HasPrefix(steps[2].Details, "snapshots") {
+		t.Fatalf("unchecked: %+v", steps[2])
+	}
+}
@@ -18,16 +18,22 @@ type PickOptions struct {
 }
 
 func PickFreePort(opt PickOptions) (int, error) {
+	if opt.Timeout <= 0 {
+		opt.Timeout = 3 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opt.Timeout)
+	defer cancel()
+	return PickFreePortContext(ctx, opt)
+}
+
+// PickFreePortContext is PickFreePort bounded by ctx instead of opt.Timeout.
+func PickFreePortContext(ctx context.Context, opt PickOptions) (int, error) {
 	if opt.Bind == "" {
 		opt.Bind = "127.0.0.1"
 	}
 	if opt.Proto != "tcp" && opt.Proto != "udp" {
 		return 0, fmt.Errorf("invalid proto: %s", opt.Proto)
 	}
-	if opt.Timeout <= 0 {
-		opt.Timeout = 3 * time.Second
-	}
-
 	freeOpt := reserve.FreeOptions{
 		Proto:    opt.Proto,
 		Bind:     opt.Bind,
@@ -49,8 +55,5 @@ func PickFreePort(opt PickOptions) (int, error) {
 	freeOpt.RangeStart = start
 	freeOpt.RangeEnd = end
 
-	ctx, cancel := context.WithTimeout(context.Background(), opt.Timeout)
-	defer cancel()
-
 	return reserve.FindFreeInRange(ctx, freeOpt)
 }
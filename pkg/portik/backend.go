@@ -0,0 +1,112 @@
+package portik
+
+import (
+	"context"
+
+	"github.com/pratik-anurag/portik/internal/docker"
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/proc"
+	"github.com/pratik-anurag/portik/internal/sockets"
+)
+
+// SocketBackend enumerates sockets. Entries need only the socket fields and
+// holder pids; process details are filled in from the ProcessBackend.
+type SocketBackend interface {
+	// Listeners returns listening sockets for proto ("tcp" or "udp").
+	Listeners(ctx context.Context, proto string) ([]Listener, error)
+	// Connections returns non-listening sockets for proto ("tcp").
+	Connections(ctx context.Context, proto string) ([]Conn, error)
+	// UnixSockets returns every AF_UNIX socket. Return an error wrapping
+	// errors.ErrUnsupported if there are none to report.
+	UnixSockets(ctx context.Context) ([]UnixSocket, error)
+}
+
+// ProcessBackend looks up processes by pid.
+type ProcessBackend interface {
+	Process(ctx context.Context, pid int32) (Process, bool)
+}
+
+// DockerBackend maps a published host port to its container.
+type DockerBackend interface {
+	MapPort(ctx context.Context, port int, proto string) (DockerMap, error)
+}
+
+// The adapters below bind a call's context to a backend so the engine,
+// which has no context of its own, can use it.
+
+type socketSource struct {
+	ctx context.Context
+	b   SocketBackend
+}
+
+func (s socketSource) ListListeners(proto string) ([]model.Listener, error) {
+	return s.b.Listeners(s.ctx, proto)
+}
+
+func (s socketSource) ListConnections(proto string) ([]model.Conn, error) {
+	return s.b.Connections(s.ctx, proto)
+}
+
+func (s socketSource) ListUnix() ([]model.UnixSocket, error) {
+	return s.b.UnixSockets(s.ctx)
+}
+
+type procSource struct {
+	ctx context.Context
+	b   ProcessBackend
+}
+
+func (p procSource) Lookup(pid int32) (proc.Info, bool) {
+	return p.b.Process(p.ctx, pid)
+}
+
+type dockerSource struct {
+	ctx context.Context
+	b   DockerBackend
+}
+
+func (d dockerSource) MapPort(port int, proto string) model.DockerMap {
+	m, err := d.b.MapPort(d.ctx, port, proto)
+	if err != nil {
+		return model.DockerMap{}
+	}
+	return m
+}
+
+// live backends, used when none is configured
+
+type liveSockets struct{}
+
+func (liveSockets) Listeners(_ context.Context, proto string) ([]Listener, error) {
+	return sockets.ListListeners(proto)
+}
+
+func (liveSockets) Connections(_ context.Context, proto string) ([]Conn, error) {
+	return sockets.ListConnections(proto)
+}
+
+func (liveSockets) UnixSockets(context.Context) ([]UnixSocket, error) {
+	return sockets.ListUnix()
+}
+
+type liveProcesses struct{}
+
+func (liveProcesses) Process(_ context.Context, pid int32) (Process, bool) {
+	return proc.Lookup(pid)
+}
+
+type liveDocker struct{}
+
+func (liveDocker) MapPort(_ context.Context, port int, proto string) (DockerMap, error) {
+	return docker.MapPort(port, proto), nil
+}
+
+// LiveSockets reads the running system with the default socket backend
+// (netlink, /proc or ss on Linux; lsof on macOS).
+func LiveSockets() SocketBackend { return liveSockets{} }
+
+// LiveProcesses reads process details from the running system.
+func LiveProcesses() ProcessBackend { return liveProcesses{} }
+
+// LiveDocker queries the local docker daemon through the docker CLI.
+func LiveDocker() DockerBackend { return liveDocker{} }
@@ -0,0 +1,210 @@
+package portik
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pratik-anurag/portik/internal/graph"
+	"github.com/pratik-anurag/portik/internal/inspect"
+	"github.com/pratik-anurag/portik/internal/proc"
+	"github.com/pratik-anurag/portik/internal/sockets"
+	"github.com/pratik-anurag/portik/internal/use"
+)
+
+// Client runs inspections. The zero value is not usable; call New. A Client
+// is safe for concurrent use. Diagnostics that need host facts (firewall,
+// sysctls, socket file modes) read the machine running the client, whatever
+// the backends.
+type Client struct {
+	sockets   SocketBackend
+	processes ProcessBackend
+	docker    DockerBackend
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithSocketBackend replaces how sockets are enumerated.
+func WithSocketBackend(b SocketBackend) Option {
+	return func(c *Client) { c.sockets = b }
+}
+
+// WithProcessBackend replaces how process details are looked up.
+func WithProcessBackend(b ProcessBackend) Option {
+	return func(c *Client) { c.processes = b }
+}
+
+// WithDockerBackend replaces how ports are mapped to containers.
+func WithDockerBackend(b DockerBackend) Option {
+	return func(c *Client) { c.docker = b }
+}
+
+// New returns a Client reading the live system unless options plug in
+// other backends.
+func New(opts ...Option) *Client {
+	c := &Client{}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// InspectOptions control a port or socket inspection.
+type InspectOptions struct {
+	Proto       string // tcp (default) or udp; ignored by InspectUnix
+	Connections bool   // also return connections to/from the port
+	Docker      bool   // map the port to a docker container
+	Probe       bool   // connect to each tcp listener and identify its protocol
+}
+
+// GraphOptions control Graph.
+type GraphOptions struct {
+	Ports []int // only these ports; all when empty
+	Unix  bool  // add Unix stream socket dependencies (ignored with Ports)
+}
+
+// FreePortOptions control FreePort.
+type FreePortOptions struct {
+	Proto string // tcp (default) or udp
+	Bind  string // address to test binding on, default 127.0.0.1
+	Range string // e.g. "3000-3999"; empty picks an ephemeral port
+}
+
+// InspectPort reports who listens on port, with diagnostics.
+func (c *Client) InspectPort(ctx context.Context, port int, opt InspectOptions) (Report, error) {
+	if port <= 0 || port > 65535 {
+		return Report{}, fmt.Errorf("invalid port %d", port)
+	}
+	proto := opt.Proto
+	if proto == "" {
+		proto = "tcp"
+	}
+	return run(ctx, func() (Report, error) {
+		rep, err := inspect.InspectPort(port, proto, c.inspectOptions(ctx, opt))
+		if err != nil {
+			return Report{}, err
+		}
+		return Report{APIVersion: APIVersion, Report: rep}, nil
+	})
+}
+
+// InspectUnix reports who listens on the Unix socket at path, with
+// diagnostics about the socket file.
+func (c *Client) InspectUnix(ctx context.Context, path string, opt InspectOptions) (Report, error) {
+	return run(ctx, func() (Report, error) {
+		rep, err := inspect.InspectUnix(path, c.inspectOptions(ctx, opt))
+		if err != nil {
+			return Report{}, err
+		}
+		return Report{APIVersion: APIVersion, Report: rep}, nil
+	})
+}
+
+// Listeners returns every listener for proto with process details.
+func (c *Client) Listeners(ctx context.Context, proto string) ([]Listener, error) {
+	if proto == "" {
+		proto = "tcp"
+	}
+	if proto != "tcp" && proto != "udp" {
+		return nil, fmt.Errorf("unsupported proto: %s", proto)
+	}
+	return run(ctx, func() ([]Listener, error) {
+		ls, err := c.socketSource(ctx).ListListeners(proto)
+		if err != nil {
+			return nil, err
+		}
+		procs := c.procSource(ctx)
+		for i := range ls {
+			proc.EnrichFrom(procs, &ls[i])
+		}
+		return ls, nil
+	})
+}
+
+// Graph builds the local TCP dependency graph between processes.
+func (c *Client) Graph(ctx context.Context, opt GraphOptions) (Graph, error) {
+	return run(ctx, func() (Graph, error) {
+		g, deps, warns, err := graph.Build("tcp", graph.Options{
+			Ports:     opt.Ports,
+			LocalOnly: true,
+			Unix:      opt.Unix,
+			Sockets:   c.socketSource(ctx),
+			Processes: c.procSource(ctx),
+		})
+		if err != nil {
+			return Graph{}, err
+		}
+		return Graph{
+			APIVersion:   APIVersion,
+			Nodes:        g.Nodes,
+			Edges:        g.Edges,
+			Dependencies: deps,
+			Warnings:     warns,
+		}, nil
+	})
+}
+
+// FreePort returns a port that could be bound just now. Another process may
+// take it before the caller binds it.
+func (c *Client) FreePort(ctx context.Context, opt FreePortOptions) (int, error) {
+	proto := opt.Proto
+	if proto == "" {
+		proto = "tcp"
+	}
+	return use.PickFreePortContext(ctx, use.PickOptions{Proto: proto, Bind: opt.Bind, PortsSpec: opt.Range})
+}
+
+func (c *Client) inspectOptions(ctx context.Context, opt InspectOptions) inspect.Options {
+	o := inspect.Options{
+		EnableDocker:       opt.Docker,
+		IncludeConnections: opt.Connections,
+		Probe:              opt.Probe,
+		Sockets:            c.socketSource(ctx),
+		Processes:          c.procSource(ctx),
+	}
+	if c.docker != nil {
+		o.Docker = dockerSource{ctx: ctx, b: c.docker}
+	}
+	return o
+}
+
+// socketSource keeps the built-in backends on their fast per-port path
+// unless a custom backend was configured.
+func (c *Client) socketSource(ctx context.Context) sockets.Source {
+	if c.sockets == nil {
+		return sockets.Live
+	}
+	return socketSource{ctx: ctx, b: c.sockets}
+}
+
+func (c *Client) procSource(ctx context.Context) proc.Source {
+	if c.processes == nil {
+		return proc.Live
+	}
+	return procSource{ctx: ctx, b: c.processes}
+}
+
+// run returns when fn does or ctx is done, whichever is first. The engine
+// cannot be interrupted mid-read, so on cancellation fn finishes in the
+// background and its result is dropped; custom backends see ctx directly.
+func run[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
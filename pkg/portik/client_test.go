@@ -0,0 +1,114 @@
+package portik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type fakeSockets struct {
+	listeners []Listener
+	conns     []Conn
+}
+
+func (f fakeSockets) Listeners(ctx context.Context, proto string) ([]Listener, error) {
+	return append([]Listener(nil), f.listeners...), ctx.Err()
+}
+
+func (f fakeSockets) Connections(ctx context.Context, proto string) ([]Conn, error) {
+	return append([]Conn(nil), f.conns...), ctx.Err()
+}
+
+func (f fakeSockets) UnixSockets(context.Context) ([]UnixSocket, error) {
+	return nil, fmt.Errorf("fake: %w", errors.ErrUnsupported)
+}
+
+type fakeProcs map[int32]Process
+
+func (f fakeProcs) Process(_ context.Context, pid int32) (Process, bool) {
+	p, ok := f[pid]
+	return p, ok
+}
+
+type fakeDocker struct{}
+
+func (fakeDocker) MapPort(_ context.Context, port int, proto string) (DockerMap, error) {
+	return DockerMap{Checked: true, Mapped: port == 5432, ContainerName: "db"}, nil
+}
+
+func newFakeClient() *Client {
+	return New(
+		WithSocketBackend(fakeSockets{
+			listeners: []Listener{
+				{LocalIP: "127.0.0.1", LocalPort: 5432, Family: "ipv4", State: "LISTEN", PID: 10},
+				{LocalIP: "0.0.0.0", LocalPort: 8080, Family: "ipv4", State: "LISTEN", PID: 20},
+			},
+			conns: []Conn{
+				{LocalIP: "127.0.0.1", LocalPort: 40000, RemoteIP: "127.0.0.1", RemotePort: 5432, State: "ESTAB", PID: 20},
+			},
+		}),
+		WithProcessBackend(fakeProcs{
+			10: {PID: 10, PPID: 1, Name: "postgres", User: "postgres"},
+			20: {PID: 20, PPID: 1, Name: "api", User: "app"},
+		}),
+		WithDockerBackend(fakeDocker{}),
+	)
+}
+
+func TestInspectPortWithBackends(t *testing.T) {
+	rep, err := newFakeClient().InspectPort(context.Background(), 5432, InspectOptions{Connections: true, Docker: true})
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if rep.APIVersion != APIVersion {
+		t.Fatalf("expected api version %q, got %q", APIVersion, rep.APIVersion)
+	}
+	if len(rep.Listeners) != 1 || rep.Listeners[0].ProcName != "postgres" || rep.Listeners[0].User != "postgres" {
+		t.Fatalf("listener not enriched from process backend: %+v", rep.Listeners)
+	}
+	if len(rep.Connections) != 1 || rep.Connections[0].ProcName != "api" {
+		t.Fatalf("unexpected connections: %+v", rep.Connections)
+	}
+	if !rep.Docker.Mapped || rep.Docker.ContainerName != "db" {
+		t.Fatalf("docker backend not used: %+v", rep.Docker)
+	}
+	if len(rep.Diagnostics) == 0 {
+		t.Fatalf("expected diagnostics")
+	}
+
+	b, err := json.Marshal(rep)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(b), `"api_version":"portik/v1"`) || !strings.Contains(string(b), `"listeners":[`) {
+		t.Fatalf("expected flat versioned JSON, got %s", b)
+	}
+}
+
+func TestGraphWithBackends(t *testing.T) {
+	g, err := newFakeClient().Graph(context.Background(), GraphOptions{})
+	if err != nil {
+		t.Fatalf("graph: %v", err)
+	}
+	if len(g.Dependencies) != 1 {
+		t.Fatalf("expected one dependency, got %+v", g.Dependencies)
+	}
+	d := g.Dependencies[0]
+	if d.Client.ProcName != "api" || d.Server.ProcName != "postgres" || d.Port.Port != 5432 {
+		t.Fatalf("unexpected dependency: %+v", d)
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := newFakeClient().InspectPort(ctx, 5432, InspectOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := New().Listeners(context.Background(), "sctp"); err == nil {
+		t.Fatalf("expected unsupported proto error")
+	}
+}
@@ -0,0 +1,56 @@
+// Package portik is the importable API of portik's inspection engine: who
+// owns a port, why it is stuck, how local processes depend on each other,
+// and which ports are free.
+//
+//	c := portik.New()
+//	rep, err := c.InspectPort(ctx, 5432, portik.InspectOptions{})
+//	if err != nil { ... }
+//	for _, d := range rep.Diagnostics { fmt.Println(d.Summary) }
+//
+// Results carry APIVersion. Fields are only added within a version; removals
+// or changes in meaning bump it.
+package portik
+
+import (
+	"github.com/pratik-anurag/portik/internal/graph"
+	"github.com/pratik-anurag/portik/internal/model"
+	"github.com/pratik-anurag/portik/internal/proc"
+)
+
+// APIVersion identifies the layout of the result types.
+const APIVersion = "portik/v1"
+
+// Building blocks of results. They are the types the portik CLI prints as
+// JSON, so CLI output and API results decode into the same structs.
+type (
+	Listener     = model.Listener
+	Conn         = model.Conn
+	TCPInfo      = model.TCPInfo
+	SocketHolder = model.SocketHolder
+	UnixSocket   = model.UnixSocket
+	Diagnostic   = model.Diagnostic
+	DockerMap    = model.DockerMap
+	Tunnel       = model.Tunnel
+	Fingerprint  = model.Fingerprint
+	Cert         = model.Cert
+	Process      = proc.Info
+
+	Node       = graph.Node
+	Edge       = graph.Edge
+	Dependency = graph.Dependency
+)
+
+// Report is the result of inspecting one port or Unix socket path.
+type Report struct {
+	APIVersion string `json:"api_version"`
+	model.Report
+}
+
+// Graph is the local dependency graph between processes.
+type Graph struct {
+	APIVersion   string       `json:"api_version"`
+	Nodes        []Node       `json:"nodes"`
+	Edges        []Edge       `json:"edges"`
+	Dependencies []Dependency `json:"dependencies"`
+	Warnings     []string     `json:"warnings,omitempty"`
+}